@@ -1,10 +1,17 @@
 package main
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"strings"
 
+	"github.com/carlosms/music-playground/format/musicxml"
+	"github.com/carlosms/music-playground/format/text"
+	"github.com/carlosms/music-playground/render"
 	"github.com/carlosms/music-playground/synth"
 	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
 
 	"github.com/hajimehoshi/oto"
 )
@@ -16,309 +23,105 @@ const (
 	bufferSizeInBytes = 5120
 )
 
-func play(wave synth.WaveGenerator, bpm int, staff [][]note.Note) io.Reader {
-	readers := []io.Reader{}
-
-	for _, notes := range staff {
-		groupReaders := []io.Reader{}
-
-		for _, n := range notes {
-			d := n.ToSeconds(note.Quarter, bpm)
-			w := wave(sampleRate, n.Frequency(), d)
-			r := synth.Sustain(w, 0.2)
-			groupReaders = append(groupReaders, r)
+func main() {
+	// A MusicXML export of the score can be played instead of the
+	// transcription
+	s := marble
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
+		if err != nil {
+			panic(err)
+		}
+		s, err = musicxml.Parse(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+			os.Exit(1)
 		}
-
-		readers = append(readers, synth.Combine(groupReaders...))
 	}
 
-	return io.MultiReader(readers...)
-}
-
-func main() {
 	p, err := oto.NewPlayer(sampleRate, channelNum, bitDepthInBytes, bufferSizeInBytes)
 	if err != nil {
 		panic(err)
 	}
 	defer p.Close()
 
-	sound := synth.Combine(
-		play(synth.NewSineWave, 180, trebleStaff),
-		play(synth.NewSineWave, 180, bassStaff),
-	)
+	// Wrong bars are reported, but the transcription is played as it is
+	if err := s.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", s.Title, err)
+	}
+
+	r := render.Renderer{
+		SampleRate: sampleRate,
+		Wave:       synth.NewSineWave,
+		Volume:     0.3,
+	}
+	sound := r.Score(s)
 
 	if _, err := io.Copy(p, sound); err != nil {
 		panic(err)
 	}
 }
 
-// Marble Machine
-// Composed by Wintergatan, transcribed by Chalmers Huang
 // Transcribed to Go painstakingly manually from
 // https://musescore.com/user/5631216/scores/1846226
-var trebleStaff = [][]note.Note{
-	// Bar 1
-	[]note.Note{note.NewNote(note.E6, note.Quarter)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	// Bar 2
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D6, note.Eighth)},
-	// Bar 3
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	// Bar 4
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D6, note.Eighth)},
-	// Bar 5
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D6, note.Eighth)},
-	// Bar 6
-	[]note.Note{note.NewNote(note.C6, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	// Bar 7
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.C5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewNote(note.C5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D6, note.Eighth)},
-	// Bar 8
-	[]note.Note{note.NewNote(note.C6, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E6, note.Eighth)},
-	// Bar 9
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	// Bar 10
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D6, note.Eighth)},
-	// Bar 11
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D6, note.Eighth)},
-	// Bar 12
-	[]note.Note{note.NewNote(note.C6, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E6, note.Eighth)},
-	// Bar 13
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E6, note.Eighth)},
-	// Bar 14
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.B5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.F5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	// Bar 15
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewNote(note.C5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.C5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E5, note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D5, note.Eighth)},
-	// Bar 16
-	[]note.Note{note.NewNote(note.D5, note.Eighth)},
-	[]note.Note{note.NewNote(note.Fsharp5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.D5, note.Eighth)},
-	[]note.Note{note.NewNote(note.G5, note.Eighth)},
-	[]note.Note{note.NewNote(note.A5, note.Eighth)},
-	[]note.Note{note.NewNote(note.E6, note.Eighth)},
+var marble = score.Score{
+	Title:    "Marble Machine",
+	Composer: "Wintergatan",
+	Arranger: "Chalmers Huang",
+	Tempo:    score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 180}),
+	Parts: []score.Part{
+		{
+			Name:       "Music Box",
+			Instrument: score.Instrument{Name: "Music Box", Program: 10},
+			Staves:     []score.Staff{staff(treble), staff(bass)},
+		},
+	},
 }
 
-var bassStaff = [][]note.Note{
-	// Bar 1
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 2
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 3
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 4
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 5
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 6
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 7
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 8
-	[]note.Note{note.NewRest(note.Whole)},
-	// Bar 9
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth),
-		note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth),
-		note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	// Bar 10
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth),
-		note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth),
-		note.NewNote(note.B4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	// Bar 11
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth),
-		note.NewNote(note.A4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth),
-		note.NewNote(note.A4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	// Bar 12
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth),
-		note.NewNote(note.A4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth),
-		note.NewNote(note.A4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	// Bar 13
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.B3, note.Eighth),
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.B3, note.Eighth),
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	// Bar 14
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.B3, note.Eighth),
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.B3, note.Eighth),
-		note.NewNote(note.D4, note.Eighth),
-		note.NewNote(note.Fsharp4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	// Bar 15
-	[]note.Note{
-		note.NewNote(note.C3, note.Eighth),
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	[]note.Note{
-		note.NewNote(note.C3, note.Eighth),
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Quarter)},
-	// Bar 16
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{
-		note.NewNote(note.C3, note.Eighth),
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{
-		note.NewNote(note.C3, note.Eighth),
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth)},
-	[]note.Note{
-		note.NewNote(note.C3, note.Eighth),
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth)},
-	[]note.Note{note.NewRest(note.Eighth)},
-	[]note.Note{
-		note.NewNote(note.C3, note.Eighth),
-		note.NewNote(note.E4, note.Eighth),
-		note.NewNote(note.G4, note.Eighth)},
+const treble = `
+	e''' e''8 b'' r4 e''8 a'' |
+	g'' a'' e'' b'' r g'' a'' d''' |
+	r4 e''8 b'' r4 e''8 a'' |
+	g'' a'' d'' fis'' r g'' a'' d''' |
+	r4 fis''8 b'' r4 fis''8 d''' |
+	c''' b'' fis'' a'' r g'' a'' e'' |
+	r c'' e'' b'' b' c'' d'' d''' |
+	c''' b'' fis'' a'' r g'' a'' e''' |
+	r4 e''8 b'' r4 e''8 a'' |
+	g'' a'' e'' b'' r g'' a'' d''' |
+	r4 fis''8 b'' r4 fis''8 d''' |
+	c''' b'' fis'' a'' r g'' a'' e''' |
+	r4 fis''8 b'' r4 a''8 e''' |
+	r b'' fis'' a'' r g'' f'' e'' |
+	r b' c'' fis'' c'' e'' g'' d'' |
+	d'' fis'' a'' b' a'' d'' g'' a'' e''' |
+`
+
+const bass = `
+	r1 |
+	r |
+	r |
+	r |
+	r |
+	r |
+	r |
+	r |
+	r4 <e' g' b'>8 r r4 <e' g' b'>8 r |
+	r4 <e' g' b'>8 r r4 <e' g' b'>8 r |
+	r4 <d' fis' a'>8 r r4 <d' fis' a'>8 r |
+	r4 <d' fis' a'>8 r r4 <d' fis' a'>8 r |
+	r4 <b d' fis'>8 r r4 <b d' fis'>8 r |
+	r4 <b d' fis'>8 r r4 <b d' fis'>8 r |
+	<c e' g'> r r4 <c e' g'>8 r r4 |
+	r8 <c e' g'> r r <c e' g'> <c e' g'> r <c e' g'> |
+`
+
+// staff parses a staff written in the text notation of text.ParseStaff
+func staff(s string) score.Staff {
+	st, err := text.ParseStaff(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return st
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/carlosms/music-playground/format/smf"
+	"github.com/carlosms/music-playground/render"
+	"github.com/carlosms/music-playground/synth"
+	"github.com/carlosms/music-playground/theory/note"
+
+	"github.com/hajimehoshi/oto"
+)
+
+const (
+	sampleRate        = 44100
+	channelNum        = 1
+	bitDepthInBytes   = 2
+	bufferSizeInBytes = 5120
+)
+
+func main() {
+	grid := flag.Int("grid", 16, "note value to round the notes to, e.g. 16 for sixteenths, 0 to keep the exact timing")
+	triplets := flag.Bool("triplets", false, "also round the notes to triplets")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] file.mid\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	o := smf.Options{Triplets: *triplets}
+	if *grid > 0 {
+		o.Grid = note.Whole / note.Duration(*grid)
+	}
+	s, err := smf.Parse(f, o)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+
+	p, err := oto.NewPlayer(sampleRate, channelNum, bitDepthInBytes, bufferSizeInBytes)
+	if err != nil {
+		panic(err)
+	}
+	defer p.Close()
+
+	r := render.Renderer{
+		SampleRate: sampleRate,
+		Wave:       synth.NewSineWave,
+		Volume:     0.3,
+	}
+	if _, err := io.Copy(p, r.Score(s)); err != nil {
+		panic(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/carlosms/music-playground/synth"
+	"github.com/carlosms/music-playground/theory/chord"
 	"github.com/carlosms/music-playground/theory/note"
 
 	"github.com/hajimehoshi/oto"
@@ -19,26 +20,6 @@ const (
 	bufferSizeInBytes = 5120
 )
 
-func majorScale(n note.Pitch) []note.Pitch {
-	// whole, whole, half, whole, whole, whole, half
-	scale := make([]note.Pitch, 8)
-	scale[0] = n
-	scale[1] = scale[0].Add(note.Tone)
-	scale[2] = scale[1].Add(note.Tone)
-	scale[3] = scale[2].Add(note.Semitone)
-	scale[4] = scale[3].Add(note.Tone)
-	scale[5] = scale[4].Add(note.Tone)
-	scale[6] = scale[5].Add(note.Tone)
-	scale[7] = scale[6].Add(note.Semitone)
-
-	return scale
-}
-
-func majorChord(n note.Pitch) []note.Pitch {
-	scale := majorScale(n)
-	return []note.Pitch{scale[0], scale[2], scale[4]}
-}
-
 func plotChord(wave synth.WaveGenerator, pitches []note.Pitch) {
 	names := make([]string, len(pitches))
 	for i, p := range pitches {
@@ -78,7 +59,7 @@ func main() {
 	pitch := note.C3
 
 	for i := uint8(0); i < 3; i++ {
-		triad := majorChord(pitch.Add(i * note.Octave))
+		triad := chord.New(pitch.Add(i*note.Octave), chord.Major).Pitches()
 		plotChord(synth.NewSineWave, triad)
 
 		sound := play(synth.NewSineWave, triad)
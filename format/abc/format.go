@@ -0,0 +1,318 @@
+package abc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// barsPerLine is the number of bars written in each line of music
+const barsPerLine = 4
+
+// Format returns the tune written in ABC notation. Pitches that are not
+// spelled are written as they belong to the key. Durations that are not a
+// multiple of the unit note length are written as triplets when possible,
+// and as fractions of the unit otherwise
+func Format(t Tune) string {
+	var b strings.Builder
+
+	number := t.Number
+	if number == 0 {
+		number = 1
+	}
+	fmt.Fprintf(&b, "X:%d\n", number)
+	if t.Title != "" {
+		fmt.Fprintf(&b, "T:%s\n", t.Title)
+	}
+	if t.Composer != "" {
+		fmt.Fprintf(&b, "C:%s\n", t.Composer)
+	}
+
+	meter := t.Meter
+	if meter == (score.TimeSignature{}) {
+		meter = score.CommonTime
+	}
+	fmt.Fprintf(&b, "M:%v\n", meter)
+
+	unit := t.Unit
+	if unit == 0 {
+		unit = defaultUnit(meter)
+	}
+	fmt.Fprintf(&b, "L:%v\n", unit.Rational())
+
+	if t.Tempo.BPM > 0 {
+		fmt.Fprintf(&b, "Q:%v=%d\n", t.Tempo.Beat.Rational(), t.Tempo.BPM)
+	}
+	fmt.Fprintf(&b, "K:%s\n", keyName(t.Key))
+
+	voices := t.Staff.NumVoices()
+	for v := 0; v < voices; v++ {
+		if voices > 1 {
+			fmt.Fprintf(&b, "V:%d\n", v+1)
+		}
+		w := writer{key: t.Key, unit: unit.Rational(), meter: meter}
+		b.WriteString(w.voice(t.Staff, v))
+	}
+
+	return b.String()
+}
+
+// keyModes are the mode suffixes used in the K: field
+var keyModes = map[key.Mode]string{
+	key.Major:      "",
+	key.Minor:      "m",
+	key.Dorian:     "dor",
+	key.Phrygian:   "phr",
+	key.Lydian:     "lyd",
+	key.Mixolydian: "mix",
+	key.Locrian:    "loc",
+}
+
+// keyName returns the key as written in the K: field, e.g. "F#m"
+func keyName(k key.Key) string {
+	return k.Tonic.Name() + keyModes[k.Mode]
+}
+
+// writer keeps the state while writing a voice
+type writer struct {
+	key   key.Key
+	unit  note.Rational
+	meter score.TimeSignature
+
+	// accidentals are the ones written in the current bar
+	accidentals map[pitchKey]note.Accidental
+}
+
+// voice returns the measures of a voice. Measures without the voice are
+// written as full measure rests
+func (w *writer) voice(s score.Staff, v int) string {
+	var b strings.Builder
+
+	for i, m := range s {
+		w.accidentals = map[pitchKey]note.Accidental{}
+
+		if m.Time != w.meter {
+			fmt.Fprintf(&b, "[M:%v] ", m.Time)
+			w.meter = m.Time
+		}
+
+		var groups score.Voice
+		if v < len(m.Voices) {
+			groups = m.Voices[v]
+		} else {
+			groups = score.Voice{{note.NewRest(m.Time.Duration().Duration())}}
+		}
+		b.WriteString(w.groups(groups))
+
+		switch {
+		case i == len(s)-1:
+			b.WriteString(" |]\n")
+		case (i+1)%barsPerLine == 0:
+			b.WriteString(" |\n")
+		default:
+			b.WriteString(" | ")
+		}
+	}
+
+	return b.String()
+}
+
+// groups returns the notes and chords of a voice in a measure
+func (w *writer) groups(v score.Voice) string {
+	var items []string
+	for i := 0; i < len(v); i++ {
+		if i+2 < len(v) && isTriplet(v[i:i+3]) {
+			items = append(items, "(3"+w.group(v, i, tripletFactor)+w.group(v, i+1, tripletFactor)+
+				w.group(v, i+2, tripletFactor))
+			i += 2
+			continue
+		}
+		items = append(items, w.group(v, i, note.NewRational(1, 1)))
+	}
+	return strings.Join(items, " ")
+}
+
+// tripletFactor is the length of a triplet note written as a normal note
+var tripletFactor = note.NewRational(3, 2)
+
+// isTriplet returns true if the 3 groups are triplets: they are written
+// with standard lengths when they are 3/2 longer
+func isTriplet(groups score.Voice) bool {
+	for _, g := range groups {
+		for _, n := range g {
+			r := n.Duration.Rational()
+			if r.Den()%3 != 0 {
+				return false
+			}
+			if d := r.Mul(tripletFactor).Den(); d&(d-1) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// group returns the note or chord at index i, with its decorations, slurs
+// and ties. Lengths are multiplied by factor, for tuplets
+func (w *writer) group(v score.Voice, i int, factor note.Rational) string {
+	group := v[i]
+	var b strings.Builder
+
+	if score.IsLegato(group) && (i == 0 || !score.IsLegato(v[i-1])) {
+		b.WriteString("(")
+	}
+
+	for _, n := range group {
+		if n.Dynamic != note.NoDynamic {
+			fmt.Fprintf(&b, "!%v!", n.Dynamic)
+			break
+		}
+	}
+	for _, n := range group {
+		switch n.Hairpin {
+		case note.Crescendo:
+			b.WriteString("!crescendo(!")
+		case note.Diminuendo:
+			b.WriteString("!diminuendo(!")
+		default:
+			continue
+		}
+		break
+	}
+
+	// rests can't be written in chords
+	if notes := score.PitchedNotes(group); len(notes) == 1 {
+		b.WriteString(articulations(notes[0].Articulation))
+		b.WriteString(w.note(notes[0], factor))
+		if notes[0].Tie {
+			b.WriteString("-")
+		}
+	} else {
+		b.WriteString(w.chord(notes, factor))
+	}
+
+	if score.IsLegato(group) && (i == len(v)-1 || !score.IsLegato(v[i+1])) {
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// chord returns the notes of a group between brackets. Articulations, ties
+// and lengths shared by all the notes are written once for the chord
+func (w *writer) chord(group []note.Note, factor note.Rational) string {
+	common := group[0].Articulation
+	same, tied := true, true
+	for _, n := range group {
+		common &= n.Articulation
+		same = same && n.Duration == group[0].Duration
+		tied = tied && n.Tie
+	}
+
+	var b strings.Builder
+	b.WriteString(articulations(common))
+	b.WriteString("[")
+	for _, n := range group {
+		b.WriteString(articulations(n.Articulation &^ common))
+		if same {
+			b.WriteString(w.pitch(n))
+		} else {
+			b.WriteString(w.note(n, factor))
+		}
+		if n.Tie && !tied {
+			b.WriteString("-")
+		}
+	}
+	b.WriteString("]")
+
+	if same {
+		b.WriteString(w.length(group[0].Duration, factor))
+	}
+	if tied {
+		b.WriteString("-")
+	}
+	return b.String()
+}
+
+// articulations returns the decorations for the articulations. Legato is
+// written with slurs
+func articulations(a note.Articulation) string {
+	var b strings.Builder
+	if a.Has(note.Staccato) {
+		b.WriteString(".")
+	}
+	if a.Has(note.Tenuto) {
+		b.WriteString("!tenuto!")
+	}
+	if a.Has(note.Accent) {
+		b.WriteString("!accent!")
+	}
+	if a.Has(note.Marcato) {
+		b.WriteString("!marcato!")
+	}
+	return b.String()
+}
+
+// note returns a pitch or rest with its length
+func (w *writer) note(n note.Note, factor note.Rational) string {
+	return w.pitch(n) + w.length(n.Duration, factor)
+}
+
+// pitch returns the pitch, e.g. "^f'", or "z" for rests. Accidentals are
+// only written when they are different from the key signature or the
+// previous accidentals of the bar
+func (w *writer) pitch(n note.Note) string {
+	s, ok := note.SpellWith(w.key, n.Pitch)
+	if !ok {
+		return "z"
+	}
+	k := pitchKey{s.Letter, s.Octave}
+
+	current, ok := w.accidentals[k]
+	if !ok {
+		current = w.key.Signature().Accidental(s.Letter)
+	}
+
+	var b strings.Builder
+	if s.Accidental != current {
+		switch {
+		case s.Accidental > 0:
+			b.WriteString(strings.Repeat("^", int(s.Accidental)))
+		case s.Accidental < 0:
+			b.WriteString(strings.Repeat("_", int(-s.Accidental)))
+		default:
+			b.WriteString("=")
+		}
+		w.accidentals[k] = s.Accidental
+	}
+
+	letter := s.Letter.String()
+	switch {
+	case s.Octave >= 5:
+		b.WriteString(strings.ToLower(letter))
+		b.WriteString(strings.Repeat("'", s.Octave-5))
+	default:
+		b.WriteString(letter)
+		b.WriteString(strings.Repeat(",", 4-s.Octave))
+	}
+	return b.String()
+}
+
+// length returns the multiplier of the unit note length, e.g. "3/2"
+func (w *writer) length(d note.Duration, factor note.Rational) string {
+	r := d.Rational().Mul(factor).Mul(note.NewRational(w.unit.Den(), w.unit.Num()))
+
+	switch {
+	case r.Num() == 1 && r.Den() == 1:
+		return ""
+	case r.Den() == 1:
+		return fmt.Sprint(r.Num())
+	case r.Num() == 1 && r.Den() == 2:
+		return "/"
+	case r.Num() == 1:
+		return fmt.Sprintf("/%d", r.Den())
+	}
+	return r.String()
+}
@@ -0,0 +1,101 @@
+package abc_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/format/abc"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	staff := score.NewStaff(score.CommonTime,
+		[][]note.Note{
+			{note.NewNote(note.E5, note.Quarter).WithDynamic(note.MezzoPiano)},
+			{note.NewNote(note.Fsharp4, note.Eighth)},
+			{note.NewNote(note.F4, note.Eighth).Articulate(note.Staccato)},
+			{note.NewRest(note.Quarter)},
+			{note.NewNote(note.C4, note.Quarter), note.NewNote(note.G4, note.Quarter)},
+		},
+		[][]note.Note{
+			{note.NewNote(note.E5, note.Eighth.Triplet()).Articulate(note.Legato).Crescendo()},
+			{note.NewNote(note.D5, note.Eighth.Triplet()).Articulate(note.Legato)},
+			{note.NewNote(note.C5, note.Eighth.Triplet()).Articulate(note.Legato)},
+			{note.NewNote(note.C3, note.Half.Dotted()).Tied(), note.NewNote(note.E3, note.Quarter)},
+		},
+	)
+	staff = append(staff, score.NewPolyphonicMeasure(score.TimeSignature{Beats: 3, Value: 4},
+		score.Voice{{note.NewNote(note.B3, note.Half.Dotted()).WithDynamic(note.Forte)}},
+		score.Voice{{note.NewNote(note.G2, note.Half)}, {note.NewNote(note.G2, note.Quarter)}},
+	))
+
+	tune := abc.Tune{
+		Title:    "Test",
+		Composer: "Someone",
+		Meter:    score.CommonTime,
+		Unit:     note.Eighth,
+		Tempo:    score.Tempo{Beat: note.Quarter, BPM: 100},
+		Key:      key.Key{Tonic: note.NewSpelledPitch(note.G, note.Natural, 4), Mode: key.Major},
+		Staff:    staff,
+	}
+
+	assert.Equal(t, `X:1
+T:Test
+C:Someone
+M:4/4
+L:1/8
+Q:1/4=100
+K:G
+V:1
+!mp!e2 F .=F z2 [CG]2 | (3(!crescendo(!edc) [C,6-E,2] | [M:3/4] !f!B,6 |]
+V:2
+z8 | z8 | [M:3/4] G,,4 G,,2 |]
+`, abc.Format(tune))
+}
+
+func TestFormatKeys(t *testing.T) {
+	for k, expected := range map[key.Key]string{
+		{Tonic: note.NewSpelledPitch(note.F, note.Sharp, 4), Mode: key.Minor}:        "K:F#m",
+		{Tonic: note.NewSpelledPitch(note.B, note.Flat, 4), Mode: key.Major}:         "K:Bb",
+		{Tonic: note.NewSpelledPitch(note.D, note.Natural, 4), Mode: key.Dorian}:     "K:Ddor",
+		{Tonic: note.NewSpelledPitch(note.G, note.Natural, 4), Mode: key.Mixolydian}: "K:Gmix",
+	} {
+		tune := abc.Tune{Key: k, Staff: score.NewStaff(score.CommonTime, [][]note.Note{{note.NewRest(note.Whole)}})}
+		assert.Contains(t, abc.Format(tune), expected+"\n")
+
+		parsed := parse(t, abc.Format(tune))
+		assert.Equal(t, k, parsed.Key)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"X:1\nT:Reel\nM:4/4\nL:1/8\nK:D\n|: dAFA DAFA | B2 ^c2 =c2 _B2 | A>B A<B (3ABc d2 :|\n",
+		"X:2\nM:6/8\nL:1/8\nQ:3/8=100\nK:Am\n!p!A,3 !crescendo(!c3 | !f![A,CE]6- | [A,2CE] z4 |]\n",
+		"X:3\nM:3/4\nL:1/4\nK:Eb\nV:1\n(.e d) !accent!c | !tenuto!B3 |\nV:2\nE, G, B, | E,3 |\n",
+		"X:4\nM:2/4\nL:1/16\nK:C\nc/d/ e2 e3/2 f/2 g4 | [c2e] [c,2e,]- [c,e,]2 z4 | [M:3/8] c6 |]\n",
+	} {
+		tune := parse(t, s)
+
+		formatted := abc.Format(tune)
+		again := parse(t, formatted)
+		assert.Equal(t, tune, again, formatted)
+	}
+
+	// Rests are dropped from chords, which can't have them
+	chords := func(groups ...[]note.Note) abc.Tune {
+		return abc.Tune{Meter: score.CommonTime, Unit: note.Eighth,
+			Staff: score.NewStaff(score.CommonTime, groups)}
+	}
+	formatted := abc.Format(chords(
+		[]note.Note{note.NewRest(note.Half), note.NewNote(note.C4, note.Half), note.NewNote(note.E4, note.Half)},
+		[]note.Note{note.NewNote(note.G4, note.Half), note.NewRest(note.Half)},
+	))
+	assert.Equal(t, abc.Format(chords(
+		[]note.Note{note.NewNote(note.C4, note.Half), note.NewNote(note.E4, note.Half)},
+		[]note.Note{note.NewNote(note.G4, note.Half)},
+	)), formatted)
+	parse(t, formatted)
+}
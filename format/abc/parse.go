@@ -0,0 +1,978 @@
+package abc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// SyntaxError is returned for ABC text that can't be parsed, with the
+// position of the problem
+type SyntaxError struct {
+	// Line and Column start at 1. Columns count characters, not bytes
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error returns the position and description of the error
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Parse reads all the tunes of an ABC file. Each tune starts with an X:
+// field and ends with an empty line, the text before the first one is
+// ignored.
+//
+// It supports the X, T, C, M, L, Q, K and V fields, also inline as [K:G]; notes
+// with accidentals, octaves and lengths; rests; bar lines, repeats and first
+// and second endings; ties; chords; tuplets; broken rhythm (> and <); slurs;
+// and the decorations for dynamics, hairpins and articulations. Chord
+// symbols, grace notes, lyrics and the rest of decorations are ignored
+func Parse(r io.Reader) ([]Tune, error) {
+	var tunes []Tune
+	var p *parser
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		if p == nil {
+			if strings.HasPrefix(trimmed, "X:") {
+				p = newParser()
+				if err := p.line(line, text); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if trimmed == "" {
+			tunes = append(tunes, p.tune())
+			p = nil
+			continue
+		}
+		if err := p.line(line, text); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if p != nil {
+		tunes = append(tunes, p.tune())
+	}
+	return tunes, nil
+}
+
+// bar is a measure of a voice, with its repeat marks
+type bar struct {
+	// time is the meter when the bar ends
+	time        score.TimeSignature
+	groups      score.Voice
+	startRepeat bool
+	endRepeat   bool
+	// ending is the number of the first or second ending, or 0
+	ending int
+}
+
+// voice keeps the bars of a voice while it is read
+type voice struct {
+	bars []bar
+	cur  bar
+	// ending is the current ending number, for the next bars
+	ending int
+	// meter and key start as the ones of the header, and change with the
+	// fields in the voice
+	meter score.TimeSignature
+	key   key.Key
+}
+
+// decorations are waiting for the next note
+type decorations struct {
+	dynamic      note.Dynamic
+	hairpin      note.Hairpin
+	articulation note.Articulation
+}
+
+// parser keeps the state while reading a tune
+type parser struct {
+	t      Tune
+	header bool
+	titled bool
+	unit   note.Duration
+
+	voices  []*voice
+	voiceID map[string]int
+	v       *voice
+
+	// accidentals are the ones written in the current bar
+	accidentals map[pitchKey]note.Accidental
+	// target are the notes the next tie applies to, the last note or chord
+	target []note.Note
+	deco   decorations
+	slur   bool
+
+	// tuplet is the number of notes left in the current tuplet, and
+	// tupletFactor their length factor
+	tuplet       int
+	tupletFactor note.Rational
+	// broken is the length factor of the next note, after > or <
+	broken note.Rational
+
+	// position of the current character, for errors
+	lineNum int
+	col     int
+}
+
+func newParser() *parser {
+	p := &parser{
+		header:      true,
+		voiceID:     map[string]int{},
+		accidentals: map[pitchKey]note.Accidental{},
+	}
+	p.t.Meter = score.CommonTime
+	p.t.Key = key.New(note.NewSpelledPitch(note.C, note.Natural, 4), key.Major)
+	p.selectVoice("")
+	return p
+}
+
+// errorf returns a SyntaxError at the given column of the current line
+func (p *parser) errorf(col int, format string, a ...interface{}) error {
+	return &SyntaxError{Line: p.lineNum, Column: col, Msg: fmt.Sprintf(format, a...)}
+}
+
+// line reads a line of the tune, a field or music
+func (p *parser) line(num int, text string) error {
+	p.lineNum = num
+
+	if strings.HasPrefix(text, "%") {
+		return nil
+	}
+	if isField(text) {
+		return p.field(1, text[:1], text[2:])
+	}
+
+	if p.header {
+		p.endHeader()
+	}
+	return p.music([]rune(text))
+}
+
+// isField returns true for lines like "T:Title"
+func isField(text string) bool {
+	return len(text) >= 2 && text[1] == ':' &&
+		((text[0] >= 'A' && text[0] <= 'Z') || (text[0] >= 'a' && text[0] <= 'z'))
+}
+
+// endHeader sets the defaults of the fields that were not set
+func (p *parser) endHeader() {
+	p.header = false
+	if p.unit == 0 {
+		p.unit = defaultUnit(p.t.Meter)
+	}
+	p.t.Unit = p.unit
+}
+
+// field reads a field, col is the position of its name in the line
+func (p *parser) field(col int, name, value string) error {
+	value = strings.TrimSpace(stripComment(value))
+	valueCol := col + 2
+
+	switch name {
+	case "X":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return p.errorf(valueCol, "wrong reference number %q", value)
+		}
+		p.t.Number = n
+	case "T":
+		if !p.titled {
+			p.t.Title = value
+			p.titled = true
+		}
+	case "C":
+		p.t.Composer = value
+	case "M":
+		m := score.CommonTime
+		if value != "none" {
+			var err error
+			if m, err = score.ParseTimeSignature(value); err != nil {
+				return p.errorf(valueCol, "wrong meter %q", value)
+			}
+		}
+		p.setMeter(m)
+	case "L":
+		d, err := parseFraction(value)
+		if err != nil {
+			return p.errorf(valueCol, "wrong unit note length %q", value)
+		}
+		p.unit = d.Duration()
+	case "Q":
+		t, err := parseTempo(value, p.unit, p.t.Meter)
+		if err != nil {
+			return p.errorf(valueCol, "wrong tempo %q", value)
+		}
+		if p.t.Tempo.BPM == 0 {
+			p.t.Tempo = t
+		}
+	case "K":
+		k, err := parseKey(value)
+		if err != nil {
+			return p.errorf(valueCol, "wrong key %q", value)
+		}
+		p.setKey(k)
+		if p.header {
+			p.endHeader()
+		}
+	case "V":
+		id := value
+		if i := strings.IndexFunc(value, unicode.IsSpace); i >= 0 {
+			id = value[:i]
+		}
+		p.selectVoice(id)
+	}
+
+	return nil
+}
+
+// setMeter changes the meter of the tune in the header, and the one of the
+// current voice in the body
+func (p *parser) setMeter(m score.TimeSignature) {
+	if !p.header {
+		p.v.meter = m
+		return
+	}
+	p.t.Meter = m
+	for _, v := range p.voices {
+		v.meter = m
+	}
+}
+
+// setKey changes the key of the tune in the header, and the one of the
+// current voice in the body
+func (p *parser) setKey(k key.Key) {
+	if !p.header {
+		p.v.key = k
+		return
+	}
+	p.t.Key = k
+	for _, v := range p.voices {
+		v.key = k
+	}
+}
+
+// stripComment removes the comment at the end of a field
+func stripComment(s string) string {
+	if i := strings.Index(s, "%"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// selectVoice makes the voice with the given id the current one
+func (p *parser) selectVoice(id string) {
+	i, ok := p.voiceID[id]
+	if !ok {
+		i = len(p.voices)
+
+		// The implicit voice used before any V: field takes the id of the
+		// first one if it has no notes yet
+		if implicit, ok := p.voiceID[""]; ok && len(p.voiceID) == 1 && p.voices[implicit].empty() {
+			delete(p.voiceID, "")
+			i = implicit
+		} else {
+			p.voices = append(p.voices, &voice{meter: p.t.Meter, key: p.t.Key})
+		}
+		p.voiceID[id] = i
+	}
+
+	p.v = p.voices[i]
+	p.target = nil
+	p.accidentals = map[pitchKey]note.Accidental{}
+}
+
+// empty returns true if the voice has no notes
+func (v *voice) empty() bool {
+	return len(v.bars) == 0 && len(v.cur.groups) == 0
+}
+
+// parseFraction reads a fraction like "1/8"
+func parseFraction(s string) (note.Rational, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) != 2 {
+		return note.Rational{}, fmt.Errorf("%q is not a fraction", s)
+	}
+	num, err := strconv.Atoi(parts[0])
+	if err != nil || num <= 0 {
+		return note.Rational{}, fmt.Errorf("%q is not a fraction", s)
+	}
+	den, err := strconv.Atoi(parts[1])
+	if err != nil || den <= 0 {
+		return note.Rational{}, fmt.Errorf("%q is not a fraction", s)
+	}
+	return note.NewRational(int64(num), int64(den)), nil
+}
+
+// parseTempo reads a tempo like "1/4=120" or "3/8=60". Text between quotes
+// is ignored, and a tempo without a beat, like "120", uses the unit note
+// length
+func parseTempo(s string, unit note.Duration, meter score.TimeSignature) (score.Tempo, error) {
+	// remove the text between quotes
+	for {
+		start := strings.Index(s, `"`)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start+1:], `"`)
+		if end < 0 {
+			return score.Tempo{}, fmt.Errorf("unclosed quote")
+		}
+		s = s[:start] + s[start+1+end+1:]
+	}
+	s = strings.TrimSpace(s)
+
+	beat := unit
+	if beat == 0 {
+		beat = defaultUnit(meter)
+	}
+
+	bpm := s
+	if i := strings.Index(s, "="); i >= 0 {
+		// the beat can be a sum of fractions, e.g. "1/4 1/8=60"
+		var total note.Rational
+		for _, f := range strings.Fields(s[:i]) {
+			r, err := parseFraction(f)
+			if err != nil {
+				return score.Tempo{}, err
+			}
+			total = total.Add(r)
+		}
+		beat = total.Duration()
+		bpm = strings.TrimSpace(s[i+1:])
+	}
+
+	n, err := strconv.Atoi(bpm)
+	if err != nil || n <= 0 || beat <= 0 {
+		return score.Tempo{}, fmt.Errorf("wrong tempo %q", s)
+	}
+	return score.Tempo{Beat: beat, BPM: n}, nil
+}
+
+// parseKey reads a key field like "Em", "Bb major" or "D dor clef=bass".
+// "none" and an empty field are C major
+func parseKey(s string) (key.Key, error) {
+	// clef and other options are ignored
+	var fields []string
+	for _, f := range strings.Fields(s) {
+		if strings.Contains(f, "=") {
+			break
+		}
+		fields = append(fields, f)
+	}
+
+	if len(fields) == 0 || fields[0] == "none" || fields[0] == "HP" || fields[0] == "Hp" {
+		return key.New(note.NewSpelledPitch(note.C, note.Natural, 4), key.Major), nil
+	}
+
+	// the mode may be separated by a space
+	if len(fields) > 1 {
+		if k, err := key.Parse(fields[0] + " " + fields[1]); err == nil {
+			return k, nil
+		}
+	}
+	return key.Parse(fields[0])
+}
+
+// tune returns the tune read so far
+func (p *parser) tune() Tune {
+	if p.header {
+		p.endHeader()
+	}
+
+	var voices [][]bar
+	for _, v := range p.voices {
+		if len(v.cur.groups) > 0 {
+			p.v = v
+			p.endBar(false, false)
+		}
+		voices = append(voices, unfold(v.bars))
+	}
+
+	// Measures are made of the bars with the same index in each voice
+	t := p.t
+	for i := 0; ; i++ {
+		var m score.Measure
+		found := false
+		for _, bars := range voices {
+			if i < len(bars) {
+				if !found {
+					m.Time = bars[i].time
+				}
+				found = true
+				m.Voices = append(m.Voices, bars[i].groups)
+			}
+		}
+		if !found {
+			break
+		}
+		t.Staff = append(t.Staff, m)
+	}
+
+	return t
+}
+
+// unfold returns the bars in the order they are played, repeating the
+// sections between |: and :|, and choosing the first or second endings in
+// each pass
+func unfold(bars []bar) []bar {
+	var played []bar
+
+	start, pass := 0, 1
+	second := false
+	for i := 0; i < len(bars); i++ {
+		b := bars[i]
+		if b.startRepeat && !second {
+			start = i
+		}
+		if !second && b.ending == 0 {
+			pass = 1
+		}
+
+		if b.ending == 0 || b.ending == pass {
+			played = append(played, b)
+		}
+
+		if b.endRepeat {
+			if !second {
+				// play again from the start of the repeat
+				second, pass = true, 2
+				i = start - 1
+				continue
+			}
+			second = false
+			start = i + 1
+		}
+	}
+
+	return played
+}
+
+// endBar adds the current bar to the voice, if it has notes
+func (p *parser) endBar(startRepeat, endRepeat bool) {
+	v := p.v
+	p.accidentals = map[pitchKey]note.Accidental{}
+	p.target = nil
+
+	if len(v.cur.groups) == 0 {
+		// bar lines at the beginning of a line, or repeats after an
+		// ending are applied to the previous bar
+		if endRepeat && len(v.bars) > 0 {
+			v.bars[len(v.bars)-1].endRepeat = true
+		}
+		v.cur.startRepeat = v.cur.startRepeat || startRepeat
+		return
+	}
+
+	v.cur.time = v.meter
+	v.cur.endRepeat = endRepeat
+	v.bars = append(v.bars, v.cur)
+	v.cur = bar{startRepeat: startRepeat, ending: v.ending}
+}
+
+// music reads a line of notes
+func (p *parser) music(s []rune) error {
+	for i := 0; i < len(s); {
+		c := s[i]
+		p.col = i + 1
+
+		var err error
+		switch {
+		case c == '%':
+			return nil
+		case c == '\\':
+			// line continuation
+			i++
+		case unicode.IsSpace(c) || c == '`' || c == 'y':
+			i++
+		case c == '"':
+			i, err = p.skip(s, i, '"', "chord symbol")
+		case c == '{':
+			i, err = p.skip(s, i, '}', "grace notes")
+		case c == '!' || c == '+':
+			i, err = p.decoration(s, i)
+		case c == '.':
+			p.deco.articulation |= note.Staccato
+			i++
+		case strings.ContainsRune("~HIJKLMNOPQRSTUVWXYhijklmnopqrstuvw", c):
+			// ornaments and user defined decorations are ignored
+			i++
+		case c == '[' && i+2 < len(s) && isField(string(s[i+1:i+3])):
+			i, err = p.inlineField(s, i)
+		case c == '[' && i+1 < len(s) && unicode.IsDigit(s[i+1]):
+			i++
+			i, err = p.ending(s, i)
+		case c == '|' || c == ':' || (c == '[' && i+1 < len(s) && s[i+1] == '|'):
+			i, err = p.barLine(s, i)
+		case c == '[':
+			i, err = p.chord(s, i)
+		case c == '(':
+			if i+1 < len(s) && unicode.IsDigit(s[i+1]) {
+				i, err = p.tupletMark(s, i)
+			} else {
+				p.slur = true
+				i++
+			}
+		case c == ')':
+			p.slur = false
+			i++
+		case c == '-':
+			if p.target == nil {
+				return p.errorf(i+1, "tie must follow a note")
+			}
+			for j := range p.target {
+				p.target[j].Tie = true
+			}
+			i++
+		case c == '>' || c == '<':
+			i, err = p.brokenRhythm(s, i)
+		case c == 'Z':
+			i, err = p.multiMeasureRest(s, i)
+		case isNoteStart(c):
+			var n note.Note
+			n, i, err = p.note(s, i)
+			if err == nil {
+				p.addGroup([]note.Note{n})
+			}
+		default:
+			return p.errorf(i+1, "unexpected %q", c)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isNoteStart returns true for the characters that start a note or a rest
+func isNoteStart(c rune) bool {
+	return strings.ContainsRune("^_=ABCDEFGabcdefgzx", c)
+}
+
+// skip returns the position after the closing character
+func (p *parser) skip(s []rune, i int, closing rune, what string) (int, error) {
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == closing {
+			return j + 1, nil
+		}
+	}
+	return 0, p.errorf(i+1, "%s is not closed with %q", what, closing)
+}
+
+// decorationArticulations are the decorations between ! that set an
+// articulation
+var decorationArticulations = map[string]note.Articulation{
+	"staccato": note.Staccato,
+	"tenuto":   note.Tenuto,
+	"accent":   note.Accent,
+	">":        note.Accent,
+	"emphasis": note.Accent,
+	"marcato":  note.Marcato,
+	"^":        note.Marcato,
+}
+
+// decoration reads a decoration between ! or +
+func (p *parser) decoration(s []rune, i int) (int, error) {
+	end, err := p.skip(s, i, s[i], "decoration")
+	if err != nil {
+		return 0, err
+	}
+	name := string(s[i+1 : end-1])
+
+	if d, err := note.ParseDynamic(name); err == nil {
+		p.deco.dynamic = d
+	} else if a, ok := decorationArticulations[name]; ok {
+		p.deco.articulation |= a
+	} else {
+		switch name {
+		case "crescendo(", "<(":
+			p.deco.hairpin = note.Crescendo
+		case "diminuendo(", ">(":
+			p.deco.hairpin = note.Diminuendo
+		}
+	}
+	return end, nil
+}
+
+// inlineField reads a field between brackets, like [K:G]
+func (p *parser) inlineField(s []rune, i int) (int, error) {
+	end, err := p.skip(s, i, ']', "inline field")
+	if err != nil {
+		return 0, err
+	}
+	field := string(s[i+1 : end-1])
+	return end, p.field(i+2, field[:1], field[2:])
+}
+
+// ending reads the number of a first or second ending
+func (p *parser) ending(s []rune, i int) (int, error) {
+	start := i
+	for i < len(s) && unicode.IsDigit(s[i]) {
+		i++
+	}
+	n, _ := strconv.Atoi(string(s[start:i]))
+	if n < 1 || n > 2 {
+		return 0, p.errorf(start+1, "only first and second endings are supported")
+	}
+
+	p.v.ending = n
+	if len(p.v.cur.groups) == 0 {
+		p.v.cur.ending = n
+	}
+	return i, nil
+}
+
+// barLine reads a bar line, with repeats and endings
+func (p *parser) barLine(s []rune, i int) (int, error) {
+	start := i
+	if s[i] == '[' {
+		i++
+	}
+	for i < len(s) && (s[i] == '|' || s[i] == ':' || s[i] == ']') {
+		i++
+	}
+	token := string(s[start:i])
+	if !strings.Contains(token, "|") && token != "::" {
+		return 0, p.errorf(start+1, "unexpected %q", token)
+	}
+
+	endRepeat := strings.HasPrefix(token, ":")
+	startRepeat := strings.HasSuffix(token, ":")
+
+	// endings finish with a repeat or a double bar line
+	if endRepeat || startRepeat || strings.Contains(token, "||") ||
+		strings.Contains(token, "|]") || strings.Contains(token, "[|") {
+		p.v.ending = 0
+	}
+	p.endBar(startRepeat, endRepeat)
+
+	if i < len(s) && unicode.IsDigit(s[i]) {
+		return p.ending(s, i)
+	}
+	return i, nil
+}
+
+// tupletFactors are the default number of notes q in the time of which p
+// notes are played, for (p. 0 depends on the meter
+var tupletFactors = map[int]int{2: 3, 3: 2, 4: 3, 5: 0, 6: 2, 7: 0, 8: 3, 9: 0}
+
+// tupletMark reads a tuplet like (3 or (3:2:3
+func (p *parser) tupletMark(s []rune, i int) (int, error) {
+	col := i + 1
+	i++
+
+	var numbers []int
+	for len(numbers) < 3 {
+		start := i
+		for i < len(s) && unicode.IsDigit(s[i]) {
+			i++
+		}
+		n := 0
+		if i > start {
+			n, _ = strconv.Atoi(string(s[start:i]))
+		}
+		numbers = append(numbers, n)
+
+		if i >= len(s) || s[i] != ':' {
+			break
+		}
+		i++
+	}
+
+	n := numbers[0]
+	q, ok := tupletFactors[n]
+	if !ok {
+		return 0, p.errorf(col, "tuplets of %d notes are not supported", n)
+	}
+	if q == 0 {
+		q = 2
+		if m := p.v.meter; m.Value == 8 && m.Beats%3 == 0 {
+			q = 3
+		}
+	}
+	r := n
+	if len(numbers) > 1 && numbers[1] > 0 {
+		q = numbers[1]
+	}
+	if len(numbers) > 2 && numbers[2] > 0 {
+		r = numbers[2]
+	}
+
+	p.tuplet = r
+	p.tupletFactor = note.NewRational(int64(q), int64(n))
+	return i, nil
+}
+
+// brokenRhythm reads > or <, making the previous note longer and the next one
+// shorter, or the opposite
+func (p *parser) brokenRhythm(s []rune, i int) (int, error) {
+	col := i + 1
+	c := s[i]
+	n := 0
+	for i < len(s) && s[i] == c {
+		i++
+		n++
+	}
+	if p.target == nil {
+		return 0, p.errorf(col, "broken rhythm must follow a note")
+	}
+
+	// > is a dotted note followed by a shorter one, >> a double dotted...
+	short := note.NewRational(1, int64(1)<<uint(n))
+	long := note.NewRational(2, 1).Sub(short)
+	if c == '<' {
+		long, short = short, long
+	}
+
+	for j := range p.target {
+		p.target[j].Duration = p.target[j].Duration.Rational().Mul(long).Duration()
+	}
+	p.broken = short
+	return i, nil
+}
+
+// multiMeasureRest reads Z, a rest for a whole bar, or Zn for n bars
+func (p *parser) multiMeasureRest(s []rune, i int) (int, error) {
+	i++
+	start := i
+	for i < len(s) && unicode.IsDigit(s[i]) {
+		i++
+	}
+	bars := 1
+	if i > start {
+		bars, _ = strconv.Atoi(string(s[start:i]))
+	}
+
+	for b := 0; b < bars; b++ {
+		if b > 0 {
+			p.endBar(false, false)
+		}
+		p.v.cur.groups = append(p.v.cur.groups, []note.Note{note.NewRest(p.v.meter.Duration().Duration())})
+	}
+	p.target = nil
+	return i, nil
+}
+
+// chord reads notes between [ and ], and their length multiplier
+func (p *parser) chord(s []rune, i int) (int, error) {
+	col := i + 1
+	i++
+
+	// decorations before the chord apply to all its notes, the ones inside
+	// only to the next note
+	all := p.deco.articulation
+	p.deco.articulation = 0
+
+	var chord []note.Note
+	for {
+		if i >= len(s) {
+			return 0, p.errorf(col, "chord is not closed with ]")
+		}
+		c := s[i]
+		p.col = i + 1
+
+		var err error
+		switch {
+		case c == ']':
+			i++
+			if len(chord) == 0 {
+				return 0, p.errorf(col, "empty chord")
+			}
+
+			var length note.Rational
+			length, i, err = p.length(s, i)
+			if err != nil {
+				return 0, err
+			}
+			for j := range chord {
+				chord[j].Duration = chord[j].Duration.Rational().Mul(length).Duration()
+			}
+			p.deco.articulation = all
+			p.addGroup(chord)
+			return i, nil
+		case c == ' ':
+			i++
+		case c == '-':
+			if len(chord) == 0 {
+				return 0, p.errorf(i+1, "tie must follow a note")
+			}
+			chord[len(chord)-1].Tie = true
+			i++
+		case c == '.':
+			p.deco.articulation |= note.Staccato
+			i++
+		case c == '!' || c == '+':
+			i, err = p.decoration(s, i)
+		case isNoteStart(c) && c != 'z' && c != 'x':
+			var n note.Note
+			n, i, err = p.note(s, i)
+			if err == nil {
+				chord = append(chord, n)
+			}
+		default:
+			return 0, p.errorf(i+1, "unexpected %q in chord", c)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// note reads a note or a rest with its length. Pending decorations are
+// applied to it
+func (p *parser) note(s []rune, i int) (note.Note, int, error) {
+	col := i + 1
+
+	var n note.Note
+	if s[i] == 'z' || s[i] == 'x' {
+		n = note.NewRest(0)
+		i++
+	} else {
+		var accidental note.Accidental
+		explicit := false
+		for ; i < len(s) && strings.ContainsRune("^_=", s[i]); i++ {
+			explicit = true
+			switch s[i] {
+			case '^':
+				accidental++
+			case '_':
+				accidental--
+			}
+		}
+
+		if i >= len(s) || !strings.ContainsRune("ABCDEFGabcdefg", s[i]) {
+			return n, 0, p.errorf(col, "accidental without a note")
+		}
+		c := s[i]
+		i++
+
+		octave := 4
+		if unicode.IsLower(c) {
+			octave = 5
+		}
+		for ; i < len(s) && (s[i] == '\'' || s[i] == ','); i++ {
+			if s[i] == '\'' {
+				octave++
+			} else {
+				octave--
+			}
+		}
+
+		letter := note.Letter((unicode.ToLower(c) - 'a' + 5) % 7)
+		k := pitchKey{letter, octave}
+		if explicit {
+			p.accidentals[k] = accidental
+		} else if a, ok := p.accidentals[k]; ok {
+			accidental = a
+		} else {
+			accidental = p.v.key.Signature().Accidental(letter)
+		}
+
+		pitch := note.NewSpelledPitch(letter, accidental, octave)
+		if pitch.Key() < note.MinKey || pitch.Key() > note.MaxKey {
+			return n, 0, p.errorf(col, "%v is outside of the range C-1 to G9", pitch)
+		}
+		n = note.NewNote(pitch, 0)
+		if p.slur {
+			n.Articulation |= note.Legato
+		}
+	}
+
+	length, i, err := p.length(s, i)
+	if err != nil {
+		return n, 0, err
+	}
+	n.Duration = p.unit.Rational().Mul(length).Duration()
+
+	// pending decorations apply to the next note, or to all the notes of
+	// the next chord
+	n.Articulation |= p.deco.articulation
+	p.deco.articulation = 0
+	return n, i, nil
+}
+
+// length reads a length multiplier like 2, 3/2, / or //
+func (p *parser) length(s []rune, i int) (note.Rational, int, error) {
+	col := i + 1
+	num, den := 1, 1
+
+	start := i
+	for i < len(s) && unicode.IsDigit(s[i]) {
+		i++
+	}
+	if i > start {
+		num, _ = strconv.Atoi(string(s[start:i]))
+	}
+
+	if i < len(s) && s[i] == '/' {
+		slashes := 0
+		for i < len(s) && s[i] == '/' {
+			i++
+			slashes++
+		}
+
+		start := i
+		for i < len(s) && unicode.IsDigit(s[i]) {
+			i++
+		}
+		if i > start {
+			den, _ = strconv.Atoi(string(s[start:i]))
+			if slashes > 1 {
+				return note.Rational{}, 0, p.errorf(col, "wrong note length")
+			}
+		} else {
+			den = 1 << uint(slashes)
+		}
+	}
+
+	if num == 0 || den == 0 {
+		return note.Rational{}, 0, p.errorf(col, "wrong note length")
+	}
+	return note.NewRational(int64(num), int64(den)), i, nil
+}
+
+// addGroup adds a note or chord to the current bar, applying the tuplet,
+// broken rhythm and decorations
+func (p *parser) addGroup(group []note.Note) {
+	var factor = note.NewRational(1, 1)
+	if p.tuplet > 0 {
+		factor = factor.Mul(p.tupletFactor)
+		p.tuplet--
+	}
+	if p.broken != (note.Rational{}) {
+		factor = factor.Mul(p.broken)
+		p.broken = note.Rational{}
+	}
+
+	for i := range group {
+		group[i].Duration = group[i].Duration.Rational().Mul(factor).Duration()
+		group[i].Articulation |= p.deco.articulation
+	}
+	group[0].Dynamic = p.deco.dynamic
+	group[0].Hairpin = p.deco.hairpin
+	p.deco = decorations{}
+
+	p.v.cur.groups = append(p.v.cur.groups, group)
+	p.target = group
+}
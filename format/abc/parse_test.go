@@ -0,0 +1,289 @@
+package abc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/abc"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sp = note.NewSpelledPitch
+
+func parse(t *testing.T, s string) abc.Tune {
+	t.Helper()
+	tunes, err := abc.Parse(strings.NewReader(s))
+	require.NoError(t, err)
+	require.Len(t, tunes, 1)
+	return tunes[0]
+}
+
+// pitches returns the pitches of each measure of the first voice
+func pitches(staff score.Staff) [][]string {
+	var all [][]string
+	for _, m := range staff {
+		var names []string
+		for _, g := range m.Voices[0] {
+			for _, n := range g {
+				names = append(names, n.Pitch.String())
+			}
+		}
+		all = append(all, names)
+	}
+	return all
+}
+
+func TestParseHeader(t *testing.T) {
+	tunes, err := abc.Parse(strings.NewReader(`% a comment before the tunes
+X:3
+T:Speed the Plough
+T:Second title
+C:Trad.
+M:6/8
+L:1/8
+Q:"Allegro" 3/8=80
+K:Em
+EGB
+
+X:4
+M:C|
+K:D dor
+D
+
+X:5
+M:2/4
+K:none
+C
+`))
+	require.NoError(t, err)
+	require.Len(t, tunes, 3)
+
+	tune := tunes[0]
+	assert.Equal(t, 3, tune.Number)
+	assert.Equal(t, "Speed the Plough", tune.Title)
+	assert.Equal(t, "Trad.", tune.Composer)
+	assert.Equal(t, score.TimeSignature{Beats: 6, Value: 8}, tune.Meter)
+	assert.Equal(t, note.Eighth, tune.Unit)
+	assert.Equal(t, score.Tempo{Beat: note.Quarter.Dotted(), BPM: 80}, tune.Tempo)
+	assert.Equal(t, key.Key{Tonic: sp(note.E, note.Natural, 4), Mode: key.Minor}, tune.Key)
+
+	assert.Equal(t, score.TimeSignature{Beats: 2, Value: 2}, tunes[1].Meter)
+	assert.Equal(t, note.Eighth, tunes[1].Unit)
+	assert.Equal(t, key.Dorian, tunes[1].Key.Mode)
+	assert.Equal(t, note.D, tunes[1].Key.Tonic.Letter)
+
+	assert.Equal(t, note.Sixteenth, tunes[2].Unit)
+	assert.Equal(t, note.C, tunes[2].Key.Tonic.Letter)
+	assert.Equal(t, key.Major, tunes[2].Key.Mode)
+	assert.Equal(t, note.Sixteenth, tunes[2].Staff[0].Voices[0][0][0].Duration)
+}
+
+func TestParseNotes(t *testing.T) {
+	tune := parse(t, "X:1\nL:1/8\nK:C\nC, C c c' z A,,2 B/ B// B3/2 B/4\n")
+	require.Len(t, tune.Staff, 1)
+
+	assert.Equal(t, score.Voice{
+		{note.NewNote(sp(note.C, note.Natural, 3), note.Eighth)},
+		{note.NewNote(sp(note.C, note.Natural, 4), note.Eighth)},
+		{note.NewNote(sp(note.C, note.Natural, 5), note.Eighth)},
+		{note.NewNote(sp(note.C, note.Natural, 6), note.Eighth)},
+		{note.NewRest(note.Eighth)},
+		{note.NewNote(sp(note.A, note.Natural, 2), note.Quarter)},
+		{note.NewNote(sp(note.B, note.Natural, 4), note.Sixteenth)},
+		{note.NewNote(sp(note.B, note.Natural, 4), note.Sixteenth/2)},
+		{note.NewNote(sp(note.B, note.Natural, 4), note.Eighth.Dotted())},
+		{note.NewNote(sp(note.B, note.Natural, 4), note.Sixteenth/2)},
+	}, tune.Staff[0].Voices[0])
+}
+
+func TestParseAccidentals(t *testing.T) {
+	tune := parse(t, "X:1\nK:D\nF ^C =F F f | _B __B ^^G G | F =c c\n")
+
+	assert.Equal(t, [][]string{
+		{"F#4", "C#4", "F4", "F4", "F#5"},
+		{"Bb4", "Bbb4", "G##4", "G##4"},
+		{"F#4", "C5", "C5"},
+	}, pitches(tune.Staff))
+}
+
+func TestParseInlineFields(t *testing.T) {
+	tune := parse(t, "X:1\nM:2/4\nL:1/4\nK:C\nF G | [K:F] B [M:3/4] B B |\nB B B |\n")
+	require.Len(t, tune.Staff, 3)
+
+	assert.Equal(t, [][]string{{"F4", "G4"}, {"Bb4", "Bb4", "Bb4"}, {"Bb4", "Bb4", "Bb4"}}, pitches(tune.Staff))
+	assert.Equal(t, score.TimeSignature{Beats: 2, Value: 4}, tune.Staff[0].Time)
+	assert.Equal(t, score.TimeSignature{Beats: 3, Value: 4}, tune.Staff[1].Time)
+	assert.Equal(t, score.TimeSignature{Beats: 3, Value: 4}, tune.Staff[2].Time)
+
+	// the header fields are kept
+	assert.Equal(t, score.TimeSignature{Beats: 2, Value: 4}, tune.Meter)
+	assert.Equal(t, note.C, tune.Key.Tonic.Letter)
+}
+
+func TestParseRepeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		music    string
+		expected string
+	}{
+		{"simple", "|: A | B :| C |]", "A B A B C"},
+		{"implicit start", "A | B :| C |]", "A B A B C"},
+		{"endings", "|: A |1 B :|2 C || D |]", "A B A C D"},
+		{"endings on new line", "|: A |1 B :|\n|2 C |]", "A B A C"},
+		{"two repeats", "|: A :|: B :|", "A A B B"},
+		{"double bar", "A || B [| C |]", "A B C"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tune := parse(t, "X:1\nL:1/4\nK:C\n"+test.music+"\n")
+
+			var played []string
+			for _, m := range pitches(tune.Staff) {
+				played = append(played, strings.Join(m, " "))
+			}
+			assert.Equal(t, test.expected, strings.Replace(strings.Join(played, " "), "4", "", -1))
+		})
+	}
+}
+
+func TestParseRhythms(t *testing.T) {
+	tune := parse(t, "X:1\nL:1/8\nK:C\n(3ABc A>B A<B A>>B (3:2:2A2B (5ABcde\n")
+	v := tune.Staff[0].Voices[0]
+
+	var durations []note.Duration
+	for _, g := range v {
+		durations = append(durations, g[0].Duration)
+	}
+
+	triplet := note.Eighth.Triplet()
+	quintuplet := note.Eighth.Tuplet(5, 2)
+	assert.Equal(t, []note.Duration{
+		triplet, triplet, triplet,
+		note.Eighth.Dotted(), note.Sixteenth,
+		note.Sixteenth, note.Eighth.Dotted(),
+		note.Eighth.DoubleDotted(), note.Sixteenth / 2,
+		note.Quarter.Triplet(), triplet,
+		quintuplet, quintuplet, quintuplet, quintuplet, quintuplet,
+	}, durations)
+}
+
+func TestParseChordsAndTies(t *testing.T) {
+	tune := parse(t, "X:1\nL:1/4\nK:C\n[CEG]2 [C2E]- [CE] \"Am\"{g}A- | A\n")
+	v := tune.Staff[0].Voices[0]
+	require.Len(t, v, 4)
+
+	c4, e4 := sp(note.C, note.Natural, 4), sp(note.E, note.Natural, 4)
+	assert.Equal(t, []note.Note{
+		note.NewNote(c4, note.Half),
+		note.NewNote(e4, note.Half),
+		note.NewNote(sp(note.G, note.Natural, 4), note.Half),
+	}, v[0])
+	assert.Equal(t, []note.Note{
+		note.NewNote(c4, note.Half).Tied(),
+		note.NewNote(e4, note.Quarter).Tied(),
+	}, v[1])
+	assert.Equal(t, []note.Note{note.NewNote(c4, note.Quarter), note.NewNote(e4, note.Quarter)}, v[2])
+	assert.Equal(t, []note.Note{note.NewNote(sp(note.A, note.Natural, 4), note.Quarter).Tied()}, v[3])
+}
+
+func TestParseDecorations(t *testing.T) {
+	tune := parse(t, "X:1\nL:1/4\nK:C\n!p!A !crescendo(!B !f!.c !accent!!tenuto!d | (AB) !marcato![CE] ~A\n")
+
+	var notes []note.Note
+	for _, m := range tune.Staff {
+		for _, g := range m.Voices[0] {
+			notes = append(notes, g...)
+		}
+	}
+	require.Len(t, notes, 9)
+
+	assert.Equal(t, note.Piano, notes[0].Dynamic)
+	assert.Equal(t, note.Crescendo, notes[1].Hairpin)
+	assert.Equal(t, note.Forte, notes[2].Dynamic)
+	assert.Equal(t, note.Staccato, notes[2].Articulation)
+	assert.Equal(t, note.Accent|note.Tenuto, notes[3].Articulation)
+	assert.Equal(t, note.Legato, notes[4].Articulation)
+	assert.Equal(t, note.Legato, notes[5].Articulation)
+	assert.Equal(t, note.Marcato, notes[6].Articulation)
+	assert.Equal(t, note.Marcato, notes[7].Articulation)
+	assert.Equal(t, note.Articulation(0), notes[8].Articulation)
+}
+
+func TestParseVoices(t *testing.T) {
+	tune := parse(t, `X:1
+M:2/4
+L:1/4
+K:C
+V:1
+c d | e f |
+V:2
+C2 | Z |
+V:1
+g a |
+`)
+	require.Len(t, tune.Staff, 3)
+
+	assert.Len(t, tune.Staff[0].Voices, 2)
+	assert.Len(t, tune.Staff[1].Voices, 2)
+	assert.Len(t, tune.Staff[2].Voices, 1)
+	assert.Equal(t, score.Voice{{note.NewRest(note.Half)}}, tune.Staff[1].Voices[1])
+	assert.Equal(t, [][]string{{"C5", "D5"}, {"E5", "F5"}, {"G5", "A5"}}, pitches(tune.Staff))
+	assert.NoError(t, tune.Staff.Validate())
+}
+
+func TestParseMultipleTunes(t *testing.T) {
+	tunes, err := abc.Parse(strings.NewReader("X:1\nT:One\nK:C\nC\n\nX:2\nT:Two\nK:G\nF\n"))
+	require.NoError(t, err)
+	require.Len(t, tunes, 2)
+
+	assert.Equal(t, "One", tunes[0].Title)
+	assert.Equal(t, "Two", tunes[1].Title)
+	assert.Equal(t, [][]string{{"F#4"}}, pitches(tunes[1].Staff))
+}
+
+func TestTuneScore(t *testing.T) {
+	tune := parse(t, "X:1\nT:Tune\nC:Someone\nQ:1/4=90\nK:G\nC\n")
+	s := tune.Score()
+
+	assert.Equal(t, "Tune", s.Title)
+	assert.Equal(t, "Someone", s.Composer)
+	assert.Equal(t, tune.Key, s.Key)
+	assert.Equal(t, score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 90}), s.Tempo)
+	require.Len(t, s.Parts, 1)
+	assert.Equal(t, []score.Staff{tune.Staff}, s.Parts[0].Staves)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		abc string
+		err string
+	}{
+		{"X:one\nK:C\n", "line 1, column 3: wrong reference number \"one\""},
+		{"X:1\nM:3/x\nK:C\n", "line 2, column 3: wrong meter \"3/x\""},
+		{"X:1\nL:0\nK:C\n", "line 2, column 3: wrong unit note length \"0\""},
+		{"X:1\nQ:1/4=0\nK:C\n", "line 2, column 3: wrong tempo \"1/4=0\""},
+		{"X:1\nK:H\n", "line 2, column 3: wrong key \"H\""},
+		{"X:1\nK:C\nC D $\n", "line 3, column 5: unexpected '$'"},
+		{"X:1\nK:C\nC !foo\n", "line 3, column 3: decoration is not closed with '!'"},
+		{"X:1\nK:C\n[CE\n", "line 3, column 1: chord is not closed with ]"},
+		{"X:1\nK:C\nC/0\n", "line 3, column 2: wrong note length"},
+		{"X:1\nK:C\n- C\n", "line 3, column 1: tie must follow a note"},
+		{"X:1\nK:C\nC |3 D\n", "line 3, column 4: only first and second endings are supported"},
+		{"X:1\nK:C\n(10CDEFGABcde\n", "line 3, column 1: tuplets of 10 notes are not supported"},
+		{"X:1\nK:C\nc''''''\n", "line 3, column 1: C11 is outside of the range C-1 to G9"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err, func(t *testing.T) {
+			_, err := abc.Parse(strings.NewReader(test.abc))
+			require.Error(t, err)
+			assert.Equal(t, test.err, err.Error())
+			assert.IsType(t, &abc.SyntaxError{}, err)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package abc
+
+import (
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// Tune is a tune written in ABC notation
+type Tune struct {
+	// Number is the reference number, the X: field
+	Number int
+	// Title is the first T: field
+	Title string
+	// Composer is the C: field
+	Composer string
+	// Meter is the M: field, 4/4 if it is not set
+	Meter score.TimeSignature
+	// Unit is the length of the notes without a multiplier, the L: field. If
+	// it is not set it is 1/16 for meters below 3/4, and 1/8 for the rest
+	Unit note.Duration
+	// Tempo is the Q: field. BPM is 0 if it is not set
+	Tempo score.Tempo
+	// Key is the K: field
+	Key key.Key
+	// Staff has the notes of all the voices. Repeats are unfolded, measures
+	// are in the order they are played
+	Staff score.Staff
+}
+
+// Score returns a score with a single part with the tune
+func (t Tune) Score() score.Score {
+	s := score.Score{
+		Title:    t.Title,
+		Composer: t.Composer,
+		Key:      t.Key,
+		Parts: []score.Part{
+			{Name: t.Title, Staves: []score.Staff{t.Staff}},
+		},
+	}
+	if t.Tempo.BPM > 0 {
+		s.Tempo = score.ConstantTempo(t.Tempo)
+	}
+	return s
+}
+
+// defaultUnit returns the unit note length used when the L: field is not
+// set, based on the meter
+func defaultUnit(meter score.TimeSignature) note.Duration {
+	if meter.Duration().Cmp(note.NewRational(3, 4)) < 0 {
+		return note.Sixteenth
+	}
+	return note.Eighth
+}
+
+// pitchKey identifies a note name in a bar, to apply accidentals to the rest
+// of notes with the same letter and octave
+type pitchKey struct {
+	letter note.Letter
+	octave int
+}
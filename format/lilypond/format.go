@@ -0,0 +1,415 @@
+package lilypond
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// Version is the LilyPond version written in the files
+const Version = "2.20.0"
+
+// Format returns the score as LilyPond source, with a staff for each staff
+// of the parts and a piano staff for parts with several staves.
+//
+// The notes of each measure are split into tied notes that show the beats,
+// see score.TimeSignature.Notate, so LilyPond beams them by beat. Each staff
+// has the clef that fits its notes, see score.Staff.Clef. Pitches are
+// spelled for the key of the score, unless they are note.SpelledPitch. The
+// tempo changes are written in the first staff
+func Format(s score.Score) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\\version %s\n", quote(Version))
+
+	if s.Title != "" || s.Composer != "" || s.Arranger != "" {
+		b.WriteString("\n\\header {\n")
+		for _, field := range []struct{ name, value string }{
+			{"title", s.Title}, {"composer", s.Composer}, {"arranger", s.Arranger},
+		} {
+			if field.value != "" {
+				fmt.Fprintf(&b, "  %s = %s\n", field.name, quote(field.value))
+			}
+		}
+		b.WriteString("}\n")
+	}
+
+	changes := append([]score.TempoChange(nil), s.Tempo.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Position.Cmp(changes[j].Position) < 0
+	})
+
+	b.WriteString("\n\\score {\n  <<\n")
+	for i, p := range s.Parts {
+		var with string
+		if p.Name != "" {
+			with = ` \with { instrumentName = ` + quote(p.Name) + ` }`
+		}
+
+		if len(p.Staves) == 1 {
+			b.WriteString(`    \new Staff` + with + " ")
+			b.WriteString(newStaffWriter(s, p.Staves[0], i == 0, changes).write("    "))
+			continue
+		}
+
+		b.WriteString(`    \new PianoStaff` + with + " <<\n")
+		for j, st := range p.Staves {
+			b.WriteString(`      \new Staff `)
+			b.WriteString(newStaffWriter(s, st, i == 0 && j == 0, changes).write("      "))
+		}
+		b.WriteString("    >>\n")
+	}
+	b.WriteString("  >>\n  \\layout { }\n}\n")
+
+	return b.String()
+}
+
+// quote returns the string in double quotes, escaping quotes and
+// backslashes
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// staffWriter keeps the state while writing a staff
+type staffWriter struct {
+	s     score.Score
+	staff score.Staff
+	// notated are the voices of each measure, see score.TimeSignature.Notate
+	notated [][]score.Voice
+	// changes are the tempo changes still to write, sorted by position
+	changes []score.TempoChange
+	// last is the previous written duration, only written when it changes
+	last string
+	// hairpin is true when the last hairpin has not ended
+	hairpin bool
+	// slurs are the voices inside a slur
+	slurs map[int]bool
+}
+
+// newStaffWriter returns a writer for a staff. The tempo changes are only
+// written if tempo is true
+func newStaffWriter(s score.Score, staff score.Staff, tempo bool, changes []score.TempoChange) *staffWriter {
+	w := &staffWriter{s: s, staff: staff, slurs: map[int]bool{}}
+	if tempo {
+		w.changes = changes
+	}
+
+	w.notated = make([][]score.Voice, len(staff))
+	for i, m := range staff {
+		for _, v := range m.Voices {
+			w.notated[i] = append(w.notated[i], m.Time.Notate(v))
+		}
+	}
+	return w
+}
+
+// write returns the music of the staff in braces, one measure per line
+func (w *staffWriter) write(indent string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+
+	var time score.TimeSignature
+	var start note.Rational
+	for i, m := range w.staff {
+		var items []string
+		if i == 0 {
+			k := w.s.Key
+			items = append(items,
+				`\clef `+w.staff.Clef().String(),
+				`\key `+noteName(k.Tonic)+` \`+k.Mode.String())
+		}
+		if m.Time != time {
+			items = append(items, `\time `+m.Time.String())
+			time = m.Time
+		}
+		if d := m.Duration(); i == 0 && d.Cmp(note.Rational{}) > 0 && d.Cmp(m.Time.Duration()) < 0 {
+			items = append(items, `\partial `+Duration(d.Duration()))
+		}
+
+		if len(m.Voices) == 1 {
+			items = append(items, w.voice(i, 0, start)...)
+		} else {
+			items = append(items, "<<")
+			for j := range m.Voices {
+				if j > 0 {
+					items = append(items, `\\`)
+				}
+				items = append(items, "{")
+				items = append(items, w.voice(i, j, start)...)
+				items = append(items, "}")
+			}
+			items = append(items, ">>")
+		}
+
+		if i == len(w.staff)-1 && w.hairpin {
+			// an empty chord ends the hairpin at the end of the staff
+			items = append(items, `<>\!`)
+		}
+
+		items = append(items, "|")
+		b.WriteString(indent + "  " + strings.Join(items, " ") + "\n")
+		start = start.Add(m.Duration())
+	}
+
+	b.WriteString(indent + "}\n")
+	return b.String()
+}
+
+// voice returns the notes of voice j of measure m, that starts at start
+// from the beginning of the score
+func (w *staffWriter) voice(m, j int, start note.Rational) []string {
+	v := w.notated[m][j]
+	time := w.staff[m].Time
+
+	if len(v) == 1 && score.IsRestGroup(v[0]) && v.Duration() == time.Duration() {
+		d := Duration(time.Duration().Duration())
+		if _, ok := time.Duration().Duration().Notation(); !ok {
+			d = fmt.Sprintf("%d*%d", time.Value, time.Beats)
+		}
+		// the duration of measure rests is always written
+		w.last = d
+		return []string{"R" + d}
+	}
+
+	var items []string
+	var tuplet note.Rational
+	var ratio string
+	endTuplet := func() {
+		if ratio != "" {
+			items = append(items, "}")
+			tuplet, ratio = note.Rational{}, ""
+		}
+	}
+
+	pos := start
+	for g, group := range v {
+		if j == 0 {
+			for len(w.changes) > 0 && w.changes[0].Position.Cmp(pos) <= 0 {
+				items = append(items, tempo(w.changes[0].Tempo))
+				w.changes = w.changes[1:]
+			}
+		}
+
+		d := group[0].Duration
+		n, ok := d.Notation()
+		written := Duration(d)
+		if ok && n.Tuplet != 0 {
+			r := fmt.Sprintf("%d/%d", n.Tuplet, n.Normal)
+			if r != ratio {
+				endTuplet()
+				items = append(items, `\tuplet `+r+" {")
+				ratio = r
+			}
+			written = value(n.Value, n.Dots)
+		} else {
+			endTuplet()
+		}
+
+		items = append(items, w.group(j, group, written, w.next(m, j, g)))
+
+		// tuplets end when their notes add up to a note value
+		if ratio != "" {
+			tuplet = tuplet.Add(d.Rational())
+			if tuplet.Den()&(tuplet.Den()-1) == 0 {
+				endTuplet()
+			}
+		}
+		pos = pos.Add(d.Rational())
+	}
+	endTuplet()
+	return items
+}
+
+// next returns the group after group g of voice j in measure m, or nil if it
+// is the last one
+func (w *staffWriter) next(m, j, g int) []note.Note {
+	if v := w.notated[m][j]; g+1 < len(v) {
+		return v[g+1]
+	}
+	if m+1 < len(w.notated) && j < len(w.notated[m+1]) {
+		if v := w.notated[m+1][j]; len(v) > 0 {
+			return v[0]
+		}
+	}
+	return nil
+}
+
+// articulationSymbols are the symbols written for each articulation, in order
+var articulationSymbols = []struct {
+	a      note.Articulation
+	symbol string
+}{
+	{note.Staccato, "-."},
+	{note.Tenuto, "--"},
+	{note.Accent, "->"},
+	{note.Marcato, "-^"},
+}
+
+// group returns a note, rest or chord of voice j with the written duration
+// and its markings. next is the following group in the voice, to close the
+// slurs
+func (w *staffWriter) group(j int, group []note.Note, written string, next []note.Note) string {
+	var notes []note.Note
+	var names []string
+	for _, n := range group {
+		if s, ok := note.SpellWith(w.s.Key, n.Pitch); ok {
+			notes = append(notes, n)
+			names = append(names, PitchName(s))
+		}
+	}
+	if len(notes) == 0 {
+		return "r" + w.duration(written)
+	}
+
+	var b strings.Builder
+	if len(notes) > 1 {
+		b.WriteString("<")
+	}
+	for i, n := range notes {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(names[i])
+		if len(notes) > 1 {
+			b.WriteString(marks(n))
+		}
+	}
+	if len(notes) > 1 {
+		b.WriteString(">" + w.duration(written))
+	} else {
+		b.WriteString(w.duration(written) + marks(notes[0]))
+	}
+
+	dyn, hairpin := note.NoDynamic, note.NoHairpin
+	for _, n := range notes {
+		if dyn == note.NoDynamic {
+			dyn = n.Dynamic
+		}
+		if hairpin == note.NoHairpin {
+			hairpin = n.Hairpin
+		}
+	}
+	if dyn != note.NoDynamic {
+		b.WriteString(`\` + dyn.String())
+		w.hairpin = false
+	}
+	if hairpin != note.NoHairpin {
+		b.WriteString(`\` + hairpin.String())
+		w.hairpin = true
+	}
+
+	// slurs of a single note are not written
+	if legato := score.IsLegato(group); legato && !w.slurs[j] && score.IsLegato(next) {
+		b.WriteString("(")
+		w.slurs[j] = true
+	} else if legato && w.slurs[j] && !score.IsLegato(next) {
+		b.WriteString(")")
+		delete(w.slurs, j)
+	}
+	return b.String()
+}
+
+// marks returns the tie and articulations of a note
+func marks(n note.Note) string {
+	if n.Tie {
+		return "~" + Articulations(n.Articulation)
+	}
+	return Articulations(n.Articulation)
+}
+
+// Articulations returns the symbols of the articulations, e.g. "-." for
+// staccato. Legato is written with slurs
+func Articulations(a note.Articulation) string {
+	var b strings.Builder
+	for _, s := range articulationSymbols {
+		if a.Has(s.a) {
+			b.WriteString(s.symbol)
+		}
+	}
+	return b.String()
+}
+
+// duration returns the written duration, or an empty string if it is the
+// same as the previous one
+func (w *staffWriter) duration(d string) string {
+	if d == w.last {
+		return ""
+	}
+	w.last = d
+	return d
+}
+
+// tempo returns the tempo marking. Beats that cannot be written with a
+// single note are written in quarters
+func tempo(t score.Tempo) string {
+	if n, ok := t.Beat.Notation(); ok && n.Tuplet == 0 {
+		return fmt.Sprintf(`\tempo %s = %d`, value(n.Value, n.Dots), t.BPM)
+	}
+	quarters := float64(t.BPM) * float64(t.Beat) / float64(note.Quarter)
+	return fmt.Sprintf(`\tempo 4 = %d`, int(math.Round(quarters)))
+}
+
+// value returns a note value with dots, e.g. "4." for a dotted quarter or
+// "\breve" for a double whole note
+func value(v note.Duration, dots int) string {
+	s := `\breve`
+	if v <= note.Whole {
+		s = fmt.Sprint(int(math.Round(float64(note.Whole / v))))
+	}
+	return s + strings.Repeat(".", dots)
+}
+
+// Duration returns the note value with dots, or with a scaling factor for
+// tuplets and other durations, e.g. "4." or "8*2/3". Double whole notes are
+// written as "1*2", as \breve is not read by text.ParseStaff
+func Duration(d note.Duration) string {
+	r := d.Rational()
+
+	for value := 1; value <= 128; value *= 2 {
+		for dots := 0; dots <= 3; dots++ {
+			if (note.Whole / note.Duration(value)).Dots(dots).Rational() == r {
+				return fmt.Sprintf("%d%s", value, strings.Repeat(".", dots))
+			}
+		}
+	}
+
+	// the shortest note value that is not shorter than d, scaled down
+	value := 1
+	for value < 128 && note.NewRational(1, int64(value*2)).Cmp(r) >= 0 {
+		value *= 2
+	}
+	factor := r.Mul(note.NewRational(int64(value), 1))
+	if factor.Den() == 1 {
+		return fmt.Sprintf("%d*%d", value, factor.Num())
+	}
+	return fmt.Sprintf("%d*%v", value, factor)
+}
+
+// PitchName returns the note name with the octave, e.g. "bes'" for Bb4
+func PitchName(s note.SpelledPitch) string {
+	var b strings.Builder
+	b.WriteString(noteName(s))
+	for o := s.Octave; o > 3; o-- {
+		b.WriteString("'")
+	}
+	for o := s.Octave; o < 3; o++ {
+		b.WriteString(",")
+	}
+	return b.String()
+}
+
+// noteName returns the note name without the octave, e.g. "bes"
+func noteName(s note.SpelledPitch) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(s.Letter.String()))
+	for a := s.Accidental; a > 0; a-- {
+		b.WriteString("is")
+	}
+	for a := s.Accidental; a < 0; a++ {
+		b.WriteString("es")
+	}
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package lilypond_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/format/lilypond"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	cm, err := key.Parse("Cm")
+	require.NoError(t, err)
+	waltz := score.TimeSignature{Beats: 3, Value: 4}
+	triplet := note.Eighth.Triplet()
+
+	s := score.Score{
+		Title:    `The "Marble"`,
+		Composer: "Traditional",
+		Key:      cm,
+		Tempo: score.TempoMap{Changes: []score.TempoChange{
+			{Position: note.NewRational(3, 2), Tempo: score.Tempo{Beat: note.Quarter, BPM: 120}},
+			{Tempo: score.Tempo{Beat: note.Half.Dotted(), BPM: 32}},
+		}},
+		Parts: []score.Part{
+			{
+				Name: "Flute",
+				Staves: []score.Staff{{
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(note.C5, note.Quarter.Dotted()).WithDynamic(note.MezzoForte)},
+						[]note.Note{note.NewNote(note.D5, note.Eighth).Articulate(note.Staccato)},
+						[]note.Note{note.NewNote(note.Dsharp5, triplet).Articulate(note.Legato)},
+						[]note.Note{note.NewNote(note.D5, triplet).Articulate(note.Legato)},
+						[]note.Note{note.NewNote(note.C5, triplet).Articulate(note.Legato)},
+					),
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(note.G4, note.Quarter).Crescendo()},
+						[]note.Note{note.NewNote(note.Gsharp4, note.Half).Tied()},
+					),
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(note.Gsharp4, note.Eighth)},
+						[]note.Note{note.NewNote(note.F4, note.Half.Dotted()-note.Eighth)},
+					),
+				}},
+			},
+			{
+				Name: "Piano",
+				Staves: []score.Staff{
+					{
+						score.NewMeasure(waltz, []note.Note{
+							note.NewNote(note.C4, note.Half.Dotted()),
+							note.NewNote(note.Dsharp4, note.Half.Dotted()),
+							note.NewNote(note.G4, note.Half.Dotted()),
+						}),
+						score.NewPolyphonicMeasure(waltz,
+							score.Voice{{note.NewRest(note.Half.Dotted())}},
+							score.Voice{{note.NewRest(note.Half)}, {note.NewNote(note.D4, note.Quarter)}},
+						),
+					},
+					{
+						score.NewMeasure(waltz,
+							[]note.Note{note.NewNote(note.C3, note.Half).Tied()},
+							[]note.Note{note.NewNote(note.C3, note.Quarter).Diminuendo()},
+						),
+						score.NewMeasure(waltz,
+							[]note.Note{note.NewNote(note.G2, note.Half.Dotted())},
+						),
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, `\version "2.20.0"
+
+\header {
+  title = "The \"Marble\""
+  composer = "Traditional"
+}
+
+\score {
+  <<
+    \new Staff \with { instrumentName = "Flute" } {
+      \clef treble \key c \minor \time 3/4 \tempo 2. = 32 c''4.\mf d''8-. \tuplet 3/2 { ees''( d'' c'') } |
+      g'4\< aes'2~ |
+      \tempo 4 = 120 aes'8 f'~ f'2 <>\! |
+    }
+    \new PianoStaff \with { instrumentName = "Piano" } <<
+      \new Staff {
+        \clef treble \key c \minor \time 3/4 <c' ees' g'>2. |
+        << { R2. } \\ { r2 d'4 } >> |
+      }
+      \new Staff {
+        \clef bass \key c \minor \time 3/4 c2~ c4\> |
+        g,2. <>\! |
+      }
+    >>
+  >>
+  \layout { }
+}
+`, lilypond.Format(s))
+}
+
+func TestFormatMeasures(t *testing.T) {
+	fiveFour := score.TimeSignature{Beats: 5, Value: 4}
+	s := score.Score{
+		Parts: []score.Part{{
+			Staves: []score.Staff{{
+				score.NewMeasure(score.CommonTime, []note.Note{note.NewNote(note.G4, note.Eighth)}),
+				score.NewMeasure(score.CommonTime,
+					[]note.Note{note.NewNote(note.C5, note.Whole).Articulate(note.Accent | note.Tenuto)},
+				),
+				score.NewMeasure(fiveFour, []note.Note{note.NewRest(note.Quarter * 5)}),
+				score.NewMeasure(fiveFour,
+					[]note.Note{note.NewNote(note.Asharp4, note.Half.Dotted())},
+					[]note.Note{note.NewRest(note.Half)},
+				),
+			}},
+		}},
+	}
+
+	assert.Equal(t, `\version "2.20.0"
+
+\score {
+  <<
+    \new Staff {
+      \clef treble \key c \major \time 4/4 \partial 8 g'8 |
+      c''1---> |
+      \time 5/4 R4*5 |
+      bes'2. r2 |
+    }
+  >>
+  \layout { }
+}
+`, lilypond.Format(s))
+}
+
+func TestDuration(t *testing.T) {
+	for d, expected := range map[note.Duration]string{
+		note.Quarter:              "4",
+		note.Half.Dots(3):         "2...",
+		note.Eighth.Triplet():     "8*2/3",
+		note.Quarter * 5:          "1*5/4",
+		note.Double:               "1*2",
+		note.Sixteenth / 8:        "128",
+		note.Quarter.Tuplet(5, 4): "4*4/5",
+	} {
+		assert.Equal(t, expected, lilypond.Duration(d), expected)
+	}
+}
+
+func TestPitchName(t *testing.T) {
+	assert.Equal(t, "bes'", lilypond.PitchName(note.NewSpelledPitch(note.B, note.Flat, 4)))
+	assert.Equal(t, "fisis,,", lilypond.PitchName(note.NewSpelledPitch(note.F, note.DoubleSharp, 1)))
+	assert.Equal(t, "c", lilypond.PitchName(note.NewSpelledPitch(note.C, note.Natural, 3)))
+	assert.Equal(t, "-.->", lilypond.Articulations(note.Staccato|note.Accent|note.Legato))
+}
@@ -0,0 +1,661 @@
+package musicxml
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// FormatError is returned for documents that are not valid MusicXML, with
+// the part and measure of the problem
+type FormatError struct {
+	// Part is the part id and Measure the measure number. Both are empty for
+	// errors outside of the parts
+	Part    string
+	Measure string
+	Msg     string
+}
+
+// Error returns the position and description of the error
+func (e *FormatError) Error() string {
+	if e.Part == "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("part %s, measure %s: %s", e.Part, e.Measure, e.Msg)
+}
+
+// zipSignature is the start of compressed MusicXML files
+var zipSignature = []byte("PK\x03\x04")
+
+// Parse reads a partwise MusicXML document, uncompressed or compressed
+// (.mxl), and converts it to a score.
+//
+// It supports the title, composer and arranger; the part names, instruments
+// and MIDI programs; several staves per part; measures with divisions, the
+// key and time signatures; voices, chords, rests, ties, tuplets, dots and
+// slurs; staccato, accent, tenuto and strong accent articulations; and
+// dynamics, hairpins and tempo directions. The key of the score is the
+// first key signature of the first part, later key changes are ignored as
+// the pitches are spelled. Grace and cue notes are skipped.
+//
+// Pitches are note.SpelledPitch, and the voices of each staff are numbered
+// in the order they appear. Gaps in the voices are filled with rests
+func Parse(r io.Reader) (score.Score, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return score.Score{}, err
+	}
+
+	if bytes.HasPrefix(data, zipSignature) {
+		if data, err = uncompress(data); err != nil {
+			return score.Score{}, err
+		}
+	}
+
+	var doc xmlScore
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return score.Score{}, err
+	}
+	switch doc.XMLName.Local {
+	case "score-partwise":
+	case "score-timewise":
+		return score.Score{}, &FormatError{Msg: "timewise scores are not supported"}
+	default:
+		return score.Score{}, &FormatError{Msg: fmt.Sprintf("root element is %s, expected score-partwise", doc.XMLName.Local)}
+	}
+
+	return convert(doc)
+}
+
+// container is the META-INF/container.xml file of compressed files, with
+// the path of the score
+type container struct {
+	Rootfiles []struct {
+		Path string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// containerPath is the path of the container file in compressed files
+const containerPath = "META-INF/container.xml"
+
+// uncompress returns the score of a compressed file
+func uncompress(data []byte) ([]byte, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range z.File {
+		files[f.Name] = f
+	}
+
+	f, ok := files[containerPath]
+	if !ok {
+		return nil, &FormatError{Msg: fmt.Sprintf("compressed file without %s", containerPath)}
+	}
+	b, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var c container
+	if err := xml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	if len(c.Rootfiles) == 0 {
+		return nil, &FormatError{Msg: fmt.Sprintf("%s does not have a rootfile", containerPath)}
+	}
+
+	path := c.Rootfiles[0].Path
+	if f, ok = files[path]; !ok {
+		return nil, &FormatError{Msg: fmt.Sprintf("rootfile %s is missing", path)}
+	}
+	return readZipFile(f)
+}
+
+// readZipFile returns the uncompressed content of a file
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// converter keeps the state to convert a document to a score
+type converter struct {
+	s score.Score
+	// keyed is true after the first key signature
+	keyed bool
+}
+
+// convert returns the score of a partwise document
+func convert(doc xmlScore) (score.Score, error) {
+	c := converter{}
+	if doc.Work != nil {
+		c.s.Title = strings.TrimSpace(doc.Work.Title)
+	}
+	if c.s.Title == "" {
+		c.s.Title = strings.TrimSpace(doc.MovementTitle)
+	}
+	if id := doc.Identification; id != nil {
+		for _, cr := range id.Creators {
+			switch cr.Type {
+			case "composer":
+				c.s.Composer = strings.TrimSpace(cr.Name)
+			case "arranger":
+				c.s.Arranger = strings.TrimSpace(cr.Name)
+			}
+		}
+	}
+
+	infos := map[string]xmlScorePart{}
+	for _, p := range doc.PartList {
+		infos[p.ID] = p
+	}
+
+	for _, p := range doc.Parts {
+		part, err := c.part(p, infos[p.ID])
+		if err != nil {
+			return score.Score{}, err
+		}
+		c.s.Parts = append(c.s.Parts, part)
+	}
+	return c.s, nil
+}
+
+// part converts the measures of a part
+func (c *converter) part(xp xmlPart, info xmlScorePart) (score.Part, error) {
+	p := score.Part{Name: strings.TrimSpace(info.Name)}
+	if info.Instrument != nil {
+		p.Instrument.Name = strings.TrimSpace(info.Instrument.Name)
+	}
+	if info.MIDI != nil && info.MIDI.Program > 0 {
+		p.Instrument.Program = info.MIDI.Program - 1
+	}
+
+	r := partReader{
+		converter: c,
+		id:        xp.ID,
+		time:      score.CommonTime,
+		dynamics:  map[int]note.Dynamic{},
+		hairpins:  map[int]note.Hairpin{},
+		slurs:     map[voiceID]bool{},
+	}
+	for _, m := range xp.Measures {
+		if err := r.measure(m); err != nil {
+			return score.Part{}, err
+		}
+	}
+
+	p.Staves = r.staves
+	return p, nil
+}
+
+// voiceID identifies a voice in a part
+type voiceID struct {
+	staff int
+	voice string
+}
+
+// partReader keeps the state to convert the measures of a part
+type partReader struct {
+	*converter
+	id string
+	// number is the number of the current measure
+	number    string
+	divisions int
+	time      score.TimeSignature
+	staves    []score.Staff
+	// voices are the indices of the voices of each staff, by voice number
+	voices []map[string]int
+	// start is the position of the current measure from the beginning
+	start note.Rational
+	// pos is the position in the current measure
+	pos note.Rational
+	// length is the furthest position reached in the current measure
+	length note.Rational
+	// groups and ends are the notes and end positions of the voices in the
+	// current measure
+	groups map[voiceID]score.Voice
+	ends   map[voiceID]note.Rational
+	// dynamics and hairpins are set by directions for the next note of each
+	// staff
+	dynamics map[int]note.Dynamic
+	hairpins map[int]note.Hairpin
+	// slurs are the voices inside a slur
+	slurs map[voiceID]bool
+}
+
+// errorf returns a FormatError in the current measure
+func (p *partReader) errorf(format string, a ...interface{}) error {
+	return &FormatError{Part: p.id, Measure: p.number, Msg: fmt.Sprintf(format, a...)}
+}
+
+// rational returns a duration in divisions as a fraction of the whole note
+func (p *partReader) rational(divisions int) note.Rational {
+	if p.divisions == 0 {
+		return note.Rational{}
+	}
+	return note.NewRational(int64(divisions), int64(4*p.divisions))
+}
+
+// measure converts a measure, adding it to all the staves
+func (p *partReader) measure(m xmlMeasure) error {
+	p.number = m.Number
+	p.pos, p.length = note.Rational{}, note.Rational{}
+	p.groups = map[voiceID]score.Voice{}
+	p.ends = map[voiceID]note.Rational{}
+
+	for _, e := range m.Elements {
+		var err error
+		switch e := e.(type) {
+		case *xmlNote:
+			err = p.note(e)
+		case *xmlMove:
+			d := p.rational(e.Duration)
+			if e.Backup {
+				d = note.Rational{}.Sub(d)
+			}
+			p.move(p.pos.Add(d))
+		case *xmlAttributes:
+			err = p.attributes(e)
+		case *xmlDirection:
+			err = p.direction(e)
+		case *xmlSound:
+			err = p.tempo(p.start.Add(p.pos), nil, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	p.endMeasure()
+	return nil
+}
+
+// move sets the position in the measure
+func (p *partReader) move(pos note.Rational) {
+	p.pos = pos
+	if p.pos.Cmp(p.length) > 0 {
+		p.length = p.pos
+	}
+}
+
+// addStaves adds staves up to n, with empty measures
+func (p *partReader) addStaves(n int) {
+	for len(p.staves) < n {
+		var staff score.Staff
+		if len(p.staves) > 0 {
+			for _, m := range p.staves[0] {
+				staff = append(staff, score.Measure{Time: m.Time})
+			}
+		}
+		p.staves = append(p.staves, staff)
+		p.voices = append(p.voices, map[string]int{})
+	}
+}
+
+// endMeasure adds the voices of the current measure to the staves, filling
+// the gaps with rests
+func (p *partReader) endMeasure() {
+	p.addStaves(1)
+
+	length := p.length
+	if length == (note.Rational{}) {
+		length = p.time.Duration()
+	}
+
+	measures := make([]score.Measure, len(p.staves))
+	for id, v := range p.groups {
+		if end := p.ends[id]; end.Cmp(length) < 0 {
+			v = append(v, []note.Note{note.NewRest(length.Sub(end).Duration())})
+		}
+
+		m := &measures[id.staff-1]
+		i := p.voices[id.staff-1][id.voice]
+		for len(m.Voices) <= i {
+			m.Voices = append(m.Voices, nil)
+		}
+		m.Voices[i] = v
+	}
+
+	for i, m := range measures {
+		if len(m.Voices) == 0 {
+			m.Voices = []score.Voice{nil}
+		}
+		for j, v := range m.Voices {
+			if v == nil {
+				m.Voices[j] = score.Voice{{note.NewRest(length.Duration())}}
+			}
+		}
+		m.Time = p.time
+		p.staves[i] = append(p.staves[i], m)
+	}
+
+	p.start = p.start.Add(length)
+}
+
+// attributes sets the divisions, key, time signature and number of staves
+func (p *partReader) attributes(a *xmlAttributes) error {
+	if a.Divisions < 0 {
+		return p.errorf("wrong divisions %d", a.Divisions)
+	}
+	if a.Divisions > 0 {
+		p.divisions = a.Divisions
+	}
+
+	if len(a.Keys) > 0 && !p.keyed {
+		p.s.Key = key.FromSignature(note.KeySignature(a.Keys[0].Fifths), modes[a.Keys[0].Mode])
+		p.keyed = true
+	}
+
+	if len(a.Times) > 0 && a.Times[0].Beats != "" {
+		t := a.Times[0]
+		beats := 0
+		for _, b := range strings.Split(t.Beats, "+") {
+			n, err := strconv.Atoi(strings.TrimSpace(b))
+			if err != nil || n <= 0 {
+				return p.errorf("wrong time signature %s/%d", t.Beats, t.BeatType)
+			}
+			beats += n
+		}
+		if t.BeatType <= 0 {
+			return p.errorf("wrong time signature %s/%d", t.Beats, t.BeatType)
+		}
+		p.time = score.TimeSignature{Beats: beats, Value: t.BeatType}
+	}
+
+	p.addStaves(a.Staves)
+	return nil
+}
+
+// modes are the key modes, major if it is not set
+var modes = map[string]key.Mode{
+	"":           key.Major,
+	"major":      key.Major,
+	"minor":      key.Minor,
+	"ionian":     key.Ionian,
+	"dorian":     key.Dorian,
+	"phrygian":   key.Phrygian,
+	"lydian":     key.Lydian,
+	"mixolydian": key.Mixolydian,
+	"aeolian":    key.Aeolian,
+	"locrian":    key.Locrian,
+}
+
+// direction sets the dynamic or hairpin of the next note of the staff, or
+// changes the tempo
+func (p *partReader) direction(d *xmlDirection) error {
+	staff := d.Staff
+	if staff < 1 {
+		staff = 1
+	}
+
+	var metronome *xmlMetronome
+	for _, t := range d.Types {
+		for _, marks := range t.Dynamics {
+			if dyn := dynamic(marks); dyn != note.NoDynamic {
+				p.dynamics[staff] = dyn
+			}
+		}
+		if t.Wedge != nil {
+			switch t.Wedge.Type {
+			case "crescendo":
+				p.hairpins[staff] = note.Crescendo
+			case "diminuendo":
+				p.hairpins[staff] = note.Diminuendo
+			}
+		}
+		if t.Metronome != nil {
+			metronome = t.Metronome
+		}
+	}
+
+	if metronome != nil || d.Sound != nil {
+		pos := p.start.Add(p.pos).Add(p.rational(d.Offset))
+		return p.tempo(pos, metronome, d.Sound)
+	}
+	return nil
+}
+
+// dynamic returns the first dynamic marking, or NoDynamic. Marks like sfz
+// are ignored
+func dynamic(marks xmlMarks) note.Dynamic {
+	for _, name := range marks.names() {
+		if d, err := note.ParseDynamic(name); err == nil {
+			return d
+		}
+	}
+	return note.NoDynamic
+}
+
+// tempo adds a tempo change from a metronome mark, or from the playback
+// tempo in quarters per minute. Marks that are not numbers, e.g. "c. 120",
+// are ignored, and tempos of 0 or less are an error. Changes in the same
+// position as a previous one, e.g. the same marking in another part, are
+// ignored
+func (p *partReader) tempo(pos note.Rational, m *xmlMetronome, s *xmlSound) error {
+	var t score.Tempo
+	if m != nil {
+		unit, ok := noteTypes[m.BeatUnit]
+		bpm, err := strconv.ParseFloat(strings.TrimSpace(m.PerMinute), 64)
+		if ok && err == nil {
+			t = score.Tempo{Beat: unit.Dots(len(m.Dots)), BPM: int(math.Round(bpm))}
+			if t.BPM <= 0 {
+				return p.errorf("wrong tempo %q", m.PerMinute)
+			}
+		}
+	}
+	if t.BPM == 0 && s != nil {
+		if bpm, err := strconv.ParseFloat(strings.TrimSpace(s.Tempo), 64); err == nil {
+			t = score.Tempo{Beat: note.Quarter, BPM: int(math.Round(bpm))}
+			if t.BPM <= 0 {
+				return p.errorf("wrong tempo %q", s.Tempo)
+			}
+		}
+	}
+	if t.BPM == 0 {
+		return nil
+	}
+
+	for _, ch := range p.s.Tempo.Changes {
+		if ch.Position == pos {
+			return nil
+		}
+	}
+	p.s.Tempo.Changes = append(p.s.Tempo.Changes, score.TempoChange{Position: pos, Tempo: t})
+	return nil
+}
+
+// noteTypes are the written durations of the note types
+var noteTypes = map[string]note.Duration{
+	"maxima":  8,
+	"long":    4,
+	"breve":   note.Double,
+	"whole":   note.Whole,
+	"half":    note.Half,
+	"quarter": note.Quarter,
+	"eighth":  note.Eighth,
+	"16th":    note.Sixteenth,
+	"32nd":    note.Sixteenth / 2,
+	"64th":    note.Sixteenth / 4,
+	"128th":   note.Sixteenth / 8,
+	"256th":   note.Sixteenth / 16,
+}
+
+// articulations are the MusicXML articulations with an equivalent
+var articulations = map[string]note.Articulation{
+	"staccato":      note.Staccato,
+	"staccatissimo": note.Staccato,
+	"accent":        note.Accent,
+	"tenuto":        note.Tenuto,
+	"strong-accent": note.Marcato,
+}
+
+// letters are the pitch steps
+var letters = map[string]note.Letter{
+	"C": note.C, "D": note.D, "E": note.E, "F": note.F, "G": note.G, "A": note.A, "B": note.B,
+}
+
+// note adds a note to its voice, or to the previous group for chords
+func (p *partReader) note(xn *xmlNote) error {
+	if xn.Grace != nil || xn.Cue != nil {
+		return nil
+	}
+	if p.divisions == 0 {
+		return p.errorf("note before the divisions are set")
+	}
+
+	id := voiceID{staff: xn.Staff, voice: strings.TrimSpace(xn.Voice)}
+	if id.staff < 1 {
+		id.staff = 1
+	}
+	if id.voice == "" {
+		id.voice = "1"
+	}
+	p.addStaves(id.staff)
+	if _, ok := p.voices[id.staff-1][id.voice]; !ok {
+		p.voices[id.staff-1][id.voice] = len(p.voices[id.staff-1])
+	}
+
+	n, err := p.convertNote(xn, id)
+	if err != nil {
+		return err
+	}
+
+	v := p.groups[id]
+	if xn.Chord != nil {
+		if len(v) == 0 {
+			return p.errorf("chord note without a previous note in voice %s", id.voice)
+		}
+		v[len(v)-1] = append(v[len(v)-1], n)
+		return nil
+	}
+
+	if n.Key() >= 0 {
+		if d, ok := p.dynamics[id.staff]; ok && n.Dynamic == note.NoDynamic {
+			n.Dynamic = d
+		}
+		if h, ok := p.hairpins[id.staff]; ok {
+			n.Hairpin = h
+		}
+		delete(p.dynamics, id.staff)
+		delete(p.hairpins, id.staff)
+	}
+
+	// rests for the gaps left by forward or backup
+	if end := p.ends[id]; end.Cmp(p.pos) < 0 {
+		v = append(v, []note.Note{note.NewRest(p.pos.Sub(end).Duration())})
+	}
+
+	p.groups[id] = append(v, []note.Note{n})
+	p.move(p.pos.Add(p.rational(xn.Duration)))
+	p.ends[id] = p.pos
+	return nil
+}
+
+// convertNote returns the pitch, written duration, tie, articulations and
+// dynamic of a note
+func (p *partReader) convertNote(xn *xmlNote, id voiceID) (note.Note, error) {
+	d, err := p.duration(xn)
+	if err != nil {
+		return note.Note{}, err
+	}
+
+	var pitch *xmlPitch
+	switch {
+	case xn.Rest != nil:
+		return note.NewRest(d), nil
+	case xn.Pitch != nil:
+		pitch = xn.Pitch
+	case xn.Unpitched != nil:
+		// unpitched notes without a position are on the middle line
+		pitch = &xmlPitch{Step: "B", Octave: 4}
+		if xn.Unpitched.DisplayStep != "" {
+			pitch = &xmlPitch{Step: xn.Unpitched.DisplayStep, Octave: xn.Unpitched.DisplayOctave}
+		}
+	default:
+		return note.NewRest(d), nil
+	}
+
+	l, ok := letters[strings.TrimSpace(pitch.Step)]
+	if !ok {
+		return note.Note{}, p.errorf("wrong pitch step %q", pitch.Step)
+	}
+	n := note.NewNote(note.NewSpelledPitch(l, note.Accidental(math.Round(pitch.Alter)), pitch.Octave), d)
+
+	for _, t := range xn.Ties {
+		n.Tie = n.Tie || t.Type == "start"
+	}
+
+	slurEnd := false
+	for _, nt := range xn.Notations {
+		for _, t := range nt.Tied {
+			n.Tie = n.Tie || t.Type == "start"
+		}
+		for _, s := range nt.Slurs {
+			switch s.Type {
+			case "start":
+				p.slurs[id] = true
+			case "stop":
+				slurEnd = true
+			}
+		}
+		for _, marks := range nt.Articulations {
+			for _, name := range marks.names() {
+				n.Articulation |= articulations[name]
+			}
+		}
+		for _, marks := range nt.Dynamics {
+			if dyn := dynamic(marks); dyn != note.NoDynamic {
+				n.Dynamic = dyn
+			}
+		}
+	}
+
+	if p.slurs[id] || slurEnd {
+		n.Articulation |= note.Legato
+	}
+	if slurEnd {
+		delete(p.slurs, id)
+	}
+	return n, nil
+}
+
+// duration returns the written duration of a note from its type, dots and
+// tuplet. Measure rests and notes without a type use their duration
+func (p *partReader) duration(xn *xmlNote) (note.Duration, error) {
+	if xn.Duration < 0 {
+		return 0, p.errorf("wrong duration %d", xn.Duration)
+	}
+	if xn.Type == "" || (xn.Rest != nil && xn.Rest.Measure == "yes") {
+		return p.rational(xn.Duration).Duration(), nil
+	}
+
+	d, ok := noteTypes[strings.TrimSpace(xn.Type)]
+	if !ok {
+		return 0, p.errorf("unknown note type %q", xn.Type)
+	}
+	d = d.Dots(len(xn.Dots))
+
+	if t := xn.Tuplet; t != nil {
+		if t.Actual <= 0 || t.Normal <= 0 {
+			return 0, p.errorf("wrong time modification %d:%d", t.Actual, t.Normal)
+		}
+		d = d.Tuplet(t.Actual, t.Normal)
+	}
+	return d, nil
+}
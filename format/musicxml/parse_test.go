@@ -0,0 +1,326 @@
+package musicxml_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/musicxml"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// document returns a MusicXML document with a part P1 and the measures
+func document(measures string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 3.1 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">
+<score-partwise version="3.1">
+  <part-list><score-part id="P1"><part-name>Music</part-name></score-part></part-list>
+  <part id="P1">` + measures + `</part>
+</score-partwise>`
+}
+
+// pitch returns the parsed pitch, or fails the test
+func pitch(t *testing.T, s string) note.SpelledPitch {
+	p, err := note.ParseSpelledPitch(s)
+	require.NoError(t, err)
+	return p
+}
+
+const marble = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 3.1 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">
+<score-partwise version="3.1">
+  <work><work-title>Marble</work-title></work>
+  <identification>
+    <creator type="composer">Traditional</creator>
+    <creator type="arranger">Carlos</creator>
+  </identification>
+  <part-list>
+    <score-part id="P1">
+      <part-name>Flute</part-name>
+      <score-instrument id="P1-I1"><instrument-name>Concert Flute</instrument-name></score-instrument>
+      <midi-instrument id="P1-I1"><midi-channel>1</midi-channel><midi-program>74</midi-program></midi-instrument>
+    </score-part>
+    <score-part id="P2"><part-name>Piano</part-name></score-part>
+  </part-list>
+  <part id="P1">
+    <measure number="1">
+      <attributes>
+        <divisions>6</divisions>
+        <key><fifths>-3</fifths><mode>minor</mode></key>
+        <time><beats>3</beats><beat-type>4</beat-type></time>
+        <clef><sign>G</sign><line>2</line></clef>
+      </attributes>
+      <direction placement="above">
+        <direction-type><metronome><beat-unit>quarter</beat-unit><per-minute>96</per-minute></metronome></direction-type>
+        <sound tempo="96"/>
+      </direction>
+      <direction><direction-type><dynamics><mf/></dynamics></direction-type></direction>
+      <note><pitch><step>C</step><octave>5</octave></pitch><duration>9</duration><voice>1</voice><type>quarter</type><dot/></note>
+      <note>
+        <pitch><step>D</step><octave>5</octave></pitch><duration>3</duration><voice>1</voice><type>eighth</type>
+        <notations><articulations><staccato/></articulations></notations>
+      </note>
+      <note>
+        <pitch><step>E</step><alter>-1</alter><octave>5</octave></pitch><duration>2</duration><voice>1</voice><type>eighth</type>
+        <time-modification><actual-notes>3</actual-notes><normal-notes>2</normal-notes></time-modification>
+        <notations><tuplet type="start"/><slur type="start"/></notations>
+      </note>
+      <note>
+        <pitch><step>D</step><octave>5</octave></pitch><duration>2</duration><voice>1</voice><type>eighth</type>
+        <time-modification><actual-notes>3</actual-notes><normal-notes>2</normal-notes></time-modification>
+      </note>
+      <note>
+        <pitch><step>C</step><octave>5</octave></pitch><duration>2</duration><voice>1</voice><type>eighth</type>
+        <time-modification><actual-notes>3</actual-notes><normal-notes>2</normal-notes></time-modification>
+        <notations><tuplet type="stop"/><slur type="stop"/></notations>
+      </note>
+    </measure>
+    <measure number="2">
+      <direction><direction-type><wedge type="crescendo"/></direction-type></direction>
+      <note>
+        <pitch><step>G</step><octave>4</octave></pitch><duration>18</duration><tie type="start"/><voice>1</voice><type>half</type><dot/>
+        <notations><tied type="start"/></notations>
+      </note>
+      <note><grace/><pitch><step>B</step><octave>4</octave></pitch><voice>1</voice><type>eighth</type></note>
+    </measure>
+    <measure number="3">
+      <note>
+        <pitch><step>G</step><octave>4</octave></pitch><duration>6</duration><tie type="stop"/><voice>1</voice><type>quarter</type>
+        <notations><tied type="stop"/></notations>
+      </note>
+      <direction>
+        <direction-type><wedge type="stop"/></direction-type>
+        <direction-type><dynamics><f/></dynamics></direction-type>
+        <sound tempo="120"/>
+      </direction>
+      <note><pitch><step>A</step><alter>-1</alter><octave>4</octave></pitch><duration>12</duration><voice>1</voice><type>half</type></note>
+    </measure>
+  </part>
+  <part id="P2">
+    <measure number="1">
+      <attributes>
+        <divisions>6</divisions>
+        <key><fifths>-3</fifths><mode>minor</mode></key>
+        <time><beats>3</beats><beat-type>4</beat-type></time>
+        <staves>2</staves>
+      </attributes>
+      <direction><direction-type><metronome><beat-unit>quarter</beat-unit><per-minute>96</per-minute></metronome></direction-type></direction>
+      <note><pitch><step>C</step><octave>4</octave></pitch><duration>18</duration><voice>1</voice><type>half</type><dot/><staff>1</staff></note>
+      <note><chord/><pitch><step>E</step><alter>-1</alter><octave>4</octave></pitch><duration>18</duration><voice>1</voice><type>half</type><dot/><staff>1</staff></note>
+      <note><chord/><pitch><step>G</step><octave>4</octave></pitch><duration>18</duration><voice>1</voice><type>half</type><dot/><staff>1</staff></note>
+      <backup><duration>18</duration></backup>
+      <note><pitch><step>C</step><octave>3</octave></pitch><duration>12</duration><voice>5</voice><type>half</type><staff>2</staff></note>
+      <forward><duration>6</duration><voice>5</voice><staff>2</staff></forward>
+    </measure>
+    <measure number="2">
+      <note><rest measure="yes"/><duration>18</duration><voice>1</voice><staff>1</staff></note>
+      <backup><duration>18</duration></backup>
+      <forward><duration>12</duration></forward>
+      <note><pitch><step>D</step><octave>4</octave></pitch><duration>6</duration><voice>2</voice><type>quarter</type><staff>1</staff></note>
+      <backup><duration>18</duration></backup>
+      <note><pitch><step>C</step><octave>3</octave></pitch><duration>6</duration><voice>5</voice><type>quarter</type><staff>2</staff></note>
+      <forward><duration>6</duration></forward>
+      <note><pitch><step>G</step><octave>2</octave></pitch><duration>6</duration><voice>5</voice><type>quarter</type><staff>2</staff></note>
+    </measure>
+    <measure number="3">
+      <note><rest/><duration>18</duration><voice>1</voice><staff>1</staff></note>
+    </measure>
+  </part>
+</score-partwise>`
+
+func TestParse(t *testing.T) {
+	s, err := musicxml.Parse(strings.NewReader(marble))
+	require.NoError(t, err)
+
+	cm, err := key.Parse("Cm")
+	require.NoError(t, err)
+	waltz := score.TimeSignature{Beats: 3, Value: 4}
+	dottedHalfRest := []note.Note{note.NewRest(note.Half.Dotted())}
+	triplet := note.Eighth.Triplet()
+
+	expected := score.Score{
+		Title:    "Marble",
+		Composer: "Traditional",
+		Arranger: "Carlos",
+		Key:      cm,
+		Tempo: score.TempoMap{Changes: []score.TempoChange{
+			{Tempo: score.Tempo{Beat: note.Quarter, BPM: 96}},
+			{Position: note.NewRational(7, 4), Tempo: score.Tempo{Beat: note.Quarter, BPM: 120}},
+		}},
+		Parts: []score.Part{
+			{
+				Name:       "Flute",
+				Instrument: score.Instrument{Name: "Concert Flute", Program: 73},
+				Staves: []score.Staff{{
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(pitch(t, "C5"), note.Quarter.Dotted()).WithDynamic(note.MezzoForte)},
+						[]note.Note{note.NewNote(pitch(t, "D5"), note.Eighth).Articulate(note.Staccato)},
+						[]note.Note{note.NewNote(pitch(t, "Eb5"), triplet).Articulate(note.Legato)},
+						[]note.Note{note.NewNote(pitch(t, "D5"), triplet).Articulate(note.Legato)},
+						[]note.Note{note.NewNote(pitch(t, "C5"), triplet).Articulate(note.Legato)},
+					),
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(pitch(t, "G4"), note.Half.Dotted()).Tied().Crescendo()},
+					),
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(pitch(t, "G4"), note.Quarter)},
+						[]note.Note{note.NewNote(pitch(t, "Ab4"), note.Half).WithDynamic(note.Forte)},
+					),
+				}},
+			},
+			{
+				Name: "Piano",
+				Staves: []score.Staff{
+					{
+						score.NewMeasure(waltz, []note.Note{
+							note.NewNote(pitch(t, "C4"), note.Half.Dotted()),
+							note.NewNote(pitch(t, "Eb4"), note.Half.Dotted()),
+							note.NewNote(pitch(t, "G4"), note.Half.Dotted()),
+						}),
+						score.NewPolyphonicMeasure(waltz,
+							score.Voice{dottedHalfRest},
+							score.Voice{
+								{note.NewRest(note.Half)},
+								{note.NewNote(pitch(t, "D4"), note.Quarter)},
+							},
+						),
+						score.NewMeasure(waltz, dottedHalfRest),
+					},
+					{
+						score.NewMeasure(waltz,
+							[]note.Note{note.NewNote(pitch(t, "C3"), note.Half)},
+							[]note.Note{note.NewRest(note.Quarter)},
+						),
+						score.NewMeasure(waltz,
+							[]note.Note{note.NewNote(pitch(t, "C3"), note.Quarter)},
+							[]note.Note{note.NewRest(note.Quarter)},
+							[]note.Note{note.NewNote(pitch(t, "G2"), note.Quarter)},
+						),
+						score.NewMeasure(waltz, dottedHalfRest),
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, s)
+	assert.NoError(t, s.Validate())
+}
+
+func TestParseMeasures(t *testing.T) {
+	s, err := musicxml.Parse(strings.NewReader(document(`
+    <measure number="0" implicit="yes">
+      <attributes><divisions>2</divisions><time><beats>3+2</beats><beat-type>8</beat-type></time></attributes>
+      <note><pitch><step>F</step><alter>1</alter><octave>4</octave></pitch><duration>1</duration><type>eighth</type></note>
+    </measure>
+    <measure number="1">
+      <note><pitch><step>G</step><octave>4</octave></pitch><duration>5</duration><voice>1</voice><type>half</type><time-modification><actual-notes>4</actual-notes><normal-notes>5</normal-notes></time-modification></note>
+    </measure>
+    <measure number="2">
+      <attributes><time><beats>2</beats><beat-type>2</beat-type></time></attributes>
+      <note><unpitched><display-step>E</display-step><display-octave>4</display-octave></unpitched><duration>8</duration><type>whole</type></note>
+    </measure>
+    <measure number="3"/>`)))
+	require.NoError(t, err)
+
+	fiveEight := score.TimeSignature{Beats: 5, Value: 8}
+	cut := score.TimeSignature{Beats: 2, Value: 2}
+	assert.Equal(t, score.Score{
+		Parts: []score.Part{{
+			Name: "Music",
+			Staves: []score.Staff{{
+				score.NewMeasure(fiveEight, []note.Note{note.NewNote(pitch(t, "F#4"), note.Eighth)}),
+				score.NewMeasure(fiveEight, []note.Note{note.NewNote(pitch(t, "G4"), note.Half.Tuplet(4, 5))}),
+				score.NewMeasure(cut, []note.Note{note.NewNote(pitch(t, "E4"), note.Whole)}),
+				score.NewMeasure(cut, []note.Note{note.NewRest(note.Whole)}),
+			}},
+		}},
+	}, s)
+}
+
+func TestParseCompressed(t *testing.T) {
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for name, content := range map[string]string{
+		"mimetype":               "application/vnd.recordare.musicxml",
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="marble.xml" media-type="application/vnd.recordare.musicxml+xml"/></rootfiles></container>`,
+		"marble.xml":             marble,
+	} {
+		w, err := z.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, z.Close())
+
+	s, err := musicxml.Parse(&b)
+	require.NoError(t, err)
+
+	expected, err := musicxml.Parse(strings.NewReader(marble))
+	require.NoError(t, err)
+	assert.Equal(t, expected, s)
+}
+
+func TestParseErrors(t *testing.T) {
+	// compressed returns a compressed file with the given files
+	compressed := func(files ...string) string {
+		var b bytes.Buffer
+		z := zip.NewWriter(&b)
+		for i := 0; i < len(files); i += 2 {
+			w, err := z.Create(files[i])
+			require.NoError(t, err)
+			w.Write([]byte(files[i+1]))
+		}
+		require.NoError(t, z.Close())
+		return b.String()
+	}
+
+	quarter := `<note><pitch><step>C</step><octave>4</octave></pitch><duration>1</duration><type>quarter</type></note>`
+	for _, test := range []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"timewise", `<score-timewise/>`, "timewise scores are not supported"},
+		{"root", `<opus/>`, "root element is opus, expected score-partwise"},
+		{"divisions", document(`<measure number="1">` + quarter + `</measure>`),
+			"part P1, measure 1: note before the divisions are set"},
+		{"note type", document(`<measure number="1"><attributes><divisions>1</divisions></attributes>` +
+			`<note><rest/><duration>1</duration><type>crotchet</type></note></measure>`),
+			`part P1, measure 1: unknown note type "crotchet"`},
+		{"step", document(`<measure number="1"><attributes><divisions>1</divisions></attributes>` +
+			`<note><pitch><step>H</step><octave>4</octave></pitch><duration>1</duration><type>quarter</type></note></measure>`),
+			`part P1, measure 1: wrong pitch step "H"`},
+		{"time signature", document(`<measure number="2"><attributes><time><beats>3</beats><beat-type>0</beat-type></time></attributes></measure>`),
+			"part P1, measure 2: wrong time signature 3/0"},
+		{"time modification", document(`<measure number="1"><attributes><divisions>3</divisions></attributes>` +
+			`<note><rest/><duration>1</duration><type>eighth</type><time-modification><actual-notes>0</actual-notes></time-modification></note></measure>`),
+			"part P1, measure 1: wrong time modification 0:0"},
+		{"chord", document(`<measure number="1"><attributes><divisions>1</divisions></attributes>` +
+			`<note><chord/><pitch><step>C</step><octave>4</octave></pitch><duration>1</duration><type>quarter</type></note></measure>`),
+			"part P1, measure 1: chord note without a previous note in voice 1"},
+		{"tempo", document(`<measure number="1"><direction><direction-type><metronome><beat-unit>quarter</beat-unit>` +
+			`<per-minute>0</per-minute></metronome></direction-type></direction></measure>`),
+			`part P1, measure 1: wrong tempo "0"`},
+		{"playback tempo", document(`<measure number="3"><sound tempo="-60"/></measure>`),
+			`part P1, measure 3: wrong tempo "-60"`},
+		{"container", compressed("score.xml", document("")), "compressed file without META-INF/container.xml"},
+		{"rootfile", compressed("META-INF/container.xml", `<container><rootfiles><rootfile full-path="a.xml"/></rootfiles></container>`),
+			"rootfile a.xml is missing"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := musicxml.Parse(strings.NewReader(test.data))
+			require.Error(t, err)
+			assert.IsType(t, &musicxml.FormatError{}, err)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+
+	_, err := musicxml.Parse(strings.NewReader(`<score-partwise><part id="P1">`))
+	assert.Error(t, err)
+}
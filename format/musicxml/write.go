@@ -0,0 +1,578 @@
+package musicxml
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// doctype is the document type declaration of partwise documents
+const doctype = `<!DOCTYPE score-partwise PUBLIC "-//Recordare//DTD MusicXML 3.1 Partwise//EN" "http://www.musicxml.org/dtds/partwise.dtd">`
+
+// Write writes the score as an uncompressed partwise MusicXML document.
+//
+// The notes of each measure are split into tied notes that show the beats,
+// see score.TimeSignature.Notate, and beamed by beat. Each staff has the
+// clef that fits its notes, see score.Staff.Clef. Pitches are spelled for
+// the key of the score, unless they are note.SpelledPitch. The tempo
+// changes are written in the first part
+func Write(w io.Writer, s score.Score) error {
+	doc := xmlScore{XMLName: xml.Name{Local: "score-partwise"}, Version: "3.1"}
+	if s.Title != "" {
+		doc.Work = &xmlWork{Title: s.Title}
+	}
+	var creators []xmlCreator
+	if s.Composer != "" {
+		creators = append(creators, xmlCreator{Type: "composer", Name: s.Composer})
+	}
+	if s.Arranger != "" {
+		creators = append(creators, xmlCreator{Type: "arranger", Name: s.Arranger})
+	}
+	if len(creators) > 0 {
+		doc.Identification = &xmlIdentification{Creators: creators}
+	}
+
+	for i, p := range s.Parts {
+		id := "P" + strconv.Itoa(i+1)
+		doc.PartList = append(doc.PartList, scorePart(id, p))
+		doc.Parts = append(doc.Parts, newPartWriter(s, p, i == 0).part(id))
+	}
+
+	if _, err := io.WriteString(w, xml.Header+doctype+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// scorePart returns the part list entry of a part, with its instrument and
+// MIDI program
+func scorePart(id string, p score.Part) xmlScorePart {
+	info := xmlScorePart{
+		ID:   id,
+		Name: p.Name,
+		MIDI: &xmlMIDIInstrument{ID: id + "-I1", Program: p.Instrument.Program + 1},
+	}
+	if p.Instrument.Name != "" {
+		info.Instrument = &xmlScoreInstrument{ID: id + "-I1", Name: p.Instrument.Name}
+	}
+	return info
+}
+
+// voiceState is what a voice carries over from one group to the next
+type voiceState struct {
+	// tied are the keys of the notes tied to the next group
+	tied map[int]bool
+	// slur is true inside a slur
+	slur bool
+	// tuplet is the duration of the current tuplet so far, 0 outside of
+	// tuplets
+	tuplet note.Rational
+}
+
+// partWriter keeps the state to convert the staves of a part to measures
+type partWriter struct {
+	s      score.Score
+	staves []score.Staff
+	// tempo is true if the tempo changes are written in this part
+	tempo bool
+	// notated are the voices of each staff and measure, see
+	// score.TimeSignature.Notate
+	notated   [][][]score.Voice
+	divisions int
+	states    map[[2]int]*voiceState
+	// wedges are the staves with an unfinished hairpin
+	wedges map[int]bool
+}
+
+// newPartWriter returns a writer with the notated voices and the divisions
+// that fit all their durations
+func newPartWriter(s score.Score, p score.Part, tempo bool) *partWriter {
+	w := &partWriter{
+		s:         s,
+		staves:    p.Staves,
+		tempo:     tempo,
+		notated:   make([][][]score.Voice, len(p.Staves)),
+		divisions: 1,
+		states:    map[[2]int]*voiceState{},
+		wedges:    map[int]bool{},
+	}
+
+	fit := func(r note.Rational) {
+		den := r.Mul(note.NewRational(4, 1)).Den()
+		w.divisions = lcm(w.divisions, int(den))
+	}
+	for i, staff := range p.Staves {
+		w.notated[i] = make([][]score.Voice, len(staff))
+		for j, m := range staff {
+			for _, v := range m.Voices {
+				v = m.Time.Notate(v)
+				w.notated[i][j] = append(w.notated[i][j], v)
+				for _, group := range v {
+					fit(group[0].Duration.Rational())
+				}
+			}
+			fit(m.Time.Duration())
+		}
+	}
+	if tempo {
+		for _, ch := range s.Tempo.Changes {
+			fit(ch.Position)
+		}
+	}
+	return w
+}
+
+// lcm returns the least common multiple
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
+
+// ticks returns a duration in divisions
+func (w *partWriter) ticks(r note.Rational) int {
+	t := r.Mul(note.NewRational(int64(4*w.divisions), 1))
+	return int(t.Num() / t.Den())
+}
+
+// part returns the measures of the part
+func (w *partWriter) part(id string) xmlPart {
+	xp := xmlPart{ID: id}
+
+	measures := 0
+	for _, staff := range w.staves {
+		if len(staff) > measures {
+			measures = len(staff)
+		}
+	}
+
+	var start note.Rational
+	var prev score.TimeSignature
+	for i := 0; i < measures; i++ {
+		time, length := w.measureTime(i)
+		xm := xmlMeasure{Number: strconv.Itoa(i + 1)}
+
+		if i == 0 {
+			xm.Elements = append(xm.Elements, w.attributes(time))
+		} else if time != prev {
+			xm.Elements = append(xm.Elements, &xmlAttributes{Times: []xmlTime{newTime(time)}})
+		}
+		prev = time
+
+		if w.tempo {
+			xm.Elements = append(xm.Elements, w.tempoChanges(start, length, i == measures-1)...)
+		}
+
+		var cursor note.Rational
+		for s := range w.staves {
+			if i >= len(w.notated[s]) {
+				continue
+			}
+			for j := range w.notated[s][i] {
+				if cursor.Cmp(note.Rational{}) > 0 {
+					xm.Elements = append(xm.Elements, &xmlMove{Backup: true, Duration: w.ticks(cursor)})
+				}
+				xm.Elements = append(xm.Elements, w.voice(s, i, j)...)
+				cursor = w.notated[s][i][j].Duration()
+			}
+		}
+
+		if i == measures-1 {
+			for s := range w.staves {
+				if w.wedges[s] {
+					xm.Elements = append(xm.Elements, w.direction(s, xmlDirectionType{Wedge: &xmlWedge{Type: "stop"}}))
+				}
+			}
+		}
+
+		xp.Measures = append(xp.Measures, xm)
+		start = start.Add(length)
+	}
+	return xp
+}
+
+// measureTime returns the time signature of a measure, from the first staff
+// that has it, and its length: the longest voice, or the time signature for
+// empty measures
+func (w *partWriter) measureTime(i int) (score.TimeSignature, note.Rational) {
+	time := score.CommonTime
+	found := false
+	var length note.Rational
+	for _, staff := range w.staves {
+		if i >= len(staff) {
+			continue
+		}
+		if !found {
+			time, found = staff[i].Time, true
+		}
+		if d := staff[i].Duration(); d.Cmp(length) > 0 {
+			length = d
+		}
+	}
+
+	if length.Cmp(note.Rational{}) == 0 {
+		length = time.Duration()
+	}
+	return time, length
+}
+
+// attributes returns the attributes of the first measure, with the clef of
+// each staff
+func (w *partWriter) attributes(time score.TimeSignature) *xmlAttributes {
+	a := &xmlAttributes{
+		Divisions: w.divisions,
+		Keys:      []xmlKey{{Fifths: int(w.s.Key.Signature()), Mode: w.s.Key.Mode.String()}},
+		Times:     []xmlTime{newTime(time)},
+	}
+	if len(w.staves) > 1 {
+		a.Staves = len(w.staves)
+	}
+
+	for i, staff := range w.staves {
+		c := xmlClef{Sign: "G", Line: 2}
+		if staff.Clef() == score.Bass {
+			c = xmlClef{Sign: "F", Line: 4}
+		}
+		if len(w.staves) > 1 {
+			c.Number = i + 1
+		}
+		a.Clefs = append(a.Clefs, c)
+	}
+	return a
+}
+
+// newTime returns a time signature element
+func newTime(t score.TimeSignature) xmlTime {
+	return xmlTime{Beats: strconv.Itoa(t.Beats), BeatType: t.Value}
+}
+
+// tempoChanges returns the directions of the tempo changes in the measure
+// that starts at start. The last measure also has the changes after its end
+func (w *partWriter) tempoChanges(start, length note.Rational, last bool) []interface{} {
+	end := start.Add(length)
+
+	var elements []interface{}
+	for _, ch := range w.s.Tempo.Changes {
+		if ch.Position.Cmp(start) < 0 || (!last && ch.Position.Cmp(end) >= 0) {
+			continue
+		}
+
+		t := ch.Tempo
+		quarters := float64(t.BPM) * float64(t.Beat) / float64(note.Quarter)
+		d := &xmlDirection{
+			Placement: "above",
+			Offset:    w.ticks(ch.Position.Sub(start)),
+			Sound:     &xmlSound{Tempo: strconv.FormatFloat(quarters, 'f', -1, 64)},
+		}
+		if len(w.staves) > 1 {
+			d.Staff = 1
+		}
+		if n, ok := t.Beat.Notation(); ok && n.Tuplet == 0 {
+			if unit, ok := typeNames[n.Value]; ok {
+				d.Types = []xmlDirectionType{{Metronome: &xmlMetronome{
+					BeatUnit:  unit,
+					Dots:      make([]struct{}, n.Dots),
+					PerMinute: strconv.Itoa(t.BPM),
+				}}}
+			}
+		}
+		elements = append(elements, d)
+	}
+	return elements
+}
+
+// typeNames are the note types of the written durations
+var typeNames = func() map[note.Duration]string {
+	names := map[note.Duration]string{}
+	for name, d := range noteTypes {
+		names[d] = name
+	}
+	return names
+}()
+
+// direction returns a direction for a staff
+func (w *partWriter) direction(staff int, t xmlDirectionType) *xmlDirection {
+	d := &xmlDirection{Placement: "below", Types: []xmlDirectionType{t}}
+	if len(w.staves) > 1 {
+		d.Staff = staff + 1
+	}
+	return d
+}
+
+// state returns what voice j of a staff carries over from the previous group
+func (w *partWriter) state(staff, j int) *voiceState {
+	id := [2]int{staff, j}
+	if w.states[id] == nil {
+		w.states[id] = &voiceState{tied: map[int]bool{}}
+	}
+	return w.states[id]
+}
+
+// nextGroup returns the group after group g of voice j in measure m of a
+// staff, or nil if it is the last one
+func (w *partWriter) nextGroup(staff, m, j, g int) []note.Note {
+	if v := w.notated[staff][m][j]; g+1 < len(v) {
+		return v[g+1]
+	}
+	if m+1 < len(w.notated[staff]) && j < len(w.notated[staff][m+1]) {
+		if v := w.notated[staff][m+1][j]; len(v) > 0 {
+			return v[0]
+		}
+	}
+	return nil
+}
+
+// voice returns the notes of voice j in measure m of a staff, with the
+// directions for their dynamics and hairpins. The voices are numbered from 1
+// in the first staff, from 5 in the second one...
+func (w *partWriter) voice(staff, m, j int) []interface{} {
+	v := w.notated[staff][m][j]
+	time := w.staves[staff][m].Time
+	st := w.state(staff, j)
+
+	base := xmlNote{Voice: strconv.Itoa(staff*4 + j + 1)}
+	if len(w.staves) > 1 {
+		base.Staff = staff + 1
+	}
+
+	if len(v) == 1 && score.IsRestGroup(v[0]) && v.Duration() == time.Duration() {
+		xn := base
+		xn.Rest = &xmlRest{Measure: "yes"}
+		xn.Duration = w.ticks(time.Duration())
+		return []interface{}{&xn}
+	}
+
+	beams := beams(v, time)
+
+	var elements []interface{}
+	for g, group := range v {
+		d := group[0].Duration.Rational()
+		n, ok := group[0].Duration.Notation()
+
+		var notes []note.Note
+		for _, nt := range group {
+			if nt.Key() >= 0 {
+				notes = append(notes, nt)
+			}
+		}
+		if len(notes) == 0 {
+			notes = group[:1]
+		} else {
+			elements = append(elements, w.dynamics(staff, group)...)
+		}
+
+		// tuplets end when their notes add up to a note value
+		var tuplets []xmlStartStop
+		if n.Tuplet != 0 {
+			if st.tuplet.Cmp(note.Rational{}) == 0 {
+				tuplets = append(tuplets, xmlStartStop{Type: "start"})
+			}
+			st.tuplet = st.tuplet.Add(d)
+			if isPowerOfTwo(st.tuplet.Den()) || g == len(v)-1 {
+				tuplets = append(tuplets, xmlStartStop{Type: "stop"})
+				st.tuplet = note.Rational{}
+			}
+		}
+
+		var slurs []xmlStartStop
+		if legato := score.IsLegato(group); legato && !st.slur {
+			if score.IsLegato(w.nextGroup(staff, m, j, g)) {
+				slurs = append(slurs, xmlStartStop{Type: "start"})
+				st.slur = true
+			}
+		} else if legato && !score.IsLegato(w.nextGroup(staff, m, j, g)) {
+			slurs = append(slurs, xmlStartStop{Type: "stop"})
+			st.slur = false
+		}
+
+		tied := map[int]bool{}
+		for k, nt := range notes {
+			xn := base
+			xn.Duration = w.ticks(d)
+			if k > 0 {
+				xn.Chord = &struct{}{}
+			}
+			if ok {
+				xn.Type = typeNames[n.Value]
+				xn.Dots = make([]struct{}, n.Dots)
+				if n.Tuplet != 0 {
+					xn.Tuplet = &xmlTuplet{Actual: n.Tuplet, Normal: n.Normal}
+				}
+			}
+
+			var notations xmlNotations
+			if sp, pitched := note.SpellWith(w.s.Key, nt.Pitch); !pitched {
+				xn.Rest = &xmlRest{}
+			} else {
+				xn.Pitch = &xmlPitch{Step: sp.Letter.String(), Alter: float64(sp.Accidental), Octave: sp.Octave}
+
+				if st.tied[nt.Key()] {
+					xn.Ties = append(xn.Ties, xmlStartStop{Type: "stop"})
+				}
+				if nt.Tie {
+					xn.Ties = append(xn.Ties, xmlStartStop{Type: "start"})
+					tied[nt.Key()] = true
+				}
+				notations.Tied = xn.Ties
+				notations.Articulations = articulationMarks(nt.Articulation)
+			}
+
+			if k == 0 {
+				xn.Beams = beams[g]
+				notations.Tuplets = tuplets
+				notations.Slurs = slurs
+			}
+			if len(notations.Tied)+len(notations.Tuplets)+len(notations.Slurs)+len(notations.Articulations) > 0 {
+				xn.Notations = []xmlNotations{notations}
+			}
+			elements = append(elements, &xn)
+		}
+		st.tied = tied
+	}
+	return elements
+}
+
+// dynamics returns the directions for the dynamic and hairpin of a group.
+// Hairpins end at the next dynamic or hairpin of the staff
+func (w *partWriter) dynamics(staff int, group []note.Note) []interface{} {
+	dyn, hairpin := note.NoDynamic, note.NoHairpin
+	for _, n := range group {
+		if dyn == note.NoDynamic {
+			dyn = n.Dynamic
+		}
+		if hairpin == note.NoHairpin {
+			hairpin = n.Hairpin
+		}
+	}
+	if dyn == note.NoDynamic && hairpin == note.NoHairpin {
+		return nil
+	}
+
+	var elements []interface{}
+	if w.wedges[staff] {
+		elements = append(elements, w.direction(staff, xmlDirectionType{Wedge: &xmlWedge{Type: "stop"}}))
+		w.wedges[staff] = false
+	}
+	if dyn != note.NoDynamic {
+		marks := xmlMarks{Marks: []xmlMark{{XMLName: xml.Name{Local: dyn.String()}}}}
+		elements = append(elements, w.direction(staff, xmlDirectionType{Dynamics: []xmlMarks{marks}}))
+	}
+	if hairpin != note.NoHairpin {
+		wedge := "crescendo"
+		if hairpin == note.Diminuendo {
+			wedge = "diminuendo"
+		}
+		elements = append(elements, w.direction(staff, xmlDirectionType{Wedge: &xmlWedge{Type: wedge}}))
+		w.wedges[staff] = true
+	}
+	return elements
+}
+
+// articulationMarks returns the articulations of a note, without legato
+// that is written as slurs
+func articulationMarks(a note.Articulation) []xmlMarks {
+	var marks xmlMarks
+	for _, art := range []struct {
+		a    note.Articulation
+		name string
+	}{
+		{note.Staccato, "staccato"},
+		{note.Accent, "accent"},
+		{note.Tenuto, "tenuto"},
+		{note.Marcato, "strong-accent"},
+	} {
+		if a.Has(art.a) {
+			marks.Marks = append(marks.Marks, xmlMark{XMLName: xml.Name{Local: art.name}})
+		}
+	}
+
+	if len(marks.Marks) == 0 {
+		return nil
+	}
+	return []xmlMarks{marks}
+}
+
+// isPowerOfTwo returns true for 1, 2, 4, 8...
+func isPowerOfTwo(n int64) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// beams returns the beams of each group of a voice. Eighths and shorter
+// notes are beamed with the rest of the notes in the same pulse, with a
+// beam for each flag
+func beams(v score.Voice, time score.TimeSignature) [][]xmlBeam {
+	pulse := time.Pulse()
+	beams := make([][]xmlBeam, len(v))
+
+	// flags returns the number of flags of a group, 0 for rests and notes
+	// that are not beamed
+	flags := func(group []note.Note) int {
+		n, ok := group[0].Duration.Notation()
+		if !ok || score.IsRestGroup(group) {
+			return 0
+		}
+		f := 0
+		for value := n.Value; value <= note.Eighth; value *= 2 {
+			f++
+		}
+		return f
+	}
+
+	// the runs of beamed groups within a pulse
+	var run []int
+	var runPulse int64
+	beamRun := func() {
+		for i, g := range run {
+			f := flags(v[g])
+			for level := 1; level <= f; level++ {
+				prev := i > 0 && flags(v[run[i-1]]) >= level
+				next := i < len(run)-1 && flags(v[run[i+1]]) >= level
+				value := "continue"
+				switch {
+				case prev && !next:
+					value = "end"
+				case !prev && next:
+					value = "begin"
+				case !prev && !next && i > 0:
+					value = "backward hook"
+				case !prev && !next:
+					value = "forward hook"
+				}
+				beams[g] = append(beams[g], xmlBeam{Number: level, Value: value})
+			}
+		}
+		run = nil
+	}
+
+	var pos note.Rational
+	for g, group := range v {
+		p := pos.Mul(note.NewRational(pulse.Den(), pulse.Num()))
+		n := p.Num() / p.Den()
+		if flags(group) == 0 || n != runPulse {
+			if len(run) > 1 {
+				beamRun()
+			}
+			run = nil
+		}
+		if flags(group) > 0 {
+			run = append(run, g)
+			runPulse = n
+		}
+		pos = pos.Add(group[0].Duration.Rational())
+	}
+	if len(run) > 1 {
+		beamRun()
+	}
+	return beams
+}
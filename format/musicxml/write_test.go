@@ -0,0 +1,114 @@
+package musicxml_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/musicxml"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write returns the document of a score, or fails the test
+func write(t *testing.T, s score.Score) string {
+	var b bytes.Buffer
+	require.NoError(t, musicxml.Write(&b, s))
+	return b.String()
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	s, err := musicxml.Parse(strings.NewReader(marble))
+	require.NoError(t, err)
+
+	doc := write(t, s)
+	assert.True(t, strings.HasPrefix(doc, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<!DOCTYPE score-partwise"))
+
+	parsed, err := musicxml.Parse(strings.NewReader(doc))
+	require.NoError(t, err)
+	assert.Equal(t, s, parsed)
+}
+
+func TestWrite(t *testing.T) {
+	eb, err := key.Parse("Eb")
+	require.NoError(t, err)
+	sixEight := score.TimeSignature{Beats: 6, Value: 8}
+
+	s := score.Score{
+		Key: eb,
+		Parts: []score.Part{{
+			Name: "Piano",
+			Staves: []score.Staff{
+				{
+					score.NewMeasure(sixEight,
+						[]note.Note{note.NewNote(note.Dsharp5, note.Eighth)},
+						[]note.Note{note.NewNote(note.C5, note.Sixteenth)},
+						[]note.Note{note.NewNote(note.D5, note.Sixteenth)},
+						[]note.Note{note.NewNote(note.G4, note.Eighth)},
+						[]note.Note{note.NewNote(note.F4, note.Quarter.Dotted()).Tied()},
+					),
+					score.NewMeasure(sixEight,
+						[]note.Note{note.NewNote(note.F4, note.Quarter)},
+						[]note.Note{note.NewNote(note.E4, note.Quarter)},
+						[]note.Note{note.NewRest(note.Quarter)},
+					),
+				},
+				{
+					score.NewMeasure(sixEight,
+						[]note.Note{note.NewNote(note.C3, note.Quarter), note.NewNote(note.G3, note.Quarter)},
+						[]note.Note{note.NewRest(note.Eighth)},
+						[]note.Note{note.NewNote(note.C2, note.Half.Dotted()/2)},
+					),
+					score.NewMeasure(sixEight, []note.Note{note.NewRest(note.Half.Dotted())}),
+				},
+			},
+		}},
+	}
+
+	// the elements without indentation
+	doc := regexp.MustCompile(`>\s+<`).ReplaceAllString(write(t, s), "><")
+	for _, expected := range []string{
+		`<key><fifths>-3</fifths><mode>major</mode></key>`,
+		`<time><beats>6</beats><beat-type>8</beat-type></time>`,
+		`<clef number="1"><sign>G</sign><line>2</line></clef>`,
+		`<clef number="2"><sign>F</sign><line>4</line></clef>`,
+		// D#5 is E-flat in the key
+		`<step>E</step><alter>-1</alter><octave>5</octave>`,
+		`<beam number="1">begin</beam>`,
+		`<beam number="2">begin</beam>`,
+		`<beam number="2">end</beam>`,
+		`<beam number="1">end</beam>`,
+		`<chord></chord>`,
+		`<tie type="stop"></tie>`,
+		`<rest measure="yes"></rest>`,
+	} {
+		assert.Contains(t, doc, expected)
+	}
+
+	parsed, err := musicxml.Parse(strings.NewReader(doc))
+	require.NoError(t, err)
+	treble := parsed.Parts[0].Staves[0]
+	require.Len(t, treble, 2)
+
+	// The F4 is tied across the bar line, and the E4 is split at the pulse
+	eflat := note.NewSpelledPitch(note.E, note.Flat, 5)
+	f4 := note.NewSpelledPitch(note.F, note.Natural, 4)
+	e4 := note.NewSpelledPitch(note.E, note.Natural, 4)
+	assert.Equal(t, score.Voice{
+		{note.NewNote(eflat, note.Eighth)},
+		{note.NewNote(note.NewSpelledPitch(note.C, note.Natural, 5), note.Sixteenth)},
+		{note.NewNote(note.NewSpelledPitch(note.D, note.Natural, 5), note.Sixteenth)},
+		{note.NewNote(note.NewSpelledPitch(note.G, note.Natural, 4), note.Eighth)},
+		{note.NewNote(f4, note.Quarter.Dotted()).Tied()},
+	}, treble[0].Voices[0])
+	assert.Equal(t, score.Voice{
+		{note.NewNote(f4, note.Quarter)},
+		{note.NewNote(e4, note.Eighth).Tied()},
+		{note.NewNote(e4, note.Eighth)},
+		{note.NewRest(note.Quarter)},
+	}, treble[1].Voices[0])
+}
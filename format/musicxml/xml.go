@@ -0,0 +1,297 @@
+package musicxml
+
+import (
+	"encoding/xml"
+)
+
+// xmlScore is the root element of a partwise MusicXML document
+type xmlScore struct {
+	XMLName        xml.Name
+	Version        string             `xml:"version,attr,omitempty"`
+	Work           *xmlWork           `xml:"work"`
+	MovementTitle  string             `xml:"movement-title,omitempty"`
+	Identification *xmlIdentification `xml:"identification"`
+	PartList       []xmlScorePart     `xml:"part-list>score-part"`
+	Parts          []xmlPart          `xml:"part"`
+}
+
+// xmlWork is the work the score belongs to
+type xmlWork struct {
+	Title string `xml:"work-title"`
+}
+
+// xmlIdentification has the creators of the score
+type xmlIdentification struct {
+	Creators []xmlCreator `xml:"creator"`
+}
+
+// xmlCreator is a composer, arranger, lyricist...
+type xmlCreator struct {
+	Type string `xml:"type,attr"`
+	Name string `xml:",chardata"`
+}
+
+// xmlScorePart is the description of a part in the part list
+type xmlScorePart struct {
+	ID         string              `xml:"id,attr"`
+	Name       string              `xml:"part-name"`
+	Instrument *xmlScoreInstrument `xml:"score-instrument"`
+	MIDI       *xmlMIDIInstrument  `xml:"midi-instrument"`
+}
+
+// xmlScoreInstrument is the instrument of a part
+type xmlScoreInstrument struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"instrument-name"`
+}
+
+// xmlMIDIInstrument is the playback of an instrument
+type xmlMIDIInstrument struct {
+	ID string `xml:"id,attr"`
+	// Program is the General MIDI program, from 1 to 128
+	Program int `xml:"midi-program,omitempty"`
+}
+
+// xmlPart has the measures of a part
+type xmlPart struct {
+	ID       string       `xml:"id,attr"`
+	Measures []xmlMeasure `xml:"measure"`
+}
+
+// xmlMeasure has the elements of a measure in the order they appear, one of
+// *xmlNote, *xmlMove, *xmlAttributes, *xmlDirection or *xmlSound
+type xmlMeasure struct {
+	Number   string
+	Elements []interface{}
+}
+
+// UnmarshalXML decodes the known elements of the measure, keeping their order
+func (m *xmlMeasure) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		if a.Name.Local == "number" {
+			m.Number = a.Value
+		}
+	}
+
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			var e interface{}
+			switch t.Name.Local {
+			case "note":
+				e = &xmlNote{}
+			case "backup":
+				e = &xmlMove{Backup: true}
+			case "forward":
+				e = &xmlMove{}
+			case "attributes":
+				e = &xmlAttributes{}
+			case "direction":
+				e = &xmlDirection{}
+			case "sound":
+				e = &xmlSound{}
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := d.DecodeElement(e, &t); err != nil {
+				return err
+			}
+			m.Elements = append(m.Elements, e)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalXML encodes the measure number and the elements, in order
+func (m xmlMeasure) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "number"}, Value: m.Number})
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, el := range m.Elements {
+		var name string
+		switch el := el.(type) {
+		case *xmlNote:
+			name = "note"
+		case *xmlMove:
+			name = "forward"
+			if el.Backup {
+				name = "backup"
+			}
+		case *xmlAttributes:
+			name = "attributes"
+		case *xmlDirection:
+			name = "direction"
+		case *xmlSound:
+			name = "sound"
+		}
+
+		if err := e.EncodeElement(el, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// xmlNote is a note, a rest or a note of a chord
+type xmlNote struct {
+	Grace     *struct{}      `xml:"grace"`
+	Cue       *struct{}      `xml:"cue"`
+	Chord     *struct{}      `xml:"chord"`
+	Pitch     *xmlPitch      `xml:"pitch"`
+	Unpitched *xmlPitch      `xml:"unpitched"`
+	Rest      *xmlRest       `xml:"rest"`
+	Duration  int            `xml:"duration"`
+	Ties      []xmlStartStop `xml:"tie"`
+	Voice     string         `xml:"voice,omitempty"`
+	Type      string         `xml:"type,omitempty"`
+	Dots      []struct{}     `xml:"dot"`
+	Tuplet    *xmlTuplet     `xml:"time-modification"`
+	Staff     int            `xml:"staff,omitempty"`
+	Beams     []xmlBeam      `xml:"beam"`
+	Notations []xmlNotations `xml:"notations"`
+}
+
+// xmlPitch is the pitch of a note. Unpitched notes use the display step and
+// octave, the position in the staff
+type xmlPitch struct {
+	Step          string  `xml:"step,omitempty"`
+	Alter         float64 `xml:"alter,omitempty"`
+	Octave        int     `xml:"octave"`
+	DisplayStep   string  `xml:"display-step,omitempty"`
+	DisplayOctave int     `xml:"display-octave,omitempty"`
+}
+
+// xmlRest is a rest. Measure rests last the whole measure
+type xmlRest struct {
+	Measure string `xml:"measure,attr,omitempty"`
+}
+
+// xmlStartStop is an element with type start or stop, like ties, slurs and
+// tuplets
+type xmlStartStop struct {
+	Type string `xml:"type,attr"`
+}
+
+// xmlBeam is the beam of a level, 1 for eighths, 2 for sixteenths... The
+// value is begin, continue, end, forward hook or backward hook
+type xmlBeam struct {
+	Number int    `xml:"number,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// xmlTuplet is the time modification of the notes of a tuplet, e.g. 3
+// actual notes in the time of 2 normal ones for triplets
+type xmlTuplet struct {
+	Actual int `xml:"actual-notes"`
+	Normal int `xml:"normal-notes"`
+}
+
+// xmlNotations are the ties, slurs, tuplets, articulations and dynamics of
+// a note
+type xmlNotations struct {
+	Tied          []xmlStartStop `xml:"tied"`
+	Slurs         []xmlStartStop `xml:"slur"`
+	Tuplets       []xmlStartStop `xml:"tuplet"`
+	Articulations []xmlMarks     `xml:"articulations"`
+	Dynamics      []xmlMarks     `xml:"dynamics"`
+}
+
+// xmlMarks are elements identified by their name, like <staccato/> inside
+// articulations or <mf/> inside dynamics
+type xmlMarks struct {
+	Marks []xmlMark `xml:",any"`
+}
+
+// xmlMark is an empty element like <staccato/>
+type xmlMark struct {
+	XMLName xml.Name
+}
+
+// names returns the names of the marks
+func (m xmlMarks) names() []string {
+	names := make([]string, len(m.Marks))
+	for i, mark := range m.Marks {
+		names[i] = mark.XMLName.Local
+	}
+	return names
+}
+
+// xmlMove moves the position in the measure back or forward, to write
+// several voices or staves in a measure
+type xmlMove struct {
+	Backup   bool `xml:"-"`
+	Duration int  `xml:"duration"`
+}
+
+// xmlAttributes are the attributes that change in a measure
+type xmlAttributes struct {
+	Divisions int       `xml:"divisions,omitempty"`
+	Keys      []xmlKey  `xml:"key"`
+	Times     []xmlTime `xml:"time"`
+	Staves    int       `xml:"staves,omitempty"`
+	Clefs     []xmlClef `xml:"clef"`
+}
+
+// xmlKey is a key signature, the number of sharps or flats and the mode
+type xmlKey struct {
+	Fifths int    `xml:"fifths"`
+	Mode   string `xml:"mode,omitempty"`
+}
+
+// xmlTime is a time signature. Beats can be a sum, e.g. 3+2
+type xmlTime struct {
+	Beats    string `xml:"beats"`
+	BeatType int    `xml:"beat-type"`
+}
+
+// xmlClef is the clef of a staff, numbered from 1
+type xmlClef struct {
+	Number int    `xml:"number,attr,omitempty"`
+	Sign   string `xml:"sign"`
+	Line   int    `xml:"line"`
+}
+
+// xmlDirection is a marking that is not attached to a note
+type xmlDirection struct {
+	Placement string             `xml:"placement,attr,omitempty"`
+	Types     []xmlDirectionType `xml:"direction-type"`
+	Offset    int                `xml:"offset,omitempty"`
+	Staff     int                `xml:"staff,omitempty"`
+	Sound     *xmlSound          `xml:"sound"`
+}
+
+// xmlDirectionType is the kind of direction
+type xmlDirectionType struct {
+	Dynamics  []xmlMarks    `xml:"dynamics"`
+	Wedge     *xmlWedge     `xml:"wedge"`
+	Metronome *xmlMetronome `xml:"metronome"`
+}
+
+// xmlWedge is a hairpin, with type crescendo, diminuendo or stop
+type xmlWedge struct {
+	Type string `xml:"type,attr"`
+}
+
+// xmlMetronome is a tempo marking, e.g. a quarter with 120 per minute
+type xmlMetronome struct {
+	BeatUnit  string     `xml:"beat-unit"`
+	Dots      []struct{} `xml:"beat-unit-dot"`
+	PerMinute string     `xml:"per-minute"`
+}
+
+// xmlSound has the playback tempo, in quarter notes per minute
+type xmlSound struct {
+	Tempo string `xml:"tempo,attr,omitempty"`
+}
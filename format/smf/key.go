@@ -0,0 +1,35 @@
+package smf
+
+import (
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// keySignature returns the key of the data of a key signature meta event,
+// the number of sharps or flats and 1 for minor keys
+func keySignature(data []byte) key.Key {
+	m := key.Major
+	if data[1] == 1 {
+		m = key.Minor
+	}
+	return key.FromSignature(note.KeySignature(int8(data[0])), m)
+}
+
+// keySignatureData returns the data of a key signature meta event. Keys with
+// more than 7 sharps or flats are written as their enharmonic key, and
+// modes other than minor as major keys with the same signature
+func keySignatureData(k key.Key) []byte {
+	sf := int(k.Signature())
+	for sf > 7 {
+		sf -= 12
+	}
+	for sf < -7 {
+		sf += 12
+	}
+
+	var mi byte
+	if k.Mode == key.Minor {
+		mi = 1
+	}
+	return []byte{byte(int8(sf)), mi}
+}
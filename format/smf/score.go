@@ -0,0 +1,430 @@
+package smf
+
+import (
+	"io"
+	"math"
+	"sort"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// Options changes how the events of a file are converted to notes
+type Options struct {
+	// Grid is the note value that positions and durations are rounded to,
+	// e.g. note.Sixteenth. Zero keeps the exact timing of the file
+	Grid note.Duration
+	// Triplets also rounds to the positions of the triplets of Grid
+	Triplets bool
+}
+
+// DefaultOptions rounds to sixteenth notes, which fits most performances
+var DefaultOptions = Options{Grid: note.Sixteenth}
+
+// Parse reads a Standard MIDI File and converts it to a score, see Read and
+// File.Score
+func Parse(r io.Reader, o Options) (score.Score, error) {
+	f, err := Read(r)
+	if err != nil {
+		return score.Score{}, err
+	}
+	return f.Score(o), nil
+}
+
+// Score converts the file to a score. Each channel of each track with notes
+// is a part with a single staff, named after the track, with the instrument
+// name of the track and the program of its first program change. The title
+// is the name of the first track, and the key the first key signature. Tempo
+// and time signature changes can be in any track, and time signatures change
+// at the next bar line
+//
+// Positions and durations are rounded with the options, notes are split into
+// tied notes at bar lines, and notes that overlap go to different voices.
+// Velocities are converted to the closest dynamic, which is only set when it
+// changes
+func (f *File) Score(o Options) score.Score {
+	c := converter{division: int64(f.Division), o: o}
+	var s score.Score
+	keyed := false
+
+	for i, t := range f.Tracks {
+		for _, e := range t {
+			pos := c.position(e.Tick)
+			switch {
+			case e.IsMeta(MetaTrackName) && i == 0 && s.Title == "":
+				s.Title = string(e.Data)
+			case e.IsMeta(MetaTempo):
+				s.Tempo.Changes = append(s.Tempo.Changes, score.TempoChange{
+					Position: pos,
+					Tempo:    tempo(e.Data),
+				})
+			case e.IsMeta(MetaTimeSignature):
+				c.meters = append(c.meters, meter{pos, score.TimeSignature{
+					Beats: int(e.Data[0]),
+					Value: 1 << e.Data[1],
+				}})
+			case e.IsMeta(MetaKeySignature) && !keyed:
+				s.Key = keySignature(e.Data)
+				keyed = true
+			}
+		}
+	}
+	sort.SliceStable(c.meters, func(i, j int) bool {
+		return c.meters[i].pos.Cmp(c.meters[j].pos) < 0
+	})
+
+	var parts []*part
+	for _, t := range f.Tracks {
+		parts = append(parts, c.parts(t)...)
+	}
+
+	var end note.Rational
+	for _, p := range parts {
+		for _, n := range p.notes {
+			if n.end.Cmp(end) > 0 {
+				end = n.end
+			}
+		}
+	}
+	bars := c.bars(end)
+
+	for _, p := range parts {
+		s.Parts = append(s.Parts, score.Part{
+			Name:       p.name,
+			Instrument: p.instrument,
+			Staves:     []score.Staff{p.staff(bars)},
+		})
+	}
+	return s
+}
+
+// tempo returns the tempo of the data of a tempo meta event, in microseconds
+// per quarter note
+func tempo(data []byte) score.Tempo {
+	micros := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+	if micros == 0 {
+		return score.DefaultTempo
+	}
+	return score.Tempo{Beat: note.Quarter, BPM: int(math.Round(60e6 / float64(micros)))}
+}
+
+// converter keeps the state to convert the tracks of a file
+type converter struct {
+	division int64
+	o        Options
+	// meters are the time signature changes, sorted by position
+	meters []meter
+}
+
+// meter is a time signature change
+type meter struct {
+	pos  note.Rational
+	time score.TimeSignature
+}
+
+// position returns the rounded position of a tick, in whole notes
+func (c *converter) position(tick int64) note.Rational {
+	return c.round(note.NewRational(tick, 4*c.division))
+}
+
+// round returns the closest multiple of the grid, or of its triplets
+func (c *converter) round(r note.Rational) note.Rational {
+	if c.o.Grid == 0 {
+		return r
+	}
+
+	grid := c.o.Grid.Rational()
+	rounded := roundTo(r, grid)
+	if c.o.Triplets {
+		t := roundTo(r, grid.Mul(note.NewRational(2, 3)))
+		if distance(t, r).Cmp(distance(rounded, r)) < 0 {
+			rounded = t
+		}
+	}
+	return rounded
+}
+
+// roundTo returns the closest multiple of step
+func roundTo(r, step note.Rational) note.Rational {
+	x := r.Mul(note.NewRational(step.Den(), step.Num()))
+	// floor(x + 1/2)
+	n := (2*x.Num() + x.Den()) / (2 * x.Den())
+	return step.Mul(note.NewRational(n, 1))
+}
+
+// distance returns |a - b|
+func distance(a, b note.Rational) note.Rational {
+	if a.Cmp(b) < 0 {
+		return b.Sub(a)
+	}
+	return a.Sub(b)
+}
+
+// bar is the position and time signature of a measure
+type bar struct {
+	start, end note.Rational
+	time       score.TimeSignature
+}
+
+// bars returns the measures needed to reach the end position, at least one.
+// Time signatures change at the next bar line after their position
+func (c *converter) bars(end note.Rational) []bar {
+	var bars []bar
+	var pos note.Rational
+	time := score.CommonTime
+	next := 0
+
+	for len(bars) == 0 || pos.Cmp(end) < 0 {
+		for next < len(c.meters) && c.meters[next].pos.Cmp(pos) <= 0 {
+			time = c.meters[next].time
+			next++
+		}
+
+		b := bar{start: pos, end: pos.Add(time.Duration()), time: time}
+		bars = append(bars, b)
+		pos = b.end
+	}
+	return bars
+}
+
+// part has the notes of a channel of a track
+type part struct {
+	name       string
+	instrument score.Instrument
+	notes      []played
+}
+
+// played is a note played between two positions
+type played struct {
+	start, end note.Rational
+	key        int
+	velocity   int
+}
+
+// parts returns the notes of each channel of the track, in channel order.
+// Notes still sounding at the end of the track end there
+func (c *converter) parts(t Track) []*part {
+	var name, instrument string
+	programs := map[int]int{}
+	channels := map[int]*part{}
+	// sounding are the notes on, by channel and key
+	sounding := map[[2]int][]played{}
+
+	end := func(channel, key int, tick int64) {
+		k := [2]int{channel, key}
+		if len(sounding[k]) == 0 {
+			return
+		}
+
+		// the first note on is the one that ends
+		n := sounding[k][0]
+		sounding[k] = sounding[k][1:]
+		n.end = c.position(tick)
+		if n.end.Cmp(n.start) <= 0 {
+			if c.o.Grid == 0 {
+				return
+			}
+			n.end = n.start.Add(c.o.Grid.Rational())
+		}
+
+		p := channels[channel]
+		p.notes = append(p.notes, n)
+	}
+
+	var tick int64
+	for _, e := range t {
+		tick = e.Tick
+		switch {
+		case e.IsMeta(MetaTrackName):
+			name = string(e.Data)
+		case e.IsMeta(MetaInstrument):
+			instrument = string(e.Data)
+		case e.Type() == ProgramChange:
+			if _, ok := programs[e.Channel()]; !ok {
+				programs[e.Channel()] = int(e.Data[0])
+			}
+		case e.Type() == NoteOn && e.Data[1] > 0:
+			if channels[e.Channel()] == nil {
+				channels[e.Channel()] = &part{}
+			}
+			k := [2]int{e.Channel(), int(e.Data[0])}
+			sounding[k] = append(sounding[k], played{
+				start:    c.position(e.Tick),
+				key:      int(e.Data[0]),
+				velocity: int(e.Data[1]),
+			})
+		case e.Type() == NoteOn, e.Type() == NoteOff:
+			end(e.Channel(), int(e.Data[0]), e.Tick)
+		}
+	}
+
+	for k, notes := range sounding {
+		for range notes {
+			end(k[0], k[1], tick)
+		}
+	}
+
+	var parts []*part
+	for ch := 0; ch < 16; ch++ {
+		p, ok := channels[ch]
+		if !ok || len(p.notes) == 0 {
+			continue
+		}
+
+		p.name = name
+		p.instrument = score.Instrument{Name: instrument, Program: programs[ch]}
+		sort.SliceStable(p.notes, func(i, j int) bool {
+			a, b := p.notes[i], p.notes[j]
+			if c := a.start.Cmp(b.start); c != 0 {
+				return c < 0
+			}
+			return a.key < b.key
+		})
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// chord is a group of notes that start at the same time
+type chord struct {
+	start, end note.Rational
+	notes      []played
+}
+
+// staff returns the notes of the part in the measures. Chords go to the
+// first voice that is not sounding when they start
+func (p *part) staff(bars []bar) score.Staff {
+	var voices [][]chord
+	for i := 0; i < len(p.notes); {
+		ch := chord{start: p.notes[i].start}
+		for ; i < len(p.notes) && p.notes[i].start == ch.start; i++ {
+			ch.notes = append(ch.notes, p.notes[i])
+			if p.notes[i].end.Cmp(ch.end) > 0 {
+				ch.end = p.notes[i].end
+			}
+		}
+
+		v := 0
+		for ; v < len(voices); v++ {
+			if voices[v][len(voices[v])-1].end.Cmp(ch.start) <= 0 {
+				break
+			}
+		}
+		if v == len(voices) {
+			voices = append(voices, nil)
+		}
+		voices[v] = append(voices[v], ch)
+	}
+
+	staff := make(score.Staff, len(bars))
+	for i, b := range bars {
+		staff[i].Time = b.time
+	}
+	for _, chords := range voices {
+		for i, v := range split(chords, bars) {
+			staff[i].Voices = append(staff[i].Voices, v)
+		}
+	}
+
+	// voices that only rest at the end of a measure are not needed
+	for i, m := range staff {
+		for len(m.Voices) > 1 && isRest(m.Voices[len(m.Voices)-1]) {
+			m.Voices = m.Voices[:len(m.Voices)-1]
+		}
+		staff[i] = m
+	}
+	return staff
+}
+
+// split returns the groups of notes of a voice in each measure. Gaps are
+// filled with rests, and notes that cross a bar line are tied
+func split(chords []chord, bars []bar) []score.Voice {
+	voices := make([]score.Voice, len(bars))
+	dynamic := note.NoDynamic
+
+	b := 0
+	var pos note.Rational
+	// add appends a group from pos to the position end, in as many
+	// measures as needed. notes returns the group for each part
+	add := func(end note.Rational, notes func(start, end note.Rational) []note.Note) {
+		for pos.Cmp(end) < 0 {
+			for bars[b].end.Cmp(pos) <= 0 {
+				b++
+			}
+
+			segment := end
+			if bars[b].end.Cmp(segment) < 0 {
+				segment = bars[b].end
+			}
+			voices[b] = append(voices[b], notes(pos, segment))
+			pos = segment
+		}
+	}
+	rest := func(start, end note.Rational) []note.Note {
+		return []note.Note{note.NewRest(end.Sub(start).Duration())}
+	}
+
+	for _, ch := range chords {
+		add(ch.start, rest)
+
+		first := true
+		add(ch.end, func(start, end note.Rational) []note.Note {
+			var group []note.Note
+			for _, n := range ch.notes {
+				if n.end.Cmp(start) <= 0 {
+					continue
+				}
+
+				nend := n.end
+				if nend.Cmp(end) > 0 {
+					nend = end
+				}
+				nn := note.NewNote(note.FromKey(n.key), nend.Sub(start).Duration())
+				nn.Tie = n.end.Cmp(end) > 0
+				group = append(group, nn)
+			}
+
+			if first {
+				if d := closestDynamic(ch.notes[0].velocity); d != dynamic {
+					group[0].Dynamic = d
+					dynamic = d
+				}
+				first = false
+			}
+			return group
+		})
+	}
+	add(bars[len(bars)-1].end, rest)
+
+	return voices
+}
+
+// closestDynamic returns the dynamic with the closest velocity
+func closestDynamic(velocity int) note.Dynamic {
+	closest := note.Pianississimo
+	for d := note.Pianississimo; d <= note.Fortississimo; d++ {
+		if abs(d.Velocity()-velocity) < abs(closest.Velocity()-velocity) {
+			closest = d
+		}
+	}
+	return closest
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// isRest returns true if all the notes of the voice are rests
+func isRest(v score.Voice) bool {
+	for _, g := range v {
+		for _, n := range g {
+			if n.Key() >= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,221 @@
+package smf_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/smf"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// delta returns a delta time as a variable length quantity
+func delta(ticks int) []byte {
+	b := []byte{byte(ticks & 0x7F)}
+	for ticks >>= 7; ticks > 0; ticks >>= 7 {
+		b = append([]byte{byte(ticks&0x7F) | 0x80}, b...)
+	}
+	return b
+}
+
+// event returns an event with its delta time
+func event(ticks int, data ...byte) []byte {
+	return append(delta(ticks), data...)
+}
+
+func parseFile(t *testing.T, o smf.Options, format int, tracks ...[]byte) score.Score {
+	t.Helper()
+	s, err := smf.Parse(bytes.NewReader(midiFile(format, 96, tracks...)), o)
+	require.NoError(t, err)
+	return s
+}
+
+func TestScore(t *testing.T) {
+	s := parseFile(t, smf.DefaultOptions, 0, bytes.Join([][]byte{
+		event(0, 0xFF, 0x03, 0x04, 'S', 'o', 'n', 'g'),
+		event(0, 0xFF, 0x51, 0x03, 0x09, 0x27, 0xC0),
+		event(0, 0xFF, 0x58, 0x04, 0x03, 0x02, 0x18, 0x08),
+		event(0, 0xC1, 40),
+		event(0, 0x90, 60, 80),
+		event(0, 0x91, 72, 45),
+		event(96, 0x80, 60, 0),
+		event(0, 0x90, 64, 96),
+		// running status with velocity 0 as note off
+		event(96, 64, 0),
+		event(0, 0x90, 67, 96),
+		event(0, 0x81, 72, 0),
+		event(192, 0x80, 67, 0),
+	}, nil))
+
+	assert.Equal(t, "Song", s.Title)
+	assert.Equal(t, score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 100}), s.Tempo)
+	require.Len(t, s.Parts, 2)
+
+	waltz := score.TimeSignature{Beats: 3, Value: 4}
+	assert.Equal(t, score.Part{
+		Name: "Song",
+		Staves: []score.Staff{{
+			score.NewMeasure(waltz,
+				[]note.Note{note.NewNote(note.C4, note.Quarter).WithDynamic(note.MezzoForte)},
+				[]note.Note{note.NewNote(note.E4, note.Quarter).WithDynamic(note.Forte)},
+				[]note.Note{note.NewNote(note.G4, note.Quarter).Tied()},
+			),
+			score.NewMeasure(waltz,
+				[]note.Note{note.NewNote(note.G4, note.Quarter)},
+				[]note.Note{note.NewRest(note.Half)},
+			),
+		}},
+	}, s.Parts[0])
+
+	assert.Equal(t, score.Part{
+		Name:       "Song",
+		Instrument: score.Instrument{Program: 40},
+		Staves: []score.Staff{{
+			score.NewMeasure(waltz,
+				[]note.Note{note.NewNote(note.C5, note.Half).WithDynamic(note.Piano)},
+				[]note.Note{note.NewRest(note.Quarter)},
+			),
+			score.NewMeasure(waltz, []note.Note{note.NewRest(note.Half.Dotted())}),
+		}},
+	}, s.Parts[1])
+
+	for _, p := range s.Parts {
+		assert.NoError(t, p.Staves[0].Validate())
+	}
+}
+
+func TestScoreFormat1(t *testing.T) {
+	s := parseFile(t, smf.DefaultOptions, 1,
+		bytes.Join([][]byte{
+			event(0, 0xFF, 0x03, 0x05, 'T', 'i', 't', 'l', 'e'),
+			event(0, 0xFF, 0x51, 0x03, 0x07, 0xA1, 0x20),
+			// 3/4 from the second bar, and 500000µs (120 BPM) in the third
+			event(384, 0xFF, 0x58, 0x04, 0x03, 0x02, 0x18, 0x08),
+			event(288, 0xFF, 0x51, 0x03, 0x07, 0xA1, 0x20),
+		}, nil),
+		bytes.Join([][]byte{
+			event(0, 0xFF, 0x03, 0x05, 'P', 'i', 'a', 'n', 'o'),
+			event(0, 0x90, 60, 80),
+			event(0, 0x90, 64, 80),
+			event(0, 0x90, 67, 80),
+			event(384, 0x80, 60, 0),
+			event(0, 0x80, 64, 0),
+			event(0, 0x80, 67, 0),
+			// sounding until the end of the track
+			event(0, 0x90, 48, 80),
+			event(576, 0xFF, 0x01, 0x03, 'e', 'n', 'd'),
+		}, nil),
+	)
+
+	assert.Equal(t, "Title", s.Title)
+	assert.Equal(t, score.TempoMap{Changes: []score.TempoChange{
+		{Tempo: score.Tempo{Beat: note.Quarter, BPM: 120}},
+		{Position: note.NewRational(7, 4), Tempo: score.Tempo{Beat: note.Quarter, BPM: 120}},
+	}}, s.Tempo)
+
+	require.Len(t, s.Parts, 1)
+	assert.Equal(t, "Piano", s.Parts[0].Name)
+
+	staff := s.Parts[0].Staves[0]
+	require.Len(t, staff, 3)
+	assert.Equal(t, score.CommonTime, staff[0].Time)
+	assert.Equal(t, score.TimeSignature{Beats: 3, Value: 4}, staff[1].Time)
+	assert.Equal(t, score.TimeSignature{Beats: 3, Value: 4}, staff[2].Time)
+
+	assert.Equal(t, score.Voice{{
+		note.NewNote(note.C4, note.Whole).WithDynamic(note.MezzoForte),
+		note.NewNote(note.E4, note.Whole),
+		note.NewNote(note.G4, note.Whole),
+	}}, staff[0].Voices[0])
+	assert.Equal(t, score.Voice{{note.NewNote(note.C3, note.Half.Dotted()).Tied()}}, staff[1].Voices[0])
+	assert.Equal(t, score.Voice{{note.NewNote(note.C3, note.Half.Dotted())}}, staff[2].Voices[0])
+	assert.NoError(t, staff.Validate())
+}
+
+func TestScoreVoices(t *testing.T) {
+	s := parseFile(t, smf.DefaultOptions, 0, bytes.Join([][]byte{
+		event(0, 0x90, 60, 80),
+		event(96, 0x90, 64, 80),
+		event(96, 0x80, 60, 0),
+		event(0, 0x80, 64, 0),
+		event(0, 0x90, 62, 80),
+		event(96, 0x80, 62, 0),
+	}, nil))
+
+	staff := s.Parts[0].Staves[0]
+	require.Len(t, staff, 1)
+	assert.Equal(t, []score.Voice{
+		{
+			{note.NewNote(note.C4, note.Half).WithDynamic(note.MezzoForte)},
+			{note.NewNote(note.D4, note.Quarter)},
+			{note.NewRest(note.Quarter)},
+		},
+		{
+			{note.NewRest(note.Quarter)},
+			{note.NewNote(note.E4, note.Quarter).WithDynamic(note.MezzoForte)},
+			{note.NewRest(note.Half)},
+		},
+	}, staff[0].Voices)
+	assert.NoError(t, staff.Validate())
+}
+
+func TestScoreQuantization(t *testing.T) {
+	// a quarter note played late and short, and triplet eighths
+	track := bytes.Join([][]byte{
+		event(3, 0x90, 60, 80),
+		event(90, 0x80, 60, 0),
+		event(3, 0x90, 62, 80),
+		event(32, 0x80, 62, 0),
+		event(0, 0x90, 64, 80),
+		event(32, 0x80, 64, 0),
+		event(0, 0x90, 65, 80),
+		event(32, 0x80, 65, 0),
+	}, nil)
+
+	durations := func(s score.Score) []note.Duration {
+		var d []note.Duration
+		for _, g := range s.Parts[0].Staves[0][0].Voices[0] {
+			d = append(d, g[0].Duration)
+		}
+		return d
+	}
+
+	triplet := note.Eighth.Triplet()
+	assert.Equal(t, []note.Duration{
+		note.Quarter, note.Sixteenth, note.Eighth, note.Sixteenth, note.Half,
+	}, durations(parseFile(t, smf.DefaultOptions, 0, track)))
+
+	assert.Equal(t, []note.Duration{
+		note.Quarter, triplet, triplet, triplet, note.Half,
+	}, durations(parseFile(t, smf.Options{Grid: note.Sixteenth, Triplets: true}, 0, track)))
+
+	exact := parseFile(t, smf.Options{}, 0, track)
+	assert.Equal(t, []note.Duration{
+		note.NewRational(3, 384).Duration(), note.NewRational(90, 384).Duration(),
+		note.NewRational(3, 384).Duration(), triplet, triplet, triplet, note.Half,
+	}, durations(exact))
+	assert.NoError(t, exact.Parts[0].Staves[0].Validate())
+}
+
+func TestClosestDynamic(t *testing.T) {
+	s := parseFile(t, smf.DefaultOptions, 0, bytes.Join([][]byte{
+		event(0, 0x90, 60, 1),
+		event(24, 0x80, 60, 0),
+		event(0, 0x90, 60, 3),
+		event(24, 0x80, 60, 0),
+		event(0, 0x90, 60, 70),
+		event(24, 0x80, 60, 0),
+		event(0, 0x90, 60, 127),
+		event(24, 0x80, 60, 0),
+	}, nil))
+
+	var dynamics []note.Dynamic
+	for _, g := range s.Parts[0].Staves[0][0].Voices[0] {
+		dynamics = append(dynamics, g[0].Dynamic)
+	}
+	assert.Equal(t, []note.Dynamic{
+		note.Pianississimo, note.NoDynamic, note.MezzoPiano, note.Fortississimo, note.NoDynamic,
+	}, dynamics)
+}
@@ -0,0 +1,344 @@
+package smf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// File is the content of a Standard MIDI File
+type File struct {
+	// Format is 0 for a single track, or 1 for simultaneous tracks
+	Format int
+	// Division is the number of ticks per quarter note
+	Division int
+	Tracks   []Track
+}
+
+// Track is a sequence of events, sorted by time
+type Track []Event
+
+// Event is a MIDI, meta or system exclusive event of a track
+type Event struct {
+	// Tick is the time of the event from the start of the track
+	Tick int64
+	// Status is the status byte. For channel events it has the message type
+	// in the high 4 bits and the channel in the low ones, e.g. 0x91 is a
+	// note on in the second channel. Meta events are 0xFF, and system
+	// exclusive events 0xF0 and 0xF7
+	Status byte
+	// Meta is the type of meta events
+	Meta byte
+	// Data are the bytes after the status byte, or after the type and length
+	// for meta and system exclusive events
+	Data []byte
+}
+
+// Channel message types, the high 4 bits of the status byte
+const (
+	NoteOff         byte = 0x80
+	NoteOn          byte = 0x90
+	KeyPressure     byte = 0xA0
+	ControlChange   byte = 0xB0
+	ProgramChange   byte = 0xC0
+	ChannelPressure byte = 0xD0
+	PitchBend       byte = 0xE0
+)
+
+// Status bytes of the events that are not channel messages
+const (
+	SysEx       byte = 0xF0
+	SysExEscape byte = 0xF7
+	Meta        byte = 0xFF
+)
+
+// Meta event types
+const (
+	MetaText          byte = 0x01
+	MetaTrackName     byte = 0x03
+	MetaInstrument    byte = 0x04
+	MetaEndOfTrack    byte = 0x2F
+	MetaTempo         byte = 0x51
+	MetaTimeSignature byte = 0x58
+	MetaKeySignature  byte = 0x59
+)
+
+// Type returns the message type of channel events, e.g. NoteOn, or the
+// status byte for the rest
+func (e Event) Type() byte {
+	if e.Status < SysEx {
+		return e.Status & 0xF0
+	}
+	return e.Status
+}
+
+// Channel returns the channel of channel events, from 0 to 15
+func (e Event) Channel() int {
+	return int(e.Status & 0x0F)
+}
+
+// IsMeta returns true for meta events of the given type
+func (e Event) IsMeta(t byte) bool {
+	return e.Status == Meta && e.Meta == t
+}
+
+// FormatError is returned for files that are not valid Standard MIDI Files,
+// with the position of the problem
+type FormatError struct {
+	// Offset is the position in bytes from the start of the file
+	Offset int64
+	Msg    string
+}
+
+// Error returns the position and description of the error
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Msg)
+}
+
+// Read reads a Standard MIDI File of format 0 or 1. Chunks with unknown
+// types are skipped. It returns a FormatError for truncated or corrupt
+// chunks, and for files with SMPTE time division
+func Read(r io.Reader) (*File, error) {
+	cr := &chunkReader{r: r}
+
+	header, err := cr.next()
+	if err == io.EOF {
+		return nil, &FormatError{Offset: 0, Msg: "empty file"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header.kind != "MThd" {
+		return nil, &FormatError{Offset: 0, Msg: "missing MThd header chunk"}
+	}
+	if len(header.data) < 6 {
+		return nil, &FormatError{Offset: 4, Msg: fmt.Sprintf("header chunk length is %d, expected 6", len(header.data))}
+	}
+
+	f := &File{
+		Format:   int(binary.BigEndian.Uint16(header.data[0:2])),
+		Division: int(binary.BigEndian.Uint16(header.data[4:6])),
+	}
+	tracks := int(binary.BigEndian.Uint16(header.data[2:4]))
+
+	switch {
+	case f.Format > 1:
+		return nil, &FormatError{Offset: 8, Msg: fmt.Sprintf("format %d is not supported", f.Format)}
+	case f.Format == 0 && tracks != 1:
+		return nil, &FormatError{Offset: 10, Msg: fmt.Sprintf("format 0 must have 1 track, found %d", tracks)}
+	case f.Division&0x8000 != 0:
+		return nil, &FormatError{Offset: 12, Msg: "SMPTE time division is not supported"}
+	case f.Division == 0:
+		return nil, &FormatError{Offset: 12, Msg: "time division is 0"}
+	}
+
+	for len(f.Tracks) < tracks {
+		c, err := cr.next()
+		if err == io.EOF {
+			return nil, &FormatError{Offset: cr.offset,
+				Msg: fmt.Sprintf("found %d tracks, expected %d", len(f.Tracks), tracks)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c.kind != "MTrk" {
+			continue
+		}
+
+		t, err := readTrack(c)
+		if err != nil {
+			return nil, err
+		}
+		f.Tracks = append(f.Tracks, t)
+	}
+
+	return f, nil
+}
+
+// chunk is a block of the file, a header or a track
+type chunk struct {
+	kind string
+	data []byte
+	// offset is the position of the data in the file
+	offset int64
+}
+
+// chunkReader reads the chunks of a file, keeping track of the position
+type chunkReader struct {
+	r      io.Reader
+	offset int64
+}
+
+// next returns the next chunk, or io.EOF at the end of the file
+func (cr *chunkReader) next() (chunk, error) {
+	var head [8]byte
+	n, err := io.ReadFull(cr.r, head[:])
+	switch {
+	case err == io.EOF:
+		return chunk{}, io.EOF
+	case err == io.ErrUnexpectedEOF:
+		return chunk{}, &FormatError{Offset: cr.offset + int64(n), Msg: "truncated chunk header"}
+	case err != nil:
+		return chunk{}, err
+	}
+
+	c := chunk{kind: string(head[:4]), offset: cr.offset + 8}
+	length := int64(binary.BigEndian.Uint32(head[4:]))
+
+	// the data is read as it comes, instead of allocating the length of
+	// the header, which can be wrong
+	var data bytes.Buffer
+	read, err := io.Copy(&data, io.LimitReader(cr.r, length))
+	if err != nil {
+		return chunk{}, err
+	}
+	if read < length {
+		return chunk{}, &FormatError{Offset: c.offset + read,
+			Msg: fmt.Sprintf("%s chunk has %d bytes, expected %d", c.kind, read, length)}
+	}
+	c.data = data.Bytes()
+
+	cr.offset = c.offset + int64(len(c.data))
+	return c, nil
+}
+
+// metaLengths are the data lengths of the meta events that are used to
+// convert the file to a score
+var metaLengths = map[byte]int{
+	MetaEndOfTrack:    0,
+	MetaTempo:         3,
+	MetaTimeSignature: 4,
+	MetaKeySignature:  2,
+}
+
+// readTrack reads the events of a track chunk, which must end with an end of
+// track meta event
+func readTrack(c chunk) (Track, error) {
+	r := bytes.NewReader(c.data)
+	errorf := func(format string, a ...interface{}) error {
+		offset := c.offset + int64(len(c.data)-r.Len())
+		return &FormatError{Offset: offset, Msg: fmt.Sprintf(format, a...)}
+	}
+
+	var track Track
+	var tick int64
+	var running byte
+	for r.Len() > 0 {
+		delta, err := readVarInt(r)
+		if err != nil {
+			return nil, errorf("%v", err)
+		}
+		tick += delta
+
+		e := Event{Tick: tick}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, errorf("missing event after delta time")
+		}
+
+		switch {
+		case b < 0x80:
+			// running status, b is the first data byte
+			if running == 0 {
+				return nil, errorf("running status without a previous status byte")
+			}
+			e.Status = running
+			r.UnreadByte()
+		case b < SysEx:
+			e.Status = b
+			running = b
+		default:
+			e.Status = b
+			// meta and system exclusive events cancel the running status
+			running = 0
+		}
+
+		switch e.Status {
+		case Meta:
+			if e.Meta, err = r.ReadByte(); err != nil {
+				return nil, errorf("truncated meta event")
+			}
+			if e.Data, err = readData(r); err != nil {
+				return nil, errorf("%v in meta event %#x", err, e.Meta)
+			}
+			if l, ok := metaLengths[e.Meta]; ok && len(e.Data) != l {
+				return nil, errorf("meta event %#x has %d bytes, expected %d", e.Meta, len(e.Data), l)
+			}
+			switch {
+			case e.IsMeta(MetaKeySignature) && (int8(e.Data[0]) < -7 || int8(e.Data[0]) > 7 || e.Data[1] > 1):
+				return nil, errorf("wrong key signature % x", e.Data)
+			case e.IsMeta(MetaTimeSignature) && (e.Data[0] == 0 || e.Data[1] > 6):
+				return nil, errorf("wrong time signature % x", e.Data)
+			}
+		case SysEx, SysExEscape:
+			if e.Data, err = readData(r); err != nil {
+				return nil, errorf("%v in system exclusive event", err)
+			}
+		default:
+			if e.Status > SysEx {
+				return nil, errorf("unexpected status byte %#x", e.Status)
+			}
+
+			e.Data = make([]byte, channelDataLength(e.Status))
+			if _, err := io.ReadFull(r, e.Data); err != nil {
+				return nil, errorf("truncated event %#x", e.Status)
+			}
+			for _, d := range e.Data {
+				if d >= 0x80 {
+					return nil, errorf("data byte %#x of event %#x is over 0x7f", d, e.Status)
+				}
+			}
+		}
+
+		track = append(track, e)
+		if e.IsMeta(MetaEndOfTrack) {
+			if r.Len() > 0 {
+				return nil, errorf("%d bytes after the end of track", r.Len())
+			}
+			return track, nil
+		}
+	}
+
+	return nil, errorf("track does not end with an end of track event")
+}
+
+// channelDataLength returns the number of data bytes of a channel message
+func channelDataLength(status byte) int {
+	switch status & 0xF0 {
+	case ProgramChange, ChannelPressure:
+		return 1
+	}
+	return 2
+}
+
+// readVarInt reads a variable length quantity, up to 4 bytes with 7 bits each
+func readVarInt(r io.ByteReader) (int64, error) {
+	var v int64
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, fmt.Errorf("truncated variable length quantity")
+		}
+		v = v<<7 | int64(b&0x7F)
+		if b < 0x80 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("variable length quantity longer than 4 bytes")
+}
+
+// readData reads a variable length quantity and that number of bytes
+func readData(r *bytes.Reader) ([]byte, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > int64(r.Len()) {
+		return nil, fmt.Errorf("length %d is over the end of the track", n)
+	}
+
+	data := make([]byte, n)
+	r.Read(data)
+	return data, nil
+}
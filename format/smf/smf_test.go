@@ -0,0 +1,135 @@
+package smf_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/smf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endOfTrack is the end of track meta event with a 0 delta time
+var endOfTrack = []byte{0x00, 0xFF, 0x2F, 0x00}
+
+// chunk returns a chunk with its type and length
+func chunk(kind string, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	head := make([]byte, 8)
+	copy(head, kind)
+	binary.BigEndian.PutUint32(head[4:], uint32(len(body)))
+	return append(head, body...)
+}
+
+// midiFile returns a file with the header and the tracks. The tracks are the
+// events, with their delta times, without the end of track
+func midiFile(format, division int, tracks ...[]byte) []byte {
+	header := make([]byte, 6)
+	binary.BigEndian.PutUint16(header[0:], uint16(format))
+	binary.BigEndian.PutUint16(header[2:], uint16(len(tracks)))
+	binary.BigEndian.PutUint16(header[4:], uint16(division))
+
+	file := chunk("MThd", header)
+	for _, t := range tracks {
+		file = append(file, chunk("MTrk", t, endOfTrack)...)
+	}
+	return file
+}
+
+func TestRead(t *testing.T) {
+	data := midiFile(1, 96,
+		[]byte{
+			0x00, 0xFF, 0x03, 0x04, 'S', 'o', 'n', 'g',
+			0x00, 0xFF, 0x51, 0x03, 0x07, 0xA1, 0x20,
+		},
+		[]byte{
+			0x00, 0xC1, 0x05,
+			0x00, 0x91, 0x3C, 0x40,
+			// running status, and a delta time of 2 bytes
+			0x81, 0x40, 0x3C, 0x00,
+			0x00, 0xF0, 0x02, 0x7E, 0xF7,
+			0x10, 0x81, 0x3E, 0x20,
+		},
+	)
+	// a chunk with an unknown type after the header
+	data = append(append(data[:14:14], chunk("XFIH", []byte{1, 2, 3})...), data[14:]...)
+
+	f, err := smf.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, f.Format)
+	assert.Equal(t, 96, f.Division)
+	require.Len(t, f.Tracks, 2)
+
+	assert.Equal(t, smf.Track{
+		{Tick: 0, Status: smf.Meta, Meta: smf.MetaTrackName, Data: []byte("Song")},
+		{Tick: 0, Status: smf.Meta, Meta: smf.MetaTempo, Data: []byte{0x07, 0xA1, 0x20}},
+		{Tick: 0, Status: smf.Meta, Meta: smf.MetaEndOfTrack, Data: []byte{}},
+	}, f.Tracks[0])
+
+	assert.Equal(t, smf.Track{
+		{Tick: 0, Status: 0xC1, Data: []byte{0x05}},
+		{Tick: 0, Status: 0x91, Data: []byte{0x3C, 0x40}},
+		{Tick: 192, Status: 0x91, Data: []byte{0x3C, 0x00}},
+		{Tick: 192, Status: smf.SysEx, Data: []byte{0x7E, 0xF7}},
+		{Tick: 208, Status: 0x81, Data: []byte{0x3E, 0x20}},
+		{Tick: 208, Status: smf.Meta, Meta: smf.MetaEndOfTrack, Data: []byte{}},
+	}, f.Tracks[1])
+
+	e := f.Tracks[1][1]
+	assert.Equal(t, smf.NoteOn, e.Type())
+	assert.Equal(t, 1, e.Channel())
+	assert.Equal(t, smf.SysEx, f.Tracks[1][3].Type())
+	assert.True(t, f.Tracks[0][1].IsMeta(smf.MetaTempo))
+}
+
+func TestReadErrors(t *testing.T) {
+	header := func(format, tracks, division int) []byte {
+		h := make([]byte, 6)
+		binary.BigEndian.PutUint16(h[0:], uint16(format))
+		binary.BigEndian.PutUint16(h[2:], uint16(tracks))
+		binary.BigEndian.PutUint16(h[4:], uint16(division))
+		return chunk("MThd", h)
+	}
+	withTrack := func(events ...byte) []byte {
+		return append(header(0, 1, 96), chunk("MTrk", events)...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", nil, "offset 0: empty file"},
+		{"not midi", chunk("RIFF", []byte{0, 0, 0, 0, 0, 0}), "offset 0: missing MThd header chunk"},
+		{"short header", chunk("MThd", []byte{0, 0}), "offset 4: header chunk length is 2, expected 6"},
+		{"truncated chunk", header(0, 1, 96)[:10], "offset 10: MThd chunk has 2 bytes, expected 6"},
+		{"huge chunk", []byte("MThd\xff\xff\xff\xf0"), "offset 8: MThd chunk has 0 bytes, expected 4294967280"},
+		{"format 2", header(2, 1, 96), "offset 8: format 2 is not supported"},
+		{"format 0 tracks", header(0, 2, 96), "offset 10: format 0 must have 1 track, found 2"},
+		{"smpte", header(1, 1, 0xE728), "offset 12: SMPTE time division is not supported"},
+		{"missing track", header(1, 2, 96), "offset 14: found 0 tracks, expected 2"},
+		{"truncated chunk header", append(header(0, 1, 96), 'M', 'T'), "offset 16: truncated chunk header"},
+		{"no end of track", withTrack(0x00, 0x90, 0x3C, 0x40), "offset 26: track does not end with an end of track event"},
+		{"running status", withTrack(0x00, 0x3C, 0x40), "offset 24: running status without a previous status byte"},
+		{"truncated event", withTrack(0x00, 0x90, 0x3C), "offset 25: truncated event 0x90"},
+		{"data byte", withTrack(0x00, 0x90, 0x3C, 0x80, 0x00, 0xFF, 0x2F, 0x00), "offset 26: data byte 0x80 of event 0x90 is over 0x7f"},
+		{"status byte", withTrack(0x00, 0xF2, 0x00), "offset 24: unexpected status byte 0xf2"},
+		{"long delta", withTrack(0x80, 0x80, 0x80, 0x80, 0x00), "offset 26: variable length quantity longer than 4 bytes"},
+		{"meta length", withTrack(0x00, 0xFF, 0x51, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00), "offset 31: meta event 0x51 has 5 bytes, expected 3"},
+		{"meta over the end", withTrack(0x00, 0xFF, 0x03, 0x10, 'a'), "offset 26: length 16 is over the end of the track in meta event 0x3"},
+		{"key signature", withTrack(0x00, 0xFF, 0x59, 0x02, 0x08, 0x00), "offset 28: wrong key signature 08 00"},
+		{"time signature", withTrack(0x00, 0xFF, 0x58, 0x04, 0x03, 0x07, 0x18, 0x08), "offset 30: wrong time signature 03 07 18 08"},
+		{"no beats", withTrack(0x00, 0xFF, 0x58, 0x04, 0x00, 0x02, 0x18, 0x08), "offset 30: wrong time signature 00 02 18 08"},
+		{"after the end", withTrack(0x00, 0xFF, 0x2F, 0x00, 0x00), "offset 26: 1 bytes after the end of track"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := smf.Read(bytes.NewReader(test.data))
+			require.Error(t, err)
+			assert.Equal(t, test.err, err.Error())
+		})
+	}
+}
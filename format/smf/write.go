@@ -0,0 +1,351 @@
+package smf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// DefaultDivision is the number of ticks per quarter note of the exported
+// files. It is divisible by 3 and 5, for triplets and quintuplets
+const DefaultDivision = 480
+
+// drumChannel is the General MIDI percussion channel, not used for parts
+const drumChannel = 9
+
+// Write writes the file as a Standard MIDI File. An end of track event is
+// added to the tracks that do not end with one
+func Write(w io.Writer, f *File) error {
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, 6)
+	binary.BigEndian.PutUint16(header[0:], uint16(f.Format))
+	binary.BigEndian.PutUint16(header[2:], uint16(len(f.Tracks)))
+	binary.BigEndian.PutUint16(header[4:], uint16(f.Division))
+	writeChunk(bw, "MThd", header)
+
+	for _, t := range f.Tracks {
+		writeChunk(bw, "MTrk", encodeTrack(t))
+	}
+	return bw.Flush()
+}
+
+// writeChunk writes the chunk type, length and data
+func writeChunk(w *bufio.Writer, kind string, data []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	w.WriteString(kind)
+	w.Write(length[:])
+	w.Write(data)
+}
+
+// encodeTrack returns the events of the track with delta times, without
+// running status
+func encodeTrack(t Track) []byte {
+	if len(t) == 0 || !t[len(t)-1].IsMeta(MetaEndOfTrack) {
+		var tick int64
+		if len(t) > 0 {
+			tick = t[len(t)-1].Tick
+		}
+		t = append(t[:len(t):len(t)], Event{Tick: tick, Status: Meta, Meta: MetaEndOfTrack})
+	}
+
+	var b []byte
+	var tick int64
+	for _, e := range t {
+		b = appendVarInt(b, e.Tick-tick)
+		tick = e.Tick
+
+		b = append(b, e.Status)
+		switch e.Status {
+		case Meta:
+			b = append(b, e.Meta)
+			b = appendVarInt(b, int64(len(e.Data)))
+		case SysEx, SysExEscape:
+			b = appendVarInt(b, int64(len(e.Data)))
+		}
+		b = append(b, e.Data...)
+	}
+	return b
+}
+
+// appendVarInt appends v as a variable length quantity
+func appendVarInt(b []byte, v int64) []byte {
+	var groups []byte
+	for {
+		groups = append(groups, byte(v&0x7F))
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		if i > 0 {
+			groups[i] |= 0x80
+		}
+		b = append(b, groups[i])
+	}
+	return b
+}
+
+// FromScore converts a score to a format 1 file. The first track has the
+// title, key signature, time signatures and tempo, and there is a track for
+// each staff of each part, with the part name, instrument and notes. Each
+// part plays in its own channel, skipping the percussion one.
+//
+// Tied notes are joined, articulations change the played length like the
+// renderer does, and the velocities follow the dynamics, hairpins and
+// accents. Tempo ramps and fermatas are written as tempo changes every
+// quarter note
+func FromScore(s score.Score) *File {
+	f := &File{Format: 1, Division: DefaultDivision}
+	c := exporter{division: DefaultDivision}
+
+	conductor := Track{
+		{Status: Meta, Meta: MetaTrackName, Data: []byte(s.Title)},
+		{Status: Meta, Meta: MetaKeySignature, Data: keySignatureData(s.Key)},
+	}
+	conductor = append(conductor, c.meters(s)...)
+	conductor = append(conductor, c.tempo(s.Tempo)...)
+	sort.SliceStable(conductor, func(i, j int) bool {
+		return conductor[i].Tick < conductor[j].Tick
+	})
+	f.Tracks = append(f.Tracks, conductor)
+
+	channel := 0
+	for _, p := range s.Parts {
+		for _, staff := range p.Staves {
+			f.Tracks = append(f.Tracks, c.track(p, channel, staff.Voices()))
+		}
+
+		channel++
+		if channel == drumChannel {
+			channel++
+		}
+		channel %= 16
+	}
+	return f
+}
+
+// FromStaves converts staves made of groups of simultaneous notes to a
+// format 1 file in common time, with a track for each staff. See FromScore
+func FromStaves(tempo score.Tempo, staves ...[][]note.Note) *File {
+	s := score.Score{
+		Tempo: score.ConstantTempo(tempo),
+		Parts: []score.Part{{}},
+	}
+	for _, groups := range staves {
+		s.Parts[0].Staves = append(s.Parts[0].Staves, score.Staff{
+			score.NewMeasure(score.CommonTime, groups...),
+		})
+	}
+	return FromScore(s)
+}
+
+// exporter keeps the state to convert a score
+type exporter struct {
+	division int64
+}
+
+// tick returns the tick of a position in whole notes, rounded
+func (c *exporter) tick(pos note.Rational) int64 {
+	t := pos.Mul(note.NewRational(4*c.division, 1))
+	return int64(math.Round(float64(t.Num()) / float64(t.Den())))
+}
+
+// meters returns the time signature events of the measures of the first
+// staff
+func (c *exporter) meters(s score.Score) Track {
+	var t Track
+	if len(s.Parts) == 0 || len(s.Parts[0].Staves) == 0 {
+		return t
+	}
+
+	var pos note.Rational
+	var last score.TimeSignature
+	for _, m := range s.Parts[0].Staves[0] {
+		if m.Time != last {
+			dd := 0
+			for 1<<uint(dd) < m.Time.Value {
+				dd++
+			}
+			t = append(t, Event{
+				Tick:   c.tick(pos),
+				Status: Meta,
+				Meta:   MetaTimeSignature,
+				// 24 MIDI clocks per click, 8 32nd notes per quarter
+				Data: []byte{byte(m.Time.Beats), byte(dd), 24, 8},
+			})
+			last = m.Time
+		}
+		pos = pos.Add(m.Time.Duration())
+	}
+	return t
+}
+
+// tempo returns the tempo events. Each event has the average tempo until the
+// next one, so the total time is the same as the one of the tempo map
+func (c *exporter) tempo(m score.TempoMap) Track {
+	changes := append([]score.TempoChange(nil), m.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Position.Cmp(changes[j].Position) < 0
+	})
+
+	points := []note.Rational{{}}
+	quarter := note.NewRational(1, 4)
+	for i, ch := range changes {
+		points = append(points, ch.Position)
+		if ch.Ramp == score.Immediate || i+1 == len(changes) {
+			continue
+		}
+		for pos := ch.Position.Add(quarter); pos.Cmp(changes[i+1].Position) < 0; pos = pos.Add(quarter) {
+			points = append(points, pos)
+		}
+	}
+	for _, f := range m.Fermatas {
+		points = append(points, f.Position, f.Position.Add(f.Duration))
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Cmp(points[j]) < 0
+	})
+
+	var t Track
+	var last int
+	for i, pos := range points {
+		if i > 0 && pos == points[i-1] {
+			continue
+		}
+
+		next := pos.Add(quarter)
+		if i+1 < len(points) && points[i+1] != pos {
+			next = points[i+1]
+		}
+		quarters := next.Sub(pos).Mul(note.NewRational(4, 1)).Duration()
+		micros := int(math.Round(float64(m.Time(next)-m.Time(pos)) / float64(time.Microsecond) / float64(quarters)))
+		if micros == last {
+			continue
+		}
+
+		t = append(t, Event{
+			Tick:   c.tick(pos),
+			Status: Meta,
+			Meta:   MetaTempo,
+			Data:   []byte{byte(micros >> 16), byte(micros >> 8), byte(micros)},
+		})
+		last = micros
+	}
+	return t
+}
+
+// track returns the events of a staff
+func (c *exporter) track(p score.Part, channel int, voices []score.Voice) Track {
+	t := Track{{Status: Meta, Meta: MetaTrackName, Data: []byte(p.Name)}}
+	if p.Instrument.Name != "" {
+		t = append(t, Event{Status: Meta, Meta: MetaInstrument, Data: []byte(p.Instrument.Name)})
+	}
+	t = append(t, Event{Status: ProgramChange | byte(channel), Data: []byte{byte(p.Instrument.Program)}})
+
+	var notes Track
+	for _, v := range voices {
+		notes = append(notes, c.voice(channel, v)...)
+	}
+
+	// note offs go before note ons at the same tick, so repeated notes are
+	// not cut
+	sort.SliceStable(notes, func(i, j int) bool {
+		a, b := notes[i], notes[j]
+		switch {
+		case a.Tick != b.Tick:
+			return a.Tick < b.Tick
+		case a.Type() != b.Type():
+			return a.Type() == NoteOff
+		}
+		return a.Data[0] < b.Data[0]
+	})
+	return append(t, notes...)
+}
+
+// sounding is a note that may continue with ties
+type sounding struct {
+	start    note.Rational
+	velocity int
+}
+
+// voice returns the note on and off events of a voice
+func (c *exporter) voice(channel int, v score.Voice) Track {
+	var t Track
+	velocities := v.Velocities()
+	tied := map[int]sounding{}
+
+	var pos note.Rational
+	for i, group := range v {
+		continued := map[int]sounding{}
+		for j, n := range group {
+			k := n.Key()
+			if k < 0 {
+				continue
+			}
+
+			s, ok := tied[k]
+			if !ok {
+				s = sounding{start: pos, velocity: velocities[i][j]}
+			}
+			if n.Tie {
+				continued[k] = s
+				continue
+			}
+
+			played := n.Duration.Rational().Mul(lengthFactor(n.Articulation))
+			t = append(t, c.note(channel, k, s, pos.Add(played))...)
+		}
+
+		// ties without a following note end before the group
+		for k, s := range tied {
+			if !inGroup(group, k) {
+				t = append(t, c.note(channel, k, s, pos)...)
+			}
+		}
+
+		pos = pos.Add(groupDuration(group))
+		tied = continued
+	}
+
+	for k, s := range tied {
+		t = append(t, c.note(channel, k, s, pos)...)
+	}
+	return t
+}
+
+// note returns the note on and off events of a note until the end position
+func (c *exporter) note(channel, key int, s sounding, end note.Rational) Track {
+	return Track{
+		{Tick: c.tick(s.start), Status: NoteOn | byte(channel), Data: []byte{byte(key), byte(s.velocity)}},
+		{Tick: c.tick(end), Status: NoteOff | byte(channel), Data: []byte{byte(key), 0}},
+	}
+}
+
+// lengthFactor returns the played fraction of the written duration
+func lengthFactor(a note.Articulation) note.Rational {
+	return note.Duration(a.Length()).Rational()
+}
+
+// groupDuration returns the duration of the longest note in the group
+func groupDuration(group []note.Note) note.Rational {
+	return score.Voice{group}.Duration()
+}
+
+// inGroup returns true if the group has a note with the key
+func inGroup(group []note.Note, key int) bool {
+	for _, n := range group {
+		if n.Key() == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package smf_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/smf"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	f := &smf.File{Format: 1, Division: 96, Tracks: []smf.Track{
+		{
+			{Tick: 0, Status: smf.Meta, Meta: smf.MetaTrackName, Data: []byte("Song")},
+		},
+		{
+			{Tick: 0, Status: 0x91, Data: []byte{0x3C, 0x40}},
+			{Tick: 200, Status: 0x81, Data: []byte{0x3C, 0x00}},
+			{Tick: 200, Status: smf.SysEx, Data: []byte{0x7E, 0xF7}},
+			{Tick: 200, Status: smf.Meta, Meta: smf.MetaEndOfTrack, Data: []byte{}},
+		},
+	}}
+
+	var b bytes.Buffer
+	require.NoError(t, smf.Write(&b, f))
+	assert.Equal(t, midiFile(1, 96,
+		[]byte{0x00, 0xFF, 0x03, 0x04, 'S', 'o', 'n', 'g'},
+		[]byte{
+			0x00, 0x91, 0x3C, 0x40,
+			0x81, 0x48, 0x81, 0x3C, 0x00,
+			0x00, 0xF0, 0x02, 0x7E, 0xF7,
+		},
+	), b.Bytes())
+
+	read, err := smf.Read(&b)
+	require.NoError(t, err)
+	f.Tracks[0] = append(f.Tracks[0], smf.Event{Status: smf.Meta, Meta: smf.MetaEndOfTrack, Data: []byte{}})
+	assert.Equal(t, f, read)
+}
+
+func TestScoreRoundTrip(t *testing.T) {
+	waltz := score.TimeSignature{Beats: 3, Value: 4}
+	march := score.TimeSignature{Beats: 2, Value: 4}
+	d, err := key.Parse("D")
+	require.NoError(t, err)
+
+	s := score.Score{
+		Title: "Round Trip",
+		Key:   d,
+		Tempo: score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 90}),
+		Parts: []score.Part{
+			{
+				Name:       "Melody",
+				Instrument: score.Instrument{Name: "Flute", Program: 73},
+				Staves: []score.Staff{{
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(note.Fsharp5, note.Quarter).WithDynamic(note.MezzoForte)},
+						[]note.Note{note.NewNote(note.E5, note.Eighth)},
+						[]note.Note{note.NewNote(note.D5, note.Eighth).WithDynamic(note.Forte)},
+						[]note.Note{note.NewNote(note.A4, note.Quarter).Tied()},
+					),
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(note.A4, note.Half)},
+						[]note.Note{note.NewRest(note.Quarter)},
+					),
+					score.NewMeasure(march,
+						[]note.Note{note.NewNote(note.D5, note.Sixteenth).WithDynamic(note.Piano)},
+						[]note.Note{note.NewNote(note.E5, note.Sixteenth)},
+						[]note.Note{note.NewRest(note.Eighth.Dotted())},
+						[]note.Note{note.NewNote(note.D5, note.Eighth.Dotted())},
+					),
+				}},
+			},
+			{
+				Name:       "Accompaniment",
+				Instrument: score.Instrument{Program: 0},
+				Staves: []score.Staff{{
+					score.NewMeasure(waltz,
+						[]note.Note{
+							note.NewNote(note.D3, note.Half.Dotted()).WithDynamic(note.Piano),
+							note.NewNote(note.A3, note.Half.Dotted()),
+						},
+					),
+					score.NewMeasure(waltz,
+						[]note.Note{note.NewNote(note.A2, note.Half.Dotted())},
+					),
+					score.NewMeasure(march,
+						[]note.Note{note.NewNote(note.D3, note.Half)},
+					),
+				}},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, smf.Write(&b, smf.FromScore(s)))
+
+	again, err := smf.Parse(&b, smf.DefaultOptions)
+	require.NoError(t, err)
+	assert.Equal(t, s, again)
+}
+
+// notes returns the note on and off events of a track
+func notes(t smf.Track) []smf.Event {
+	var events []smf.Event
+	for _, e := range t {
+		if e.Type() == smf.NoteOn || e.Type() == smf.NoteOff {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func TestFromScoreArticulations(t *testing.T) {
+	s := score.Score{Parts: []score.Part{{Staves: []score.Staff{score.NewStaff(score.CommonTime,
+		[][]note.Note{
+			{note.NewNote(note.C4, note.Quarter).Articulate(note.Staccato)},
+			{note.NewNote(note.C4, note.Quarter).Articulate(note.Accent)},
+			{note.NewNote(note.C4, note.Half).Tied()},
+		},
+		[][]note.Note{
+			{note.NewNote(note.C4, note.Half).Tied()},
+			{note.NewNote(note.C4, note.Half)},
+		},
+	)}}}}
+
+	f := smf.FromScore(s)
+	require.Len(t, f.Tracks, 2)
+	assert.Equal(t, []smf.Event{
+		{Tick: 0, Status: 0x90, Data: []byte{60, 80}},
+		{Tick: 240, Status: 0x80, Data: []byte{60, 0}},
+		{Tick: 480, Status: 0x90, Data: []byte{60, 100}},
+		{Tick: 960, Status: 0x80, Data: []byte{60, 0}},
+		{Tick: 960, Status: 0x90, Data: []byte{60, 80}},
+		{Tick: 3840, Status: 0x80, Data: []byte{60, 0}},
+	}, notes(f.Tracks[1]))
+}
+
+func TestFromScoreTempo(t *testing.T) {
+	s := score.Score{
+		Tempo: score.TempoMap{
+			Changes: []score.TempoChange{
+				{Tempo: score.Tempo{Beat: note.Quarter, BPM: 120}},
+				{Position: note.NewRational(1, 1), Tempo: score.Tempo{Beat: note.Half, BPM: 30}},
+			},
+			Fermatas: []score.Fermata{
+				{Position: note.NewRational(1, 4), Duration: note.NewRational(1, 4)},
+			},
+		},
+	}
+
+	var tempos []smf.Event
+	for _, e := range smf.FromScore(s).Tracks[0] {
+		if e.IsMeta(smf.MetaTempo) {
+			tempos = append(tempos, e)
+		}
+	}
+
+	tempo := func(tick int64, micros int) smf.Event {
+		return smf.Event{Tick: tick, Status: smf.Meta, Meta: smf.MetaTempo,
+			Data: []byte{byte(micros >> 16), byte(micros >> 8), byte(micros)}}
+	}
+	assert.Equal(t, []smf.Event{
+		tempo(0, 500000),
+		tempo(480, 1000000),
+		tempo(960, 500000),
+		tempo(1920, 1000000),
+	}, tempos)
+}
+
+func TestFromScoreKeySignature(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"C":        {0, 0},
+		"Eb":       {0xFD, 0},
+		"F#m":      {3, 1},
+		"G#":       {0xFC, 0},
+		"D dorian": {0, 0},
+	} {
+		k, err := key.Parse(name)
+		require.NoError(t, err)
+
+		track := smf.FromScore(score.Score{Key: k}).Tracks[0]
+		assert.Equal(t, smf.Event{Status: smf.Meta, Meta: smf.MetaKeySignature, Data: data}, track[1], name)
+	}
+}
+
+func TestFromStaves(t *testing.T) {
+	f := smf.FromStaves(score.Tempo{Beat: note.Quarter, BPM: 60},
+		[][]note.Note{{note.NewNote(note.E4, note.Quarter)}, {note.NewNote(note.G4, note.Quarter)}},
+		[][]note.Note{{note.NewNote(note.C3, note.Half)}},
+	)
+
+	assert.Equal(t, 1, f.Format)
+	require.Len(t, f.Tracks, 3)
+	assert.Equal(t, []smf.Event{
+		{Tick: 0, Status: 0x90, Data: []byte{64, 80}},
+		{Tick: 480, Status: 0x80, Data: []byte{64, 0}},
+		{Tick: 480, Status: 0x90, Data: []byte{67, 80}},
+		{Tick: 960, Status: 0x80, Data: []byte{67, 0}},
+	}, notes(f.Tracks[1]))
+	assert.Equal(t, []smf.Event{
+		{Tick: 0, Status: 0x90, Data: []byte{48, 80}},
+		{Tick: 960, Status: 0x80, Data: []byte{48, 0}},
+	}, notes(f.Tracks[2]))
+}
@@ -0,0 +1,140 @@
+package text
+
+import (
+	"strings"
+
+	"github.com/carlosms/music-playground/format/lilypond"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// Format returns the staff written in the text notation read by ParseStaff,
+// one measure per line. Durations are only written when they change
+func Format(s score.Staff) string {
+	f := formatter{last: note.Quarter}
+	time := score.CommonTime
+
+	var b strings.Builder
+	for _, m := range s {
+		var items []string
+		if m.Time != time {
+			items = append(items, `\time `+m.Time.String())
+			time = m.Time
+		}
+
+		for i, v := range m.Voices {
+			if i > 0 {
+				items = append(items, `\\`)
+			}
+			for j, group := range v {
+				items = append(items, f.group(group, legato(v, j-1), legato(v, j+1)))
+			}
+		}
+
+		items = append(items, "|")
+		b.WriteString(strings.Join(items, " "))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// formatter keeps the state while writing a staff
+type formatter struct {
+	// last is the duration of the previous note
+	last note.Duration
+}
+
+// group returns a note or a chord. prev and next tell if the groups around
+// it are legato, to open and close the slurs
+func (f *formatter) group(group []note.Note, prev, next bool) string {
+	var b strings.Builder
+
+	// rests can't be written in chords
+	if notes := score.PitchedNotes(group); len(notes) == 1 {
+		b.WriteString(f.note(notes[0], false, false))
+	} else {
+		same := true
+		for _, n := range notes {
+			same = same && n.Duration == notes[0].Duration
+		}
+
+		b.WriteString("<")
+		for i, n := range notes {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(f.note(n, true, !same))
+		}
+		b.WriteString(">")
+
+		if same {
+			b.WriteString(f.duration(notes[0].Duration))
+		}
+	}
+
+	// Dynamics and hairpins are written once for the group
+	for _, n := range group {
+		if n.Dynamic != note.NoDynamic {
+			b.WriteString(`\` + n.Dynamic.String())
+			break
+		}
+	}
+	for _, n := range group {
+		if n.Hairpin != note.NoHairpin {
+			b.WriteString(`\` + n.Hairpin.String())
+			break
+		}
+	}
+
+	if score.IsLegato(group) {
+		if !prev {
+			b.WriteString("(")
+		}
+		if !next {
+			b.WriteString(")")
+		}
+	}
+
+	return b.String()
+}
+
+// note returns a pitch or rest with its duration, articulations and tie.
+// inChord is true for the notes of a chord, and mixed if the chord has notes
+// with different durations. Otherwise the duration is written after the chord
+func (f *formatter) note(n note.Note, inChord, mixed bool) string {
+	var b strings.Builder
+	if s, ok := note.Spell(n.Pitch); ok {
+		b.WriteString(lilypond.PitchName(s))
+	} else {
+		b.WriteString("r")
+	}
+
+	switch {
+	case !inChord:
+		b.WriteString(f.duration(n.Duration))
+	case mixed:
+		// durations inside a chord do not change the previous one
+		b.WriteString(lilypond.Duration(n.Duration))
+	}
+	if n.Tie {
+		b.WriteString("~")
+	}
+	b.WriteString(lilypond.Articulations(n.Articulation))
+	return b.String()
+}
+
+// duration returns the note value, dots and scaling factor, or an empty
+// string if it is the same as the previous duration
+func (f *formatter) duration(d note.Duration) string {
+	if d == f.last {
+		return ""
+	}
+	f.last = d
+	return lilypond.Duration(d)
+}
+
+// legato returns true if the group at index i exists and is legato
+func legato(v score.Voice, i int) bool {
+	return i >= 0 && i < len(v) && score.IsLegato(v[i])
+}
@@ -0,0 +1,66 @@
+package text_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/text"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	staff := score.NewStaff(score.CommonTime,
+		[][]note.Note{
+			{note.NewNote(note.E6, note.Quarter).WithDynamic(note.MezzoPiano)},
+			{note.NewNote(note.E5, note.Eighth)},
+			{note.NewNote(note.Asharp4, note.Eighth).Articulate(note.Staccato)},
+			{note.NewRest(note.Quarter)},
+			{note.NewNote(note.C4, note.Quarter), note.NewNote(note.G4, note.Quarter)},
+		},
+		[][]note.Note{
+			{note.NewNote(note.E5, note.Eighth.Triplet()).Articulate(note.Legato).Crescendo()},
+			{note.NewNote(note.D5, note.Eighth.Triplet()).Articulate(note.Legato)},
+			{note.NewNote(note.C5, note.Eighth.Triplet()).Articulate(note.Legato)},
+			{note.NewNote(note.C3, note.Half.Dotted()).Tied(), note.NewNote(note.E3, note.Quarter)},
+		},
+	)
+	staff = append(staff, score.NewPolyphonicMeasure(score.TimeSignature{Beats: 3, Value: 4},
+		score.Voice{{note.NewNote(note.C3, note.Half.Dotted()).WithDynamic(note.Forte)}},
+		score.Voice{{note.NewNote(note.G2, note.Half)}, {note.NewNote(note.G2, note.Quarter)}},
+	))
+
+	assert.Equal(t, `e'''\mp e''8 ais'-. r4 <c' g'> |
+e''8*2/3\<( d'' c'') <c2.~ e4> |
+\time 3/4 c2.\f \\ g,2 g,4 |
+`, text.Format(staff))
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		`e''4 b'8 r fis,8. bes'16 aes, es eis' ceses'' <c' e' g'>2 |`,
+		`c4 c1 c2.. c8*2/3 c c16*4/5 c1*2 <c e2> c |`,
+		`\time 3/4 e''4 d'' c'' \\ c'2. | g'2. | \time 2/4 c'2 |`,
+		`c'4~\p\< c'4 d'8-.-> e'--( f' g'-^) <c'~ e'>4\f <d'-. f'>2 c'1() |`,
+	} {
+		staff, err := text.ParseStaff(strings.NewReader(s))
+		require.NoError(t, err, s)
+
+		formatted := text.Format(staff)
+		again, err := text.ParseStaff(strings.NewReader(formatted))
+		require.NoError(t, err, formatted)
+		assert.Equal(t, staff, again, formatted)
+	}
+
+	// Rests are dropped from chords
+	staff := score.NewStaff(score.CommonTime,
+		[][]note.Note{{note.NewRest(note.Whole), note.NewNote(note.C4, note.Whole)}},
+		[][]note.Note{{note.NewRest(note.Whole), note.NewRest(note.Whole)}},
+	)
+	formatted := text.Format(staff)
+	assert.Equal(t, "c'1 |\nr |\n", formatted)
+	_, err := text.ParseStaff(strings.NewReader(formatted))
+	assert.NoError(t, err, formatted)
+}
@@ -0,0 +1,499 @@
+package text
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// SyntaxError is returned for text that can't be parsed, with the position
+// of the problem
+type SyntaxError struct {
+	// Line and Column start at 1. Columns count characters, not bytes
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error returns the position and description of the error
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// ParseStaff reads a staff written in a compact text notation, inspired by
+// LilyPond:
+//
+//	\time 3/4  e''4 b'8 r8 <c' e' g'>4 | c''2.~ | c''4 d''8( e'') f''4-. \p\< |
+//
+// Notes are a letter from a to g, an accidental, an octave and a duration.
+// Sharps are written "is" and flats "es" (or "s" after a and e), e.g. "fis"
+// or "bes". Without octave marks the note is in octave 3, each ' is an octave
+// higher and each , an octave lower, so c' is C4. Rests are written r.
+//
+// Durations are the note value (1, 2, 4, 8, 16, 32, 64 or 128) followed by
+// dots, and an optional scaling factor for tuplets, e.g. 8*2/3 for a triplet
+// eighth. Notes without a duration have the same as the previous one, and
+// the first one is a quarter.
+//
+// Chords are notes between < and >, with the duration after >. Notes inside
+// a chord can have their own duration, which does not change the previous
+// one. Notes can be followed by ~ for a tie, the articulations -. (staccato),
+// -- (tenuto), -> (accent) and -^ (marcato), the dynamics \ppp to \fff and
+// the hairpins \< and \> (\! is accepted and ignored). Notes between ( and )
+// are played legato.
+//
+// Measures are separated by |, and independent voices in a measure by \\.
+// \time sets the time signature of the measure and the ones after it, 4/4 by
+// default. Comments start with % and end with the line
+func ParseStaff(r io.Reader) (score.Staff, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		src:  []rune(string(b)),
+		line: 1,
+		col:  1,
+		last: note.Quarter,
+		time: score.CommonTime,
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.staff, nil
+}
+
+// parser keeps the state while reading a staff
+type parser struct {
+	src       []rune
+	pos       int
+	line, col int
+
+	// last is the duration of the previous note
+	last note.Duration
+	// slur is true between ( and )
+	slur bool
+	time score.TimeSignature
+
+	staff  score.Staff
+	voices []score.Voice
+	// target are the notes the next marks apply to, the last note or chord
+	target []note.Note
+}
+
+// errorf returns a SyntaxError at the given position
+func errorf(line, col int, format string, a ...interface{}) error {
+	return &SyntaxError{Line: line, Column: col, Msg: fmt.Sprintf(format, a...)}
+}
+
+// errorf returns a SyntaxError at the current position
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return errorf(p.line, p.col, format, a...)
+}
+
+// peek returns the current character, or 0 at the end
+func (p *parser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// peekAt returns the character at an offset from the current one, or 0
+func (p *parser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+// next consumes the current character
+func (p *parser) next() rune {
+	r := p.peek()
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+// skipSpace consumes white space and comments
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) {
+		r := p.peek()
+		switch {
+		case r == '%':
+			for p.pos < len(p.src) && p.peek() != '\n' {
+				p.next()
+			}
+		case unicode.IsSpace(r):
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) parse() error {
+	p.voices = []score.Voice{nil}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		line, col := p.line, p.col
+		r := p.peek()
+		switch {
+		case r == '|':
+			p.next()
+			p.endMeasure()
+		case r == '\\':
+			if err := p.command(); err != nil {
+				return err
+			}
+		case r == '<':
+			if err := p.chord(); err != nil {
+				return err
+			}
+		case r == 'r' || (r >= 'a' && r <= 'g'):
+			n, explicit, err := p.note()
+			if err != nil {
+				return err
+			}
+			if !explicit {
+				n.Duration = p.last
+			}
+			p.addGroup([]note.Note{n})
+		case r == '~' || r == '-' || r == '(' || r == ')':
+			if err := p.mark(); err != nil {
+				return err
+			}
+		default:
+			return errorf(line, col, "unexpected %q", r)
+		}
+	}
+
+	if len(p.voices) > 1 || len(p.voices[0]) > 0 {
+		p.endMeasure()
+	}
+	return nil
+}
+
+// endMeasure adds the current measure to the staff
+func (p *parser) endMeasure() {
+	p.staff = append(p.staff, score.NewPolyphonicMeasure(p.time, p.voices...))
+	p.voices = []score.Voice{nil}
+	p.target = nil
+}
+
+// addGroup adds a group of simultaneous notes to the current voice
+func (p *parser) addGroup(group []note.Note) {
+	v := &p.voices[len(p.voices)-1]
+	*v = append(*v, group)
+	p.target = group
+}
+
+// command parses the commands starting with \
+func (p *parser) command() error {
+	line, col := p.line, p.col
+	p.next()
+
+	switch p.peek() {
+	case '\\':
+		p.next()
+		p.voices = append(p.voices, nil)
+		p.target = nil
+		return nil
+	case '<', '>', '!':
+		return p.hairpin(line, col, p.next())
+	}
+
+	var name strings.Builder
+	for unicode.IsLetter(p.peek()) {
+		name.WriteRune(p.next())
+	}
+
+	if name.String() == "time" {
+		return p.timeSignature()
+	}
+
+	d, err := note.ParseDynamic(name.String())
+	if err != nil {
+		return errorf(line, col, "unknown command \\%s", name.String())
+	}
+	if p.target == nil {
+		return errorf(line, col, "dynamic \\%v must follow a note", d)
+	}
+	p.target[0].Dynamic = d
+	return nil
+}
+
+// hairpin applies \<, \> or \! to the last note
+func (p *parser) hairpin(line, col int, r rune) error {
+	if p.target == nil {
+		return errorf(line, col, "hairpin \\%c must follow a note", r)
+	}
+	switch r {
+	case '<':
+		p.target[0].Hairpin = note.Crescendo
+	case '>':
+		p.target[0].Hairpin = note.Diminuendo
+	}
+	return nil
+}
+
+// timeSignature parses the argument of \time
+func (p *parser) timeSignature() error {
+	p.skipSpace()
+	line, col := p.line, p.col
+
+	var arg strings.Builder
+	for r := p.peek(); r != 0 && !unicode.IsSpace(r) && r != '|'; r = p.peek() {
+		arg.WriteRune(p.next())
+	}
+
+	t, err := score.ParseTimeSignature(arg.String())
+	if err != nil {
+		return errorf(line, col, "wrong time signature %q", arg.String())
+	}
+	p.time = t
+	return nil
+}
+
+// chord parses notes between < and >
+func (p *parser) chord() error {
+	line, col := p.line, p.col
+	p.next()
+
+	var chord []note.Note
+	var explicit []bool
+	last := p.last
+	for {
+		p.skipSpace()
+		r := p.peek()
+		switch {
+		case r == '>':
+			p.next()
+			if len(chord) == 0 {
+				return errorf(line, col, "empty chord")
+			}
+
+			// durations of the notes inside do not change the previous one
+			p.last = last
+			d, ok, err := p.duration()
+			if err != nil {
+				return err
+			}
+			if ok {
+				p.last = d
+			}
+			for i := range chord {
+				if !explicit[i] {
+					chord[i].Duration = p.last
+				}
+			}
+			p.addGroup(chord)
+			return nil
+		case r >= 'a' && r <= 'g':
+			n, e, err := p.note()
+			if err != nil {
+				return err
+			}
+			chord = append(chord, n)
+			explicit = append(explicit, e)
+			p.target = chord[len(chord)-1:]
+		case r == '~' || r == '-':
+			if p.target == nil || len(chord) == 0 {
+				return p.errorf("unexpected %q", r)
+			}
+			if err := p.mark(); err != nil {
+				return err
+			}
+		case r == 0:
+			return errorf(line, col, "chord is not closed with >")
+		default:
+			return p.errorf("unexpected %q in chord", r)
+		}
+	}
+}
+
+// note parses a note or a rest. explicit is false if it has no duration
+func (p *parser) note() (n note.Note, explicit bool, err error) {
+	line, col := p.line, p.col
+
+	if p.peek() == 'r' {
+		p.next()
+		n = note.NewRest(0)
+	} else {
+		pitch := p.pitch()
+		if pitch.Key() < note.MinKey || pitch.Key() > note.MaxKey {
+			return n, false, errorf(line, col, "%v is outside of the range C-1 to G9", pitch)
+		}
+		n = note.NewNote(pitch, 0)
+		if p.slur {
+			n.Articulation |= note.Legato
+		}
+	}
+
+	if unicode.IsLetter(p.peek()) {
+		return n, false, p.errorf("unexpected %q after note", p.peek())
+	}
+
+	n.Duration, explicit, err = p.duration()
+	if explicit {
+		p.last = n.Duration
+	}
+	return n, explicit, err
+}
+
+// pitch parses a letter, accidentals and octave marks
+func (p *parser) pitch() note.SpelledPitch {
+	r := p.next()
+	l := note.Letter((r - 'a' + 5) % 7)
+
+	var a note.Accidental
+	if (r == 'a' || r == 'e') && p.peek() == 's' {
+		p.next()
+		a--
+	}
+	for {
+		if p.peek() == 'i' && p.peekAt(1) == 's' {
+			a++
+		} else if p.peek() == 'e' && p.peekAt(1) == 's' {
+			a--
+		} else {
+			break
+		}
+		p.next()
+		p.next()
+	}
+
+	octave := 3
+	for {
+		switch p.peek() {
+		case '\'':
+			octave++
+		case ',':
+			octave--
+		default:
+			return note.NewSpelledPitch(l, a, octave)
+		}
+		p.next()
+	}
+}
+
+// duration parses a note value, dots and a scaling factor. ok is false if
+// there is no duration
+func (p *parser) duration() (d note.Duration, ok bool, err error) {
+	line, col, start := p.line, p.col, p.pos
+
+	value, ok := p.number()
+	if !ok {
+		if p.peek() == '.' || p.peek() == '*' {
+			return 0, false, p.errorf("%q without a note value", p.peek())
+		}
+		return 0, false, nil
+	}
+	if value < 1 || value > 128 || value&(value-1) != 0 {
+		return 0, false, errorf(line, col, "wrong note value %s, use 1, 2, 4, 8, 16, 32, 64 or 128",
+			string(p.src[start:p.pos]))
+	}
+	d = note.Whole / note.Duration(value)
+
+	dots := 0
+	for p.peek() == '.' {
+		p.next()
+		dots++
+	}
+	d = d.Dots(dots)
+
+	if p.peek() == '*' {
+		p.next()
+		line, col := p.line, p.col
+		num, ok := p.number()
+		if !ok || num == 0 {
+			return 0, false, errorf(line, col, "wrong scaling factor")
+		}
+		den := 1
+		if p.peek() == '/' {
+			p.next()
+			line, col := p.line, p.col
+			if den, ok = p.number(); !ok || den == 0 {
+				return 0, false, errorf(line, col, "wrong scaling factor")
+			}
+		}
+		d = d.Tuplet(den, num)
+	}
+
+	return d, true, nil
+}
+
+// number parses a positive integer. ok is false if there are no digits, and
+// the number is 0 if the digits overflow an int
+func (p *parser) number() (n int, ok bool) {
+	start := p.pos
+	for p.peek() >= '0' && p.peek() <= '9' {
+		p.next()
+	}
+	if p.pos == start {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(p.src[start:p.pos]))
+	if err != nil {
+		return 0, true
+	}
+	return n, true
+}
+
+// articulations are the symbols written after -
+var articulations = map[rune]note.Articulation{
+	'.': note.Staccato,
+	'-': note.Tenuto,
+	'>': note.Accent,
+	'^': note.Marcato,
+}
+
+// mark applies a tie, articulation or slur to the last note or chord
+func (p *parser) mark() error {
+	line, col := p.line, p.col
+	r := p.next()
+	if p.target == nil {
+		return errorf(line, col, "%q must follow a note", r)
+	}
+
+	switch r {
+	case '~':
+		for i := range p.target {
+			p.target[i].Tie = true
+		}
+	case '-':
+		a, ok := articulations[p.peek()]
+		if !ok {
+			return errorf(line, col, "unknown articulation %q", "-"+string(p.peek()))
+		}
+		p.next()
+		for i := range p.target {
+			p.target[i].Articulation |= a
+		}
+	case '(', ')':
+		for i := range p.target {
+			if p.target[i].Key() >= 0 {
+				p.target[i].Articulation |= note.Legato
+			}
+		}
+		p.slur = r == '('
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package text_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/format/text"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, s string) score.Staff {
+	t.Helper()
+	staff, err := text.ParseStaff(strings.NewReader(s))
+	require.NoError(t, err)
+	return staff
+}
+
+func TestParseNotes(t *testing.T) {
+	staff := parse(t, `e''4 b'8 r fis,8. bes'16 aes, es eis' ceses'' <c' e' g'>2`)
+	require.Len(t, staff, 1)
+	require.Len(t, staff[0].Voices, 1)
+
+	sp := note.NewSpelledPitch
+	assert.Equal(t, score.Voice{
+		{note.NewNote(sp(note.E, note.Natural, 5), note.Quarter)},
+		{note.NewNote(sp(note.B, note.Natural, 4), note.Eighth)},
+		{note.NewRest(note.Eighth)},
+		{note.NewNote(sp(note.F, note.Sharp, 2), note.Eighth.Dotted())},
+		{note.NewNote(sp(note.B, note.Flat, 4), note.Sixteenth)},
+		{note.NewNote(sp(note.A, note.Flat, 2), note.Sixteenth)},
+		{note.NewNote(sp(note.E, note.Flat, 3), note.Sixteenth)},
+		{note.NewNote(sp(note.E, note.Sharp, 4), note.Sixteenth)},
+		{note.NewNote(sp(note.C, note.DoubleFlat, 5), note.Sixteenth)},
+		{
+			note.NewNote(sp(note.C, note.Natural, 4), note.Half),
+			note.NewNote(sp(note.E, note.Natural, 4), note.Half),
+			note.NewNote(sp(note.G, note.Natural, 4), note.Half),
+		},
+	}, staff[0].Voices[0])
+}
+
+func TestParseDurations(t *testing.T) {
+	v := parse(t, `c c1 c2.. c8*2/3 c c16*4/5 c1*2 <c e2> c`)[0].Voices[0]
+
+	var durations []note.Duration
+	for _, g := range v {
+		for _, n := range g {
+			durations = append(durations, n.Duration)
+		}
+	}
+	assert.Equal(t, []note.Duration{
+		note.Quarter, note.Whole, note.Half.DoubleDotted(),
+		note.Eighth.Triplet(), note.Eighth.Triplet(), note.Sixteenth.Tuplet(5, 4),
+		note.Double,
+		note.Double, note.Half, note.Double,
+	}, durations)
+}
+
+func TestParseMeasures(t *testing.T) {
+	staff := parse(t, `
+		% Two voices in the first bar
+		\time 3/4 e''4 d'' c'' \\ c'2. |
+		g'2. |
+		\time 2/4
+		c'2 |
+	`)
+	require.Len(t, staff, 3)
+	assert.NoError(t, staff.Validate())
+
+	assert.Equal(t, score.TimeSignature{Beats: 3, Value: 4}, staff[0].Time)
+	assert.Equal(t, score.TimeSignature{Beats: 3, Value: 4}, staff[1].Time)
+	assert.Equal(t, score.TimeSignature{Beats: 2, Value: 4}, staff[2].Time)
+
+	require.Len(t, staff[0].Voices, 2)
+	assert.Len(t, staff[0].Voices[0], 3)
+	assert.Len(t, staff[0].Voices[1], 1)
+	assert.Len(t, staff[1].Voices, 1)
+
+	assert.Empty(t, parse(t, ""))
+	assert.Empty(t, parse(t, "% nothing"))
+}
+
+func TestParseMarks(t *testing.T) {
+	v := parse(t, `c'4~\p\< c'4 d'8-.-> e'--( f' g'-^) <c' e'>4~\f`)[0].Voices[0]
+	require.Len(t, v, 7)
+
+	assert.True(t, v[0][0].Tie)
+	assert.Equal(t, note.Piano, v[0][0].Dynamic)
+	assert.Equal(t, note.Crescendo, v[0][0].Hairpin)
+	assert.False(t, v[1][0].Tie)
+
+	assert.Equal(t, note.Staccato|note.Accent, v[2][0].Articulation)
+	assert.Equal(t, note.Tenuto|note.Legato, v[3][0].Articulation)
+	assert.Equal(t, note.Legato, v[4][0].Articulation)
+	assert.Equal(t, note.Marcato|note.Legato, v[5][0].Articulation)
+
+	assert.True(t, v[6][0].Tie)
+	assert.True(t, v[6][1].Tie)
+	assert.Equal(t, note.Forte, v[6][0].Dynamic)
+	assert.Equal(t, note.NoDynamic, v[6][1].Dynamic)
+	assert.Equal(t, note.Articulation(0), v[6][0].Articulation)
+}
+
+func TestParseErrors(t *testing.T) {
+	for s, expected := range map[string]string{
+		`c4 h4`:           `line 1, column 4: unexpected 'h'`,
+		"c4 d4 |\n  e4 x": `line 2, column 6: unexpected 'x'`,
+		`c3`:              `line 1, column 2: wrong note value 3, use 1, 2, 4, 8, 16, 32, 64 or 128`,
+		`c4*0`:            `line 1, column 4: wrong scaling factor`,
+		`c.`:              `line 1, column 2: '.' without a note value`,
+		`cx`:              `line 1, column 2: unexpected 'x' after note`,
+		`c'''''''`:        `line 1, column 1: C10 is outside of the range C-1 to G9`,
+		`<c e`:            `line 1, column 1: chord is not closed with >`,
+		`<>`:              `line 1, column 1: empty chord`,
+		`<c r>`:           `line 1, column 4: unexpected 'r' in chord`,
+		`c4-x`:            `line 1, column 3: unknown articulation "-x"`,
+		`~ c4`:            `line 1, column 1: '~' must follow a note`,
+		`\p c4`:           `line 1, column 1: dynamic \p must follow a note`,
+		`\< c4`:           `line 1, column 1: hairpin \< must follow a note`,
+		`c4 \foo`:         `line 1, column 4: unknown command \foo`,
+		"\\time 3/5 c4":   `line 1, column 7: wrong time signature "3/5"`,
+		"c4 |\n\t\\p":     `line 2, column 2: dynamic \p must follow a note`,
+
+		// Numbers that overflow an int
+		`c9999999999999999999`:     `line 1, column 2: wrong note value 9999999999999999999, use 1, 2, 4, 8, 16, 32, 64 or 128`,
+		`c4*3/9999999999999999999`: `line 1, column 6: wrong scaling factor`,
+	} {
+		_, err := text.ParseStaff(strings.NewReader(s))
+		if assert.Error(t, err, s) {
+			assert.Equal(t, expected, err.Error(), s)
+			_, ok := err.(*text.SyntaxError)
+			assert.True(t, ok, s)
+		}
+	}
+}
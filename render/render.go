@@ -0,0 +1,157 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/carlosms/music-playground/synth"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+)
+
+// Renderer turns a score into a single Reader of int16 samples, mixing all
+// its parts, staves and voices
+type Renderer struct {
+	SampleRate int
+	// Waves are the waves used to play each instrument, by instrument name
+	Waves map[string]synth.WaveGenerator
+	// Wave is used for the instruments not found in Waves
+	Wave synth.WaveGenerator
+	// Volume of the loudest notes, a value between 0 and 1. Softer dynamics
+	// are scaled down by their MIDI velocity
+	Volume float64
+}
+
+// Score returns a Reader with the samples of all the voices of the score
+// played at the same time
+func (r Renderer) Score(s score.Score) io.Reader {
+	readers := []io.Reader{}
+	for _, p := range s.Parts {
+		wave := r.wave(p.Instrument)
+		for _, staff := range p.Staves {
+			for _, v := range staff.Voices() {
+				readers = append(readers, r.Voice(wave, s.Tempo, v))
+			}
+		}
+	}
+
+	if len(readers) == 0 {
+		return strings.NewReader("")
+	}
+	return synth.Combine(readers...)
+}
+
+// Voice returns a Reader with the samples of the groups of simultaneous notes
+// of a voice, played one after another following the tempo map. Dynamics and
+// articulations change the volume, length and envelope of each note. Tied
+// notes are played as a single one
+func (r Renderer) Voice(wave synth.WaveGenerator, tempo score.TempoMap, voice score.Voice) io.Reader {
+	readers := []io.Reader{}
+	velocities := voice.Velocities()
+
+	// Each group is placed at its exact position in the voice, so rounding
+	// each group to whole samples does not accumulate over the piece
+	var pos note.Rational
+	tied := map[int]io.Reader{}
+	for i, notes := range voice {
+		groupReaders := []io.Reader{}
+		continued := map[int]io.Reader{}
+
+		// the next group starts when the longest note ends
+		var longest note.Duration
+		for j, n := range notes {
+			if n.Duration > longest {
+				longest = n.Duration
+			}
+			// rests are the silence Fit adds after the notes
+			if n.Key() < 0 {
+				continue
+			}
+
+			// a note tied from the previous group keeps reading the samples of
+			// the first one, that were cut by the group length
+			w, ok := tied[n.Key()]
+			if !ok {
+				d := played(tempo, voice, i, pos, n)
+				w = wave(r.SampleRate, n.Frequency(), d)
+				attack, release := envelope(n.Articulation)
+				w = synth.Envelope(w, r.samples(d), r.samples(attack), r.samples(release))
+				volume := r.Volume * float64(velocities[i][j]) / 127
+				w = synth.Sustain(w, volume)
+			}
+			if _, ok := tiedNote(voice, i, n); ok {
+				continued[n.Key()] = w
+			}
+			groupReaders = append(groupReaders, w)
+		}
+
+		start := tempo.Samples(pos, r.SampleRate)
+		pos = pos.Add(longest.Rational())
+		end := tempo.Samples(pos, r.SampleRate)
+
+		readers = append(readers, synth.Fit(synth.Combine(groupReaders...), end-start))
+		tied = continued
+	}
+
+	return io.MultiReader(readers...)
+}
+
+// played returns how long note n of group i, that starts at pos, sounds. It
+// is held until the end of the last note it is tied to
+func played(tempo score.TempoMap, voice score.Voice, i int, pos note.Rational, n note.Note) time.Duration {
+	last, lastPos := n, pos
+	for k := i; ; k++ {
+		next, ok := tiedNote(voice, k, last)
+		if !ok {
+			break
+		}
+		lastPos = lastPos.Add(score.Voice{voice[k]}.Duration())
+		last = next
+	}
+
+	written := tempo.Time(lastPos.Add(last.Duration.Rational())) - tempo.Time(lastPos)
+	return tempo.Time(lastPos) - tempo.Time(pos) + time.Duration(float64(written)*last.Articulation.Length())
+}
+
+// tiedNote returns the note of the group after group i that n, a note of
+// group i, is tied to
+func tiedNote(voice score.Voice, i int, n note.Note) (note.Note, bool) {
+	if !n.Tie || i+1 >= len(voice) {
+		return note.Note{}, false
+	}
+	for _, next := range voice[i+1] {
+		if next.Key() == n.Key() {
+			return next, true
+		}
+	}
+	return note.Note{}, false
+}
+
+// envelope returns the fade in and fade out of a note with the given
+// articulation. Accents have a sharper attack, and legato notes a softer one
+// with a short release to connect them to the next note
+func envelope(a note.Articulation) (attack, release time.Duration) {
+	switch {
+	case a.Has(note.Accent), a.Has(note.Marcato):
+		return time.Millisecond, 20 * time.Millisecond
+	case a.Has(note.Legato):
+		return 30 * time.Millisecond, 5 * time.Millisecond
+	case a.Has(note.Staccato):
+		return 2 * time.Millisecond, 10 * time.Millisecond
+	}
+	return 5 * time.Millisecond, 20 * time.Millisecond
+}
+
+// samples returns the number of samples of a duration, rounded down
+func (r Renderer) samples(d time.Duration) int64 {
+	return int64(float64(r.SampleRate) * d.Seconds())
+}
+
+// wave returns the wave generator for the instrument
+func (r Renderer) wave(i score.Instrument) synth.WaveGenerator {
+	if w, ok := r.Waves[i.Name]; ok {
+		return w
+	}
+	return r.Wave
+}
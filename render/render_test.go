@@ -0,0 +1,145 @@
+package render_test
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/carlosms/music-playground/render"
+	"github.com/carlosms/music-playground/synth"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScore(t *testing.T) {
+	triplet := []note.Note{note.NewNote(note.E5, note.Eighth.Triplet())}
+
+	s := score.Score{
+		Title: "Test",
+		Tempo: score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 120}),
+		Parts: []score.Part{
+			{
+				Name:       "Piano",
+				Instrument: score.Instrument{Name: "Piano"},
+				Staves: []score.Staff{
+					score.NewStaff(score.CommonTime,
+						[][]note.Note{triplet, triplet, triplet, {note.NewNote(note.D5, note.Half.Dotted())}},
+						[][]note.Note{{note.NewNote(note.C5, note.Whole)}},
+					),
+				},
+			},
+			{
+				Name:       "Bass",
+				Instrument: score.Instrument{Name: "Bass"},
+				Staves: []score.Staff{
+					score.NewStaff(score.CommonTime,
+						[][]note.Note{{note.NewNote(note.C3, note.Whole)}},
+					),
+				},
+			},
+		},
+	}
+	require.NoError(t, s.Validate())
+	assert.Equal(t, note.NewRational(2, 1), s.Duration())
+
+	r := render.Renderer{
+		SampleRate: 1000,
+		Wave:       synth.NewSineWave,
+		Waves:      map[string]synth.WaveGenerator{"Bass": synth.NewSquareWave},
+		Volume:     0.2,
+	}
+
+	// 2 bars of 4/4 at ♩ = 120 last 4 seconds, 2 bytes per sample
+	b, err := ioutil.ReadAll(r.Score(s))
+	require.NoError(t, err)
+	assert.Len(t, b, 4*1000*2)
+
+	b, err = ioutil.ReadAll(r.Score(score.Score{}))
+	require.NoError(t, err)
+	assert.Empty(t, b)
+}
+
+func TestVoice(t *testing.T) {
+	var played []time.Duration
+	wave := func(sampleRate int, freq float64, d time.Duration) io.Reader {
+		played = append(played, d)
+		return synth.NewSquareWave(sampleRate, freq, d)
+	}
+	r := render.Renderer{SampleRate: 1000, Wave: wave, Volume: 1}
+	tempo := score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 120})
+	c := note.NewNote(note.C5, note.Quarter)
+
+	// Tied notes are attacked once, and held for all their durations
+	b, err := ioutil.ReadAll(r.Voice(wave, tempo, score.Voice{{c.Tied()}, {c}, {c}}))
+	require.NoError(t, err)
+	assert.Len(t, b, 3*500*2)
+	assert.Equal(t, []time.Duration{time.Second, 500 * time.Millisecond}, played)
+
+	// Rests are silent, without the offset of a 0 Hz wave
+	played = nil
+	b, err = ioutil.ReadAll(r.Voice(wave, tempo, score.Voice{{note.NewRest(note.Quarter)}}))
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 500*2), b)
+	assert.Empty(t, played)
+}
+
+func TestArticulationsAndDynamics(t *testing.T) {
+	r := render.Renderer{SampleRate: 1000, Wave: synth.NewSquareWave, Volume: 1}
+	tempo := score.ConstantTempo(score.Tempo{Beat: note.Quarter, BPM: 120})
+	c := note.NewNote(note.C5, note.Quarter)
+
+	// Each quarter is 500 samples
+	samples := func(v score.Voice) []int16 {
+		b, err := ioutil.ReadAll(r.Voice(r.Wave, tempo, v))
+		require.NoError(t, err)
+		require.Len(t, b, len(v)*500*2)
+
+		s := make([]int16, len(b)/2)
+		for i := range s {
+			s[i] = int16(b[2*i]) + int16(b[2*i+1])<<8
+		}
+		return s
+	}
+	peak := func(s []int16) int16 {
+		var m int16
+		for _, v := range s {
+			if v > m {
+				m = v
+			}
+		}
+		return m
+	}
+
+	// Staccato notes are silent for the second half
+	s := samples(score.Voice{{c.Articulate(note.Staccato)}})
+	assert.NotZero(t, peak(s[:250]))
+	assert.Zero(t, peak(s[260:]))
+
+	s = samples(score.Voice{{c}})
+	assert.NotZero(t, peak(s[260:480]))
+
+	// Louder dynamics have a higher peak
+	s = samples(score.Voice{{c.WithDynamic(note.Pianissimo)}, {c.WithDynamic(note.Fortissimo)}})
+	assert.True(t, peak(s[:500]) < peak(s[500:]))
+
+	// Tied notes are attacked once, without fading out and in between them
+	quietest := func(s []int16) int16 {
+		m := peak(s)
+		for _, v := range s {
+			if v < 0 {
+				v = -v
+			}
+			if v < m {
+				m = v
+			}
+		}
+		return m
+	}
+	s = samples(score.Voice{{c.Tied()}, {c}})
+	assert.Equal(t, peak(s), quietest(s[480:520]))
+	s = samples(score.Voice{{c}, {c}})
+	assert.True(t, quietest(s[480:520]) < peak(s)/2)
+}
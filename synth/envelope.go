@@ -0,0 +1,53 @@
+package synth
+
+import (
+	"io"
+)
+
+// Envelope takes a Reader that returns nSamples int16 samples, and returns a
+// Reader that fades in linearly the first attack samples, and fades out
+// linearly the last release samples. It avoids the clicks of sounds that
+// start or stop suddenly
+func Envelope(r io.Reader, nSamples, attack, release int64) io.Reader {
+	return &EnvelopeReader{r: r, nSamples: nSamples, attack: attack, release: release}
+}
+
+// EnvelopeReader takes a Reader that returns int16 samples, and fades in and
+// out its first and last samples
+type EnvelopeReader struct {
+	r        io.Reader // underlying reader
+	nSamples int64
+	attack   int64
+	release  int64
+	pos      int64 // current sample
+}
+
+func (e *EnvelopeReader) Read(p []byte) (n int, err error) {
+	n, err = e.r.Read(p)
+
+	for i := 0; i < n-1; i += 2 {
+		// Convert 2 bytes to int16, little-endian
+		v := int16(p[i]) + int16(p[i+1])<<8
+
+		gain := 1.0
+		if e.pos < e.attack {
+			gain = float64(e.pos) / float64(e.attack)
+		}
+		if left := e.nSamples - e.pos; left < e.release {
+			if g := float64(left) / float64(e.release); g < gain {
+				gain = g
+			}
+		}
+		if gain < 0 {
+			gain = 0
+		}
+		v = int16(float64(v) * gain)
+
+		// int16 back to to 2 bytes, little-endian
+		p[i] = byte(v)
+		p[i+1] = byte(v >> 8)
+		e.pos++
+	}
+
+	return
+}
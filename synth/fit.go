@@ -0,0 +1,27 @@
+package synth
+
+import (
+	"io"
+)
+
+// Fit takes a Reader that returns int16 samples, and returns a Reader that
+// returns exactly nSamples samples. Longer readers are cut, and shorter ones
+// are padded with silence. It can be used to place sounds at exact sample
+// positions, without accumulating rounding errors
+func Fit(r io.Reader, nSamples int64) io.Reader {
+	// 2 bytes per sample
+	nBytes := nSamples * 2
+	return io.LimitReader(io.MultiReader(io.LimitReader(r, nBytes), silence{}), nBytes)
+}
+
+// silence is an endless io.Reader of equilibrium samples
+type silence struct{}
+
+func (silence) Read(p []byte) (int, error) {
+	for i := 0; i < len(p)-1; i += 2 {
+		// int16 to 2 bytes, little-endian
+		p[i] = byte(equilibrium)
+		p[i+1] = byte(equilibrium >> 8)
+	}
+	return len(p) - len(p)%2, nil
+}
@@ -44,6 +44,12 @@ func (m *CombinedReader) Read(p []byte) (int, error) {
 			}
 		}
 
+		// All the readers are finished, the samples read so far are the
+		// last ones
+		if eofErr == io.EOF {
+			break
+		}
+
 		// int16 back to to 2 bytes, little-endian
 		p[n] = byte(total)
 		p[n+1] = byte(total >> 8)
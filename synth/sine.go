@@ -38,11 +38,13 @@ func (s *SineWave) Read(buf []byte) (int, error) {
 		return 0, io.EOF
 	}
 
-	samplesPeriod := int64(float64(s.sampleRate) / s.freq)
+	// The period is not rounded to a number of samples, otherwise
+	// frequencies would be quantized and pitches out of tune
+	samplesPeriod := float64(s.sampleRate) / s.freq
 
 	var i int
 	for i = 0; i < len(buf)/2 && s.offset < s.nSamples; i += 2 {
-		radian := float64(s.offset) / float64(samplesPeriod) * 2 * math.Pi
+		radian := float64(s.offset) / samplesPeriod * 2 * math.Pi
 		value := equilibrium + int16(float64(max)*math.Sin(radian))
 
 		// int16 to 2 bytes, little-endian
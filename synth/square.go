@@ -2,6 +2,7 @@ package synth
 
 import (
 	"io"
+	"math"
 	"time"
 )
 
@@ -37,12 +38,14 @@ func (s *SquareWave) Read(buf []byte) (int, error) {
 		return 0, io.EOF
 	}
 
-	samplesPeriod := int64(float64(s.sampleRate) / s.freq)
+	// The period is not rounded to a number of samples, otherwise
+	// frequencies would be quantized and pitches out of tune
+	samplesPeriod := float64(s.sampleRate) / s.freq
 	samplesHalfPeriod := samplesPeriod / 2
 
 	var i int
 	for i = 0; i < len(buf)/2 && s.offset < s.nSamples; i += 2 {
-		pos := s.offset % samplesPeriod
+		pos := math.Mod(float64(s.offset), samplesPeriod)
 
 		var value int16
 		if pos <= samplesHalfPeriod {
@@ -0,0 +1,55 @@
+package chord
+
+import (
+	"fmt"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Alteration is a chord degree raised or lowered a semitone, e.g. the b5 in
+// C7(b5) or the #9 in G7(#9)
+type Alteration struct {
+	// Degree is the altered chord degree: 5, 9, 11 or 13
+	Degree int
+	// Sharp is true for raised degrees, false for lowered ones
+	Sharp bool
+}
+
+// Common alterations
+var (
+	Flat5   = Alteration{5, false}
+	Sharp5  = Alteration{5, true}
+	Flat9   = Alteration{9, false}
+	Sharp9  = Alteration{9, true}
+	Sharp11 = Alteration{11, true}
+	Flat13  = Alteration{13, false}
+)
+
+// naturals are the unaltered intervals for each degree that can be altered
+var naturals = map[int]note.Interval{
+	5:  perfect5th,
+	9:  major9th,
+	11: perfect11th,
+	13: major13th,
+}
+
+// natural returns the unaltered interval above the root for this degree
+func (a Alteration) natural() note.Interval {
+	return naturals[a.Degree]
+}
+
+// Interval returns the altered interval above the root
+func (a Alteration) Interval() note.Interval {
+	if a.Sharp {
+		return a.natural() + note.Semitone
+	}
+	return a.natural() - note.Semitone
+}
+
+// String returns the alteration symbol, e.g. "#9"
+func (a Alteration) String() string {
+	if a.Sharp {
+		return fmt.Sprintf("#%d", a.Degree)
+	}
+	return fmt.Sprintf("b%d", a.Degree)
+}
@@ -0,0 +1,133 @@
+package chord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Chord is a group of pitches built on a root, e.g. C major seventh
+type Chord struct {
+	// Root is the note the chord is built on
+	Root note.Pitch
+	// Quality defines the intervals of the chord notes above the root
+	Quality Quality
+	// Alterations are raised or lowered fifths and extensions, e.g. the #9
+	// in G7(#9)
+	Alterations []Alteration
+	// Bass is the lowest note, for slash chords like D/F#. A nil Bass means
+	// the root is the lowest note
+	Bass note.Pitch
+}
+
+// New returns a Chord with the given root, quality and alterations
+func New(root note.Pitch, q Quality, alterations ...Alteration) Chord {
+	return Chord{
+		Root:        root,
+		Quality:     q,
+		Alterations: alterations,
+	}
+}
+
+// Over returns a copy of the chord with the given pitch in the bass, e.g.
+// New(note.D4, Major).Over(note.Fsharp3) is D/F#
+func (c Chord) Over(bass note.Pitch) Chord {
+	c.Bass = bass
+	return c
+}
+
+// Intervals returns the distances of each chord note to the root, with the
+// alterations applied, in ascending order. The bass note is not included
+func (c Chord) Intervals() []note.Interval {
+	intervals := make([]note.Interval, len(c.Quality.Intervals))
+	copy(intervals, c.Quality.Intervals)
+
+	for _, a := range c.Alterations {
+		natural := a.natural()
+		altered := a.Interval()
+
+		replaced := false
+		for i, v := range intervals {
+			if v == natural {
+				intervals[i] = altered
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			intervals = append(intervals, altered)
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals
+}
+
+// Pitches returns the chord notes in ascending order. For slash chords the
+// bass is the first pitch, placed below the root
+func (c Chord) Pitches() []note.Pitch {
+	var pitches []note.Pitch
+	if c.Bass != nil {
+		pitches = append(pitches, bassBelow(c.Root, c.Bass))
+	}
+
+	for _, i := range c.Intervals() {
+		pitches = append(pitches, c.Root.Add(i))
+	}
+
+	return pitches
+}
+
+// bassBelow returns a pitch with the same name as bass, in the closest
+// octave below root. Spelled bass pitches keep their spelling
+func bassBelow(root, bass note.Pitch) note.Pitch {
+	octave := int(note.Octave)
+	distance := ((root.Key()-bass.Key())%octave + octave) % octave
+	if distance == 0 {
+		distance = octave
+	}
+
+	p := root.Subtract(note.Interval(distance))
+	if s, ok := bass.(note.SpelledPitch); ok {
+		s.Octave += (p.Key() - s.Key()) / octave
+		return s
+	}
+
+	return p
+}
+
+// Symbol returns the canonical chord symbol, e.g. "F#m7b5" or "G7(#9)"
+func (c Chord) Symbol() string {
+	var b strings.Builder
+	b.WriteString(pitchName(c.Root))
+	b.WriteString(c.Quality.Symbol)
+
+	if len(c.Alterations) > 0 {
+		names := make([]string, len(c.Alterations))
+		for i, a := range c.Alterations {
+			names[i] = a.String()
+		}
+		fmt.Fprintf(&b, "(%s)", strings.Join(names, ","))
+	}
+
+	if c.Bass != nil {
+		b.WriteString("/")
+		b.WriteString(pitchName(c.Bass))
+	}
+
+	return b.String()
+}
+
+// String returns the canonical chord symbol
+func (c Chord) String() string {
+	return c.Symbol()
+}
+
+// pitchName returns the pitch name without the octave number, e.g. "F#" for
+// F#5
+func pitchName(p note.Pitch) string {
+	return strings.TrimRight(p.String(), "-0123456789")
+}
@@ -0,0 +1,140 @@
+package chord_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/chord"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPitches(t *testing.T) {
+	assert.Equal(t,
+		[]note.Pitch{note.C4, note.E4, note.G4},
+		chord.New(note.C4, chord.Major).Pitches())
+
+	assert.Equal(t,
+		[]note.Pitch{note.Fsharp3, note.A3, note.C4, note.E4},
+		chord.New(note.Fsharp3, chord.HalfDiminished7).Pitches())
+
+	assert.Equal(t,
+		[]note.Pitch{note.G3, note.B3, note.D4, note.F4, note.Asharp4},
+		chord.New(note.G3, chord.Dominant7, chord.Sharp9).Pitches())
+
+	assert.Equal(t,
+		[]note.Pitch{note.C4, note.E4, note.Fsharp4, note.Asharp4, note.Csharp5},
+		chord.New(note.C4, chord.Dominant7, chord.Flat5, chord.Flat9).Pitches())
+
+	assert.Equal(t,
+		[]note.Pitch{note.D4, note.Fsharp4, note.A4, note.C5, note.E5, note.B5},
+		chord.New(note.D4, chord.Dominant13).Pitches())
+
+	assert.Equal(t,
+		[]note.Pitch{note.Fsharp3, note.D4, note.Fsharp4, note.A4},
+		chord.New(note.D4, chord.Major).Over(note.Fsharp5).Pitches())
+
+	assert.Equal(t,
+		[]note.Pitch{note.C3, note.C4, note.E4, note.G4},
+		chord.New(note.C4, chord.Major).Over(note.C4).Pitches())
+}
+
+func TestParse(t *testing.T) {
+	c, err := chord.Parse("F#m7b5")
+	require.NoError(t, err)
+	assert.Equal(t, note.NewSpelledPitch(note.F, note.Sharp, 4), c.Root)
+	assert.Equal(t, "m7b5", c.Quality.Symbol)
+	assert.Empty(t, c.Alterations)
+	assert.Nil(t, c.Bass)
+
+	c, err = chord.Parse("G7(#9)")
+	require.NoError(t, err)
+	assert.Equal(t, chord.New(note.NewSpelledPitch(note.G, note.Natural, 4), chord.Dominant7, chord.Sharp9), c)
+
+	c, err = chord.Parse("D/F#")
+	require.NoError(t, err)
+	assert.Equal(t, chord.New(note.NewSpelledPitch(note.D, note.Natural, 4), chord.Major).Over(note.NewSpelledPitch(note.F, note.Sharp, 4)), c)
+	assert.Equal(t, "F#3 D4 F#4 A4", names(c.Pitches()))
+
+	c, err = chord.Parse("Bb7")
+	require.NoError(t, err)
+	assert.Equal(t, note.NewSpelledPitch(note.B, note.Flat, 4), c.Root)
+	assert.Equal(t, "Bb4 D5 F5 Ab5", names(c.Pitches()))
+
+	c, err = chord.Parse("Cm7")
+	require.NoError(t, err)
+	assert.Equal(t, "C4 Eb4 G4 Bb4", names(c.Pitches()))
+
+	c, err = chord.Parse("Eb/Bb")
+	require.NoError(t, err)
+	assert.Equal(t, "Bb3 Eb4 G4 Bb4", names(c.Pitches()))
+
+	c, err = chord.Parse("C6/9")
+	require.NoError(t, err)
+	assert.Equal(t, chord.New(note.NewSpelledPitch(note.C, note.Natural, 4), chord.SixNine), c)
+}
+
+// names returns the pitch names separated by spaces
+func names(pitches []note.Pitch) string {
+	s := make([]string, len(pitches))
+	for i, p := range pitches {
+		s[i] = p.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{
+		"", "H7", "c", "Cxyz", "C7(#9", "C7#", "C7b7", "C/H",
+	} {
+		_, err := chord.Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSymbolRoundTrip(t *testing.T) {
+	// Canonical symbols are rendered back unchanged
+	for _, s := range []string{
+		"C", "Cm", "Cdim", "Caug", "Csus2", "Csus4", "C5",
+		"C6", "Cm6", "C6/9",
+		"C7", "Cmaj7", "Cm7", "CmMaj7", "Cdim7", "Cm7b5", "Caug7", "Cmaj7#5", "C7sus4",
+		"C9", "Cmaj9", "Cm9", "C11", "Cm11", "C13", "Cmaj13", "Cm13",
+		"Cadd9", "Cmadd9", "Cadd11",
+		"F#m7b5", "G7(#9)", "D/F#", "A7(b9,#11)", "C7(b5)", "E7(#5,#9)/G#", "C#m7(b13)",
+		"A#maj7/C", "G13(b9)", "Bbmaj7", "Ebm7b5", "Db/F", "Ab7(b9)/Gb", "Gbm",
+	} {
+		c, err := chord.Parse(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, s, c.Symbol())
+		}
+	}
+
+	// Alternative spellings are rendered with the canonical symbol
+	for s, expected := range map[string]string{
+		"CM":         "C",
+		"Cmaj":       "C",
+		"Cmin":       "Cm",
+		"C-7":        "Cm7",
+		"CΔ7":        "Cmaj7",
+		"CM7":        "Cmaj7",
+		"C°":         "Cdim",
+		"Co7":        "Cdim7",
+		"Cø":         "Cm7b5",
+		"C+":         "Caug",
+		"Csus":       "Csus4",
+		"Cm(maj7)":   "CmMaj7",
+		"C69":        "C6/9",
+		"G7#9":       "G7(#9)",
+		"A7b9#11":    "A7(b9,#11)",
+		"A7(b9 #11)": "A7(b9,#11)",
+		"C♯m7":       "C#m7",
+		"D♭7":        "Db7",
+		" D/F# ":     "D/F#",
+	} {
+		c, err := chord.Parse(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, c.Symbol(), s)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package chord
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// letters are the natural note names
+var letters = map[byte]note.Letter{
+	'C': note.C, 'D': note.D, 'E': note.E, 'F': note.F, 'G': note.G, 'A': note.A, 'B': note.B,
+}
+
+// suffixes contains all the canonical quality symbols and aliases, longest
+// first
+var suffixes = func() []string {
+	var s []string
+	for _, q := range Qualities {
+		s = append(s, q.Symbol)
+	}
+	for alias := range aliases {
+		s = append(s, alias)
+	}
+
+	sort.Slice(s, func(i, j int) bool {
+		if len(s[i]) != len(s[j]) {
+			return len(s[i]) > len(s[j])
+		}
+		return s[i] < s[j]
+	})
+	return s
+}()
+
+// Parse reads a chord symbol like "Cmaj7", "F#m7b5", "G7(#9)" or "D/F#".
+// The root is placed in the 4th octave, and the bass of slash chords in the
+// octave below the root
+func Parse(symbol string) (Chord, error) {
+	s := strings.TrimSpace(symbol)
+
+	root, s, err := parseName(s)
+	if err != nil {
+		return Chord{}, fmt.Errorf("invalid chord symbol %q: %v", symbol, err)
+	}
+
+	var bass note.Pitch
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		// 6/9 chords also contain a slash, it's only a bass note if the
+		// suffix is a note name
+		if p, rest, err := parseName(s[i+1:]); err == nil && rest == "" {
+			bass = p
+			s = s[:i]
+		}
+	}
+
+	var q Quality
+	for _, suffix := range suffixes {
+		if strings.HasPrefix(s, suffix) {
+			q, _ = qualityBySymbol(suffix)
+			s = s[len(suffix):]
+			break
+		}
+	}
+
+	alterations, err := parseAlterations(s)
+	if err != nil {
+		return Chord{}, fmt.Errorf("invalid chord symbol %q: %v", symbol, err)
+	}
+
+	c := New(root, q, alterations...)
+	if bass != nil {
+		c = c.Over(bass)
+	}
+
+	return c, nil
+}
+
+// parseName reads a note name without octave at the beginning of s, e.g.
+// "Bb", and returns it as a spelled pitch in the 4th octave. The second
+// returned value is the remaining unread string
+func parseName(s string) (note.SpelledPitch, string, error) {
+	if s == "" {
+		return note.SpelledPitch{}, "", fmt.Errorf("missing note name")
+	}
+
+	l, ok := letters[s[0]]
+	if !ok {
+		return note.SpelledPitch{}, "", fmt.Errorf("%q is not a note name", s[0])
+	}
+	p := note.NewSpelledPitch(l, note.Natural, 4)
+	s = s[1:]
+
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "#"), strings.HasPrefix(s, "♯"):
+			p.Accidental++
+			s = strings.TrimPrefix(strings.TrimPrefix(s, "#"), "♯")
+		case strings.HasPrefix(s, "b"), strings.HasPrefix(s, "♭"):
+			p.Accidental--
+			s = strings.TrimPrefix(strings.TrimPrefix(s, "b"), "♭")
+		default:
+			return p, s, nil
+		}
+	}
+
+	return p, s, nil
+}
+
+// parseAlterations reads a list of alterations, optionally inside
+// parentheses and separated by commas, e.g. "b9#11" or "(b9,#11)"
+func parseAlterations(s string) ([]Alteration, error) {
+	var alterations []Alteration
+
+	if strings.HasPrefix(s, "(") {
+		if !strings.HasSuffix(s, ")") {
+			return nil, fmt.Errorf("missing closing parenthesis in %q", s)
+		}
+		s = s[1 : len(s)-1]
+	}
+
+	for s != "" {
+		var a Alteration
+		switch {
+		case strings.HasPrefix(s, ","), strings.HasPrefix(s, " "):
+			s = s[1:]
+			continue
+		case strings.HasPrefix(s, "#"):
+			a.Sharp = true
+			s = s[1:]
+		case strings.HasPrefix(s, "♯"):
+			a.Sharp = true
+			s = strings.TrimPrefix(s, "♯")
+		case strings.HasPrefix(s, "b"):
+			s = s[1:]
+		case strings.HasPrefix(s, "♭"):
+			s = strings.TrimPrefix(s, "♭")
+		default:
+			return nil, fmt.Errorf("unknown chord suffix %q", s)
+		}
+
+		end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		if end == -1 {
+			end = len(s)
+		}
+
+		degree, err := strconv.Atoi(s[:end])
+		if err != nil {
+			return nil, fmt.Errorf("missing degree for alteration in %q", s)
+		}
+		if _, ok := naturals[degree]; !ok {
+			return nil, fmt.Errorf("degree %d can't be altered", degree)
+		}
+
+		a.Degree = degree
+		alterations = append(alterations, a)
+		s = s[end:]
+	}
+
+	return alterations, nil
+}
@@ -0,0 +1,156 @@
+package chord
+
+import (
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Quality is a type of chord, defined by the intervals of its notes above
+// the root
+type Quality struct {
+	// Name is a human readable name, e.g. "minor seventh"
+	Name string
+	// Symbol is the canonical suffix written after the root, e.g. "m7"
+	Symbol string
+	// Intervals are the distances of each chord note to the root, in
+	// ascending order. The first one is always 0, the root itself
+	Intervals []note.Interval
+}
+
+// Intervals above the root, named by their scale degree
+const (
+	root        note.Interval = 0
+	major2nd    note.Interval = 2
+	minor3rd    note.Interval = 3
+	major3rd    note.Interval = 4
+	perfect4th  note.Interval = 5
+	dim5th      note.Interval = 6
+	perfect5th  note.Interval = 7
+	aug5th      note.Interval = 8
+	major6th    note.Interval = 9
+	minor7th    note.Interval = 10
+	major7th    note.Interval = 11
+	major9th    note.Interval = 14
+	perfect11th note.Interval = 17
+	major13th   note.Interval = 21
+)
+
+// Triads
+var (
+	Major      = Quality{"major", "", []note.Interval{root, major3rd, perfect5th}}
+	Minor      = Quality{"minor", "m", []note.Interval{root, minor3rd, perfect5th}}
+	Diminished = Quality{"diminished", "dim", []note.Interval{root, minor3rd, dim5th}}
+	Augmented  = Quality{"augmented", "aug", []note.Interval{root, major3rd, aug5th}}
+	Sus2       = Quality{"suspended second", "sus2", []note.Interval{root, major2nd, perfect5th}}
+	Sus4       = Quality{"suspended fourth", "sus4", []note.Interval{root, perfect4th, perfect5th}}
+	Power      = Quality{"power", "5", []note.Interval{root, perfect5th}}
+)
+
+// Sixth and seventh chords
+var (
+	Major6          = Quality{"major sixth", "6", []note.Interval{root, major3rd, perfect5th, major6th}}
+	Minor6          = Quality{"minor sixth", "m6", []note.Interval{root, minor3rd, perfect5th, major6th}}
+	Dominant7       = Quality{"dominant seventh", "7", []note.Interval{root, major3rd, perfect5th, minor7th}}
+	Major7          = Quality{"major seventh", "maj7", []note.Interval{root, major3rd, perfect5th, major7th}}
+	Minor7          = Quality{"minor seventh", "m7", []note.Interval{root, minor3rd, perfect5th, minor7th}}
+	MinorMajor7     = Quality{"minor major seventh", "mMaj7", []note.Interval{root, minor3rd, perfect5th, major7th}}
+	Diminished7     = Quality{"diminished seventh", "dim7", []note.Interval{root, minor3rd, dim5th, major6th}}
+	HalfDiminished7 = Quality{"half-diminished seventh", "m7b5", []note.Interval{root, minor3rd, dim5th, minor7th}}
+	Augmented7      = Quality{"augmented seventh", "aug7", []note.Interval{root, major3rd, aug5th, minor7th}}
+	AugmentedMajor7 = Quality{"augmented major seventh", "maj7#5", []note.Interval{root, major3rd, aug5th, major7th}}
+	Dominant7Sus4   = Quality{"dominant seventh suspended fourth", "7sus4", []note.Interval{root, perfect4th, perfect5th, minor7th}}
+)
+
+// Extended chords
+var (
+	Dominant9  = Quality{"dominant ninth", "9", []note.Interval{root, major3rd, perfect5th, minor7th, major9th}}
+	Major9     = Quality{"major ninth", "maj9", []note.Interval{root, major3rd, perfect5th, major7th, major9th}}
+	Minor9     = Quality{"minor ninth", "m9", []note.Interval{root, minor3rd, perfect5th, minor7th, major9th}}
+	Dominant11 = Quality{"dominant eleventh", "11", []note.Interval{root, major3rd, perfect5th, minor7th, major9th, perfect11th}}
+	Minor11    = Quality{"minor eleventh", "m11", []note.Interval{root, minor3rd, perfect5th, minor7th, major9th, perfect11th}}
+	// The eleventh is usually omitted in major and dominant thirteenth chords,
+	// it clashes with the major third
+	Dominant13 = Quality{"dominant thirteenth", "13", []note.Interval{root, major3rd, perfect5th, minor7th, major9th, major13th}}
+	Major13    = Quality{"major thirteenth", "maj13", []note.Interval{root, major3rd, perfect5th, major7th, major9th, major13th}}
+	Minor13    = Quality{"minor thirteenth", "m13", []note.Interval{root, minor3rd, perfect5th, minor7th, major9th, perfect11th, major13th}}
+)
+
+// Added tone chords
+var (
+	Add9      = Quality{"added ninth", "add9", []note.Interval{root, major3rd, perfect5th, major9th}}
+	MinorAdd9 = Quality{"minor added ninth", "madd9", []note.Interval{root, minor3rd, perfect5th, major9th}}
+	Add11     = Quality{"added eleventh", "add11", []note.Interval{root, major3rd, perfect5th, perfect11th}}
+	SixNine   = Quality{"six nine", "6/9", []note.Interval{root, major3rd, perfect5th, major6th, major9th}}
+)
+
+// Qualities contains all the known chord qualities
+var Qualities = []Quality{
+	Major, Minor, Diminished, Augmented, Sus2, Sus4, Power,
+	Major6, Minor6, Dominant7, Major7, Minor7, MinorMajor7, Diminished7,
+	HalfDiminished7, Augmented7, AugmentedMajor7, Dominant7Sus4,
+	Dominant9, Major9, Minor9, Dominant11, Minor11, Dominant13, Major13, Minor13,
+	Add9, MinorAdd9, Add11, SixNine,
+}
+
+// aliases maps alternative suffixes to the canonical Quality Symbol
+var aliases = map[string]string{
+	"M":       "",
+	"maj":     "",
+	"min":     "m",
+	"-":       "m",
+	"°":       "dim",
+	"o":       "dim",
+	"+":       "aug",
+	"sus":     "sus4",
+	"M6":      "6",
+	"min6":    "m6",
+	"-6":      "m6",
+	"M7":      "maj7",
+	"Maj7":    "maj7",
+	"Δ":       "maj7",
+	"Δ7":      "maj7",
+	"min7":    "m7",
+	"-7":      "m7",
+	"mM7":     "mMaj7",
+	"m(maj7)": "mMaj7",
+	"minmaj7": "mMaj7",
+	"°7":      "dim7",
+	"o7":      "dim7",
+	"ø":       "m7b5",
+	"ø7":      "m7b5",
+	"min7b5":  "m7b5",
+	"+7":      "aug7",
+	"+maj7":   "maj7#5",
+	"7sus":    "7sus4",
+	"M9":      "maj9",
+	"Maj9":    "maj9",
+	"min9":    "m9",
+	"-9":      "m9",
+	"min11":   "m11",
+	"-11":     "m11",
+	"M13":     "maj13",
+	"Maj13":   "maj13",
+	"min13":   "m13",
+	"-13":     "m13",
+	"madd2":   "madd9",
+	"69":      "6/9",
+}
+
+// qualityBySymbol returns the Quality for a canonical symbol or an alias
+func qualityBySymbol(symbol string) (Quality, bool) {
+	if canonical, ok := aliases[symbol]; ok {
+		symbol = canonical
+	}
+
+	for _, q := range Qualities {
+		if q.Symbol == symbol {
+			return q, true
+		}
+	}
+
+	return Quality{}, false
+}
+
+// String returns the quality name
+func (q Quality) String() string {
+	return q.Name
+}
@@ -0,0 +1,148 @@
+package chord
+
+import (
+	"sort"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Candidate is one of the possible chords a group of pitches could be
+type Candidate struct {
+	// Chord is the recognized chord. For inversions and chords with a
+	// foreign bass note, Chord.Bass contains the lowest pitch
+	Chord Chord
+	// Inversion is 0 for root position, 1 when the second chord note is in
+	// the bass, 2 for the third one, and so on. It is -1 when the bass is not
+	// a chord note, or when it is an extension like a 9th
+	Inversion int
+	// Score is a likelihood measure between 0 and 1, higher is more likely
+	Score float64
+}
+
+// Scoring penalties, subtracted from a perfect score of 1
+const (
+	// missingFifthPenalty applies to chords with 4 or more notes where the
+	// fifth is omitted, a common voicing
+	missingFifthPenalty = 0.15
+	// foreignBassPenalty applies when the bass is not part of the chord
+	foreignBassPenalty = 0.3
+	// inversionPenalty applies once per inversion position
+	inversionPenalty = 0.05
+	// sizePenalty applies once per chord note beyond a triad, to prefer the
+	// simplest name
+	sizePenalty = 0.02
+)
+
+// Recognize returns the chords that the simultaneous notes could be, ranked
+// from most to least likely. Rests are ignored. It returns an empty slice
+// if no known chord matches
+func Recognize(notes []note.Note) []Candidate {
+	var pitches []note.Pitch
+	for _, n := range notes {
+		if n.Key() >= 0 {
+			pitches = append(pitches, n.Pitch)
+		}
+	}
+
+	return RecognizePitches(pitches...)
+}
+
+// RecognizePitches returns the chords that the pitches could be, ranked from
+// most to least likely. It returns an empty slice if no known chord matches
+func RecognizePitches(pitches ...note.Pitch) []Candidate {
+	if len(pitches) == 0 {
+		return nil
+	}
+
+	// lowest pitch for each pitch class
+	classes := map[int]note.Pitch{}
+	var bass note.Pitch
+	for _, p := range pitches {
+		pc := note.PitchClass(p.Key())
+		if lowest, ok := classes[pc]; !ok || p.Key() < lowest.Key() {
+			classes[pc] = p
+		}
+		if bass == nil || p.Key() < bass.Key() {
+			bass = p
+		}
+	}
+
+	var candidates []Candidate
+	for rootClass, root := range classes {
+		for _, q := range Qualities {
+			if c, ok := match(classes, root, rootClass, bass, q); ok {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Chord.Symbol() < candidates[j].Chord.Symbol()
+	})
+
+	return candidates
+}
+
+// match returns a Candidate if the pitch classes form a chord of quality q
+// built on root. The only pitch class not in the chord allowed is the bass,
+// and the only chord note that can be missing is the fifth
+func match(classes map[int]note.Pitch, root note.Pitch, rootClass int, bass note.Pitch, q Quality) (Candidate, bool) {
+	score := 1.0
+	if len(q.Intervals) > 3 {
+		score -= sizePenalty * float64(len(q.Intervals)-3)
+	}
+
+	chordClasses := map[int]bool{}
+	for _, i := range q.Intervals {
+		pc := note.PitchClass(rootClass + int(i))
+		chordClasses[pc] = true
+
+		if _, ok := classes[pc]; ok {
+			continue
+		}
+
+		if i != perfect5th || len(q.Intervals) < 4 {
+			return Candidate{}, false
+		}
+		score -= missingFifthPenalty
+	}
+
+	bassClass := note.PitchClass(bass.Key())
+	for pc := range classes {
+		if !chordClasses[pc] && pc != bassClass {
+			return Candidate{}, false
+		}
+	}
+
+	c := Candidate{Chord: New(root, q)}
+
+	if bassClass != rootClass {
+		c.Chord = c.Chord.Over(bass)
+		c.Inversion = -1
+
+		for n, i := range q.Intervals {
+			// Extensions in the bass, like the 9th in Cadd9/D, are not
+			// considered inversions
+			if i < note.Octave && note.PitchClass(rootClass+int(i)) == bassClass {
+				c.Inversion = n
+				break
+			}
+		}
+
+		if c.Inversion == -1 {
+			// A lone bass note with nothing else is not a slash chord
+			if len(classes) < 3 {
+				return Candidate{}, false
+			}
+			score -= foreignBassPenalty
+		} else {
+			score -= inversionPenalty * float64(c.Inversion)
+		}
+	}
+
+	c.Score = score
+	return c, true
+}
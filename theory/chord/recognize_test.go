@@ -0,0 +1,93 @@
+package chord_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/chord"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// symbols returns the chord symbols of the candidates, in order
+func symbols(candidates []chord.Candidate) []string {
+	var s []string
+	for _, c := range candidates {
+		s = append(s, c.Chord.Symbol())
+	}
+	return s
+}
+
+func TestRecognize(t *testing.T) {
+	// Bass staff group from the marble machine
+	candidates := chord.Recognize([]note.Note{
+		note.NewNote(note.E4, note.Eighth),
+		note.NewNote(note.G4, note.Eighth),
+		note.NewNote(note.B4, note.Eighth)})
+
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "Em", candidates[0].Chord.Symbol())
+	assert.Equal(t, note.E4, candidates[0].Chord.Root)
+	assert.Equal(t, 0, candidates[0].Inversion)
+	assert.Equal(t, 1.0, candidates[0].Score)
+
+	candidates = chord.Recognize([]note.Note{
+		note.NewNote(note.C3, note.Eighth),
+		note.NewNote(note.E4, note.Eighth),
+		note.NewNote(note.G4, note.Eighth),
+		note.NewRest(note.Eighth)})
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "C", candidates[0].Chord.Symbol())
+	assert.Equal(t, note.C3, candidates[0].Chord.Root)
+
+	assert.Empty(t, chord.Recognize([]note.Note{note.NewRest(note.Quarter)}))
+	assert.Empty(t, chord.Recognize(nil))
+	assert.Empty(t, chord.RecognizePitches(note.C4, note.Csharp4, note.D4))
+}
+
+func TestRecognizeInversions(t *testing.T) {
+	candidates := chord.RecognizePitches(note.Fsharp3, note.D4, note.A4)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "D/F#", candidates[0].Chord.Symbol())
+	assert.Equal(t, 1, candidates[0].Inversion)
+
+	candidates = chord.RecognizePitches(note.G3, note.C4, note.E4)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "C/G", candidates[0].Chord.Symbol())
+	assert.Equal(t, 2, candidates[0].Inversion)
+
+	candidates = chord.RecognizePitches(note.F3, note.G3, note.B3, note.D4)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "G7/F", candidates[0].Chord.Symbol())
+	assert.Equal(t, 3, candidates[0].Inversion)
+}
+
+func TestRecognizeAmbiguous(t *testing.T) {
+	// Same notes, the bass decides the most likely name
+	assert.Equal(t,
+		[]string{"C6", "Am7/C"},
+		symbols(chord.RecognizePitches(note.C4, note.E4, note.G4, note.A4)))
+
+	assert.Equal(t,
+		[]string{"Am7", "C6/A", "C/A"},
+		symbols(chord.RecognizePitches(note.A3, note.C4, note.E4, note.G4)))
+
+	candidates := chord.RecognizePitches(note.B3, note.D4, note.F4, note.Gsharp4)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "Bdim7", candidates[0].Chord.Symbol())
+	assert.Equal(t, "G#dim7/B", candidates[1].Chord.Symbol())
+}
+
+func TestRecognizeMissingFifth(t *testing.T) {
+	candidates := chord.RecognizePitches(note.C3, note.E4, note.Asharp4)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "C7", candidates[0].Chord.Symbol())
+	assert.True(t, candidates[0].Score < 1)
+}
+
+func TestRecognizeForeignBass(t *testing.T) {
+	candidates := chord.RecognizePitches(note.D3, note.C4, note.E4, note.G4)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "C/D", candidates[0].Chord.Symbol())
+	assert.Equal(t, -1, candidates[0].Inversion)
+}
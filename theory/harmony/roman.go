@@ -0,0 +1,296 @@
+package harmony
+
+import (
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/chord"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Numeral is the Roman numeral analysis of a chord in a key, e.g. V7 or ii6
+type Numeral struct {
+	// Chord is the analyzed chord
+	Chord chord.Chord
+	// Degree is the scale degree of the chord root, from 1 to 7. For
+	// secondary chords it is the degree in the tonicized key, e.g. 5 for the
+	// V in V/ii. A 0 Degree means the chord could not be analyzed
+	Degree int
+	// Accidental is the alteration of the root compared to the scale degree,
+	// e.g. Flat for bVI
+	Accidental note.Accidental
+	// Inversion is 0 for root position, 1 for first inversion, and so on
+	Inversion int
+	// Secondary is the degree of the tonicized chord for secondary dominants
+	// and leading tone chords, e.g. 2 for V/ii. It is 0 for other chords
+	Secondary int
+	// Borrowed is true for chords taken from the parallel key, e.g. iv in a
+	// major key
+	Borrowed bool
+
+	// secondaryUpper is true when the tonicized chord is major
+	secondaryUpper bool
+}
+
+// romans are the numerals for each degree, in upper case
+var romans = [...]string{"", "I", "II", "III", "IV", "V", "VI", "VII"}
+
+// Analyze returns the Roman numeral analysis of each chord in the key k
+func Analyze(k key.Key, chords ...chord.Chord) []Numeral {
+	numerals := make([]Numeral, len(chords))
+	for i, c := range chords {
+		numerals[i] = analyze(k, c, inversion(c))
+	}
+
+	return numerals
+}
+
+// AnalyzeNotes returns the Roman numeral analysis of each group of
+// simultaneous notes in the key k, using the most likely chord returned by
+// chord.Recognize. Groups that are not recognized as chords, like rests,
+// return a Numeral with Degree 0
+func AnalyzeNotes(k key.Key, groups [][]note.Note) []Numeral {
+	numerals := make([]Numeral, len(groups))
+	for i, g := range groups {
+		candidates := chord.Recognize(g)
+		if len(candidates) == 0 {
+			continue
+		}
+
+		c := candidates[0]
+		inv := c.Inversion
+		if inv < 0 {
+			inv = 0
+		}
+		numerals[i] = analyze(k, c.Chord, inv)
+	}
+
+	return numerals
+}
+
+// analyze returns the numeral for c in the key k
+func analyze(k key.Key, c chord.Chord, inv int) Numeral {
+	n := Numeral{Chord: c, Inversion: inv}
+
+	// chords are built on pitches, they are never rests
+	root, _ := k.Spell(c.Root)
+	n.Degree, n.Accidental = degree(k, root)
+
+	if n.Accidental == note.Natural && fits(k, c) {
+		return n
+	}
+
+	if target, upper, ok := secondary(k, c); ok {
+		n.Secondary = target
+		n.secondaryUpper = upper
+		n.Degree = 5
+		if isLeadingTone(c) {
+			n.Degree = 7
+		}
+		n.Accidental = note.Natural
+		return n
+	}
+
+	parallel := k.Parallel()
+	if fits(parallel, c) {
+		n.Borrowed = true
+		root, _ = parallel.Spell(c.Root)
+		n.Degree, n.Accidental = degree(k, root)
+	}
+
+	return n
+}
+
+// degree returns the scale degree of the spelled root in the key, and its
+// alteration compared to the scale
+func degree(k key.Key, root note.SpelledPitch) (int, note.Accidental) {
+	scale := k.Scale()
+	d := (int(root.Letter)-int(k.Tonic.Letter)+7)%7 + 1
+
+	octave := int(note.Octave)
+	diff := ((root.Key()-scale[d-1].Key())%octave + octave) % octave
+	if diff > octave/2 {
+		diff -= octave
+	}
+
+	// The raised 6th and 7th are part of the minor key, e.g. vii° and not
+	// #vii° for G# in A minor
+	if k.Mode == key.Minor && d >= 6 && diff == int(note.Sharp) {
+		diff = 0
+	}
+
+	return d, note.Accidental(diff)
+}
+
+// fits returns true if all the chord notes belong to the key scale. Minor
+// keys also accept the raised 6th and 7th of the melodic and harmonic minor
+// scales
+func fits(k key.Key, c chord.Chord) bool {
+	classes := map[int]bool{}
+	for _, p := range k.Scale() {
+		classes[note.PitchClass(p.Key())] = true
+	}
+	if k.Mode == key.Minor {
+		for _, d := range []int{5, 6} {
+			classes[note.PitchClass(k.Scale()[d].Key()+1)] = true
+		}
+	}
+
+	for _, p := range c.Pitches() {
+		if !classes[note.PitchClass(p.Key())] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// secondary returns the degree of the chord tonicized by c, if c is a
+// dominant or leading tone chord of a major or minor chord of the key, e.g.
+// 5 for D major in C major (V/V). It also returns true if the tonicized
+// chord is major
+func secondary(k key.Key, c chord.Chord) (int, bool, bool) {
+	scale := k.Scale()
+	dominant := isDominant(c)
+	leading := isLeadingTone(c)
+	if !dominant && !leading {
+		return 0, false, false
+	}
+
+	for target := 2; target <= 7; target++ {
+		triad := chord.RecognizePitches(
+			scale[target-1], scale[(target+1)%7], scale[(target+3)%7])
+		if len(triad) == 0 {
+			continue
+		}
+
+		q := triad[0].Chord.Quality.Symbol
+		if q != chord.Major.Symbol && q != chord.Minor.Symbol {
+			continue
+		}
+
+		targetClass := note.PitchClass(scale[target-1].Key())
+		rootClass := note.PitchClass(c.Root.Key())
+
+		if (dominant && rootClass == note.PitchClass(targetClass+7)) ||
+			(leading && rootClass == note.PitchClass(targetClass-1)) {
+			return target, q == chord.Major.Symbol, true
+		}
+	}
+
+	return 0, false, false
+}
+
+// isDominant returns true for major triads and dominant seventh chords
+func isDominant(c chord.Chord) bool {
+	switch c.Quality.Symbol {
+	case chord.Major.Symbol, chord.Dominant7.Symbol, chord.Dominant9.Symbol:
+		return true
+	}
+	return false
+}
+
+// isLeadingTone returns true for diminished triads and seventh chords
+func isLeadingTone(c chord.Chord) bool {
+	switch c.Quality.Symbol {
+	case chord.Diminished.Symbol, chord.Diminished7.Symbol, chord.HalfDiminished7.Symbol:
+		return true
+	}
+	return false
+}
+
+// inversion returns the inversion of the chord, from the position of its
+// bass in the chord notes
+func inversion(c chord.Chord) int {
+	if c.Bass == nil {
+		return 0
+	}
+
+	intervals := c.Intervals()
+	for i, interval := range intervals {
+		if interval < note.Octave &&
+			note.PitchClass(c.Root.Key()+int(interval)) == note.PitchClass(c.Bass.Key()) {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// figures are the figured bass symbols for each inversion of triads and
+// seventh chords
+var (
+	triadFigures   = [...]string{"", "6", "64"}
+	seventhFigures = [...]string{"7", "65", "43", "42"}
+)
+
+// String returns the Roman numeral, e.g. "V65/V", "bVI" or "viiø7". Upper
+// case numerals are used for chords with a major third, lower case for
+// chords with a minor third
+func (n Numeral) String() string {
+	if n.Degree == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(n.Accidental.String())
+
+	numeral := romans[n.Degree]
+	if hasMinorThird(n.Chord) {
+		numeral = strings.ToLower(numeral)
+	}
+	b.WriteString(numeral)
+
+	var quality string
+	var figures []string
+	switch n.Chord.Quality.Symbol {
+	case chord.Major.Symbol, chord.Minor.Symbol:
+		figures = triadFigures[:]
+	case chord.Diminished.Symbol:
+		quality, figures = "°", triadFigures[:]
+	case chord.Augmented.Symbol:
+		quality, figures = "+", triadFigures[:]
+	case chord.Dominant7.Symbol, chord.Minor7.Symbol:
+		figures = seventhFigures[:]
+	case chord.Major7.Symbol, chord.MinorMajor7.Symbol:
+		quality, figures = "M", seventhFigures[:]
+	case chord.Diminished7.Symbol:
+		quality, figures = "°", seventhFigures[:]
+	case chord.HalfDiminished7.Symbol:
+		quality, figures = "ø", seventhFigures[:]
+	case chord.Augmented7.Symbol:
+		quality, figures = "+", seventhFigures[:]
+	default:
+		quality = n.Chord.Quality.Symbol
+	}
+
+	b.WriteString(quality)
+	if n.Inversion < len(figures) {
+		b.WriteString(figures[n.Inversion])
+	}
+
+	if n.Secondary != 0 {
+		target := romans[n.Secondary]
+		if !n.secondaryUpper {
+			target = strings.ToLower(target)
+		}
+		b.WriteString("/")
+		b.WriteString(target)
+	}
+
+	return b.String()
+}
+
+// hasMinorThird returns true for chords with a minor third above the root,
+// and not a major one
+func hasMinorThird(c chord.Chord) bool {
+	for _, i := range c.Quality.Intervals {
+		if i == note.Tone+note.Semitone {
+			return true
+		}
+		if i == 2*note.Tone {
+			return false
+		}
+	}
+	return false
+}
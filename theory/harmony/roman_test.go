@@ -0,0 +1,100 @@
+package harmony_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/chord"
+	"github.com/carlosms/music-playground/theory/harmony"
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// analyze returns the numerals for the chord symbols, separated by spaces
+func analyze(t *testing.T, k string, symbols string) string {
+	kk, err := key.Parse(k)
+	require.NoError(t, err)
+
+	var chords []chord.Chord
+	for _, s := range strings.Fields(symbols) {
+		c, err := chord.Parse(s)
+		require.NoError(t, err, s)
+		chords = append(chords, c)
+	}
+
+	var numerals []string
+	for _, n := range harmony.Analyze(kk, chords...) {
+		numerals = append(numerals, n.String())
+	}
+	return strings.Join(numerals, " ")
+}
+
+func TestAnalyzeDiatonic(t *testing.T) {
+	assert.Equal(t, "I ii iii IV V vi vii°",
+		analyze(t, "C", "C Dm Em F G Am Bdim"))
+	assert.Equal(t, "IM7 ii7 V7 viiø7",
+		analyze(t, "C", "Cmaj7 Dm7 G7 Bm7b5"))
+	assert.Equal(t, "i iv V7 VI ii° III+",
+		analyze(t, "E minor", "Em Am B7 C F#dim Gaug"))
+	assert.Equal(t, "vii°7", analyze(t, "A minor", "G#dim7"))
+	assert.Equal(t, "I ii V", analyze(t, "Bb", "Bb Cm F"))
+}
+
+func TestAnalyzeInversions(t *testing.T) {
+	assert.Equal(t, "I6 I64 V65 V43 V42",
+		analyze(t, "C", "C/E C/G G7/B G7/D G7/F"))
+	assert.Equal(t, "ii6", analyze(t, "D", "Em/G"))
+}
+
+func TestAnalyzeSecondary(t *testing.T) {
+	assert.Equal(t, "V/V V7/IV V7/ii vii°/V vii°7/vi",
+		analyze(t, "C", "D C7 A7 F#dim G#dim7"))
+	assert.Equal(t, "V65/V", analyze(t, "C", "D7/F#"))
+	assert.Equal(t, "V7/iv", analyze(t, "E minor", "E7"))
+}
+
+func TestAnalyzeBorrowed(t *testing.T) {
+	assert.Equal(t, "iv bVI bVII bIII", analyze(t, "C", "Fm Ab Bb Eb"))
+	assert.Equal(t, "IV", analyze(t, "A minor", "D"))
+
+	k, err := key.Parse("C")
+	require.NoError(t, err)
+	fm, err := chord.Parse("Fm")
+	require.NoError(t, err)
+
+	n := harmony.Analyze(k, fm)[0]
+	assert.True(t, n.Borrowed)
+	assert.Equal(t, 4, n.Degree)
+	assert.Equal(t, note.Natural, n.Accidental)
+}
+
+func TestAnalyzeNotes(t *testing.T) {
+	em, err := key.Parse("E minor")
+	require.NoError(t, err)
+
+	numerals := harmony.AnalyzeNotes(em, [][]note.Note{
+		{
+			note.NewNote(note.E4, note.Eighth),
+			note.NewNote(note.G4, note.Eighth),
+			note.NewNote(note.B4, note.Eighth),
+		},
+		{
+			note.NewNote(note.Dsharp4, note.Eighth),
+			note.NewNote(note.Fsharp4, note.Eighth),
+			note.NewNote(note.A4, note.Eighth),
+			note.NewNote(note.B4, note.Eighth),
+		},
+		{
+			note.NewRest(note.Quarter),
+		},
+	})
+
+	require.Len(t, numerals, 3)
+	assert.Equal(t, "i", numerals[0].String())
+	assert.Equal(t, "V65", numerals[1].String())
+	assert.Equal(t, 1, numerals[1].Inversion)
+	assert.Equal(t, 0, numerals[2].Degree)
+	assert.Equal(t, "", numerals[2].String())
+}
@@ -0,0 +1,10 @@
+package intmath
+
+// FloorDiv returns a / b rounded down, also for negative values
+func FloorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,19 @@
+package intmath_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/internal/intmath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFloorDiv(t *testing.T) {
+	assert.Equal(t, 2, intmath.FloorDiv(7, 3))
+	assert.Equal(t, 2, intmath.FloorDiv(6, 3))
+	assert.Equal(t, 0, intmath.FloorDiv(0, 3))
+	assert.Equal(t, -1, intmath.FloorDiv(-1, 3))
+	assert.Equal(t, -2, intmath.FloorDiv(-6, 3))
+	assert.Equal(t, -3, intmath.FloorDiv(-7, 3))
+	assert.Equal(t, -3, intmath.FloorDiv(7, -3))
+	assert.Equal(t, 2, intmath.FloorDiv(-7, -3))
+}
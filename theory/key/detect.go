@@ -0,0 +1,134 @@
+package key
+
+import (
+	"math"
+	"sort"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Candidate is a possible key for a group of notes, with a score from -1 to
+// 1. Higher scores are more likely
+type Candidate struct {
+	Key   Key
+	Score float64
+}
+
+// Krumhansl-Kessler key profiles, the perceived stability of each pitch
+// class in a major and a minor key, starting on the tonic
+var (
+	majorProfile = [12]float64{
+		6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [12]float64{
+		6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// Detect estimates the key of the notes using the Krumhansl-Schmuckler
+// algorithm. The duration of each pitch class is compared to the major and
+// minor key profiles, and the 24 major and minor keys are returned sorted by
+// their correlation score. Rests are ignored. It returns nil if there are no
+// pitched notes
+func Detect(notes []note.Note) []Candidate {
+	return DetectStaff([][]note.Note{notes})
+}
+
+// DetectStaff estimates the key of one or more staves made of groups of
+// simultaneous notes. See Detect
+func DetectStaff(staves ...[][]note.Note) []Candidate {
+	var durations [12]float64
+	var total float64
+
+	for _, staff := range staves {
+		for _, group := range staff {
+			for _, n := range group {
+				if n.Key() < 0 {
+					continue
+				}
+				durations[note.PitchClass(n.Key())] += float64(n.Duration)
+				total += float64(n.Duration)
+			}
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	candidates := make([]Candidate, 0, 24)
+	for tonic := 0; tonic < 12; tonic++ {
+		for _, m := range []Mode{Major, Minor} {
+			profile := majorProfile
+			if m == Minor {
+				profile = minorProfile
+			}
+
+			// rotate the durations so the tonic is the first element
+			var rotated [12]float64
+			for i := range rotated {
+				rotated[i] = durations[(tonic+i)%12]
+			}
+
+			candidates = append(candidates, Candidate{
+				Key:   New(tonicName(tonic, m), m),
+				Score: correlation(rotated, profile),
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates
+}
+
+// tonicName returns the spelling of the tonic pitch class with the fewest
+// accidentals in the key signature, e.g. Bb for major and A# for minor
+func tonicName(class int, m Mode) note.SpelledPitch {
+	var best note.SpelledPitch
+	bestFifths := math.MaxInt32
+
+	for _, a := range []note.Accidental{note.Natural, note.Flat, note.Sharp} {
+		for l := note.C; l <= note.B; l++ {
+			p := note.NewSpelledPitch(l, a, tonicOctave)
+			if note.PitchClass(p.Key()) != class {
+				continue
+			}
+
+			fifths := int(New(p, m).Signature())
+			if fifths < 0 {
+				fifths = -fifths
+			}
+			if fifths < bestFifths {
+				best, bestFifths = p, fifths
+			}
+		}
+	}
+
+	return best
+}
+
+// correlation returns the Pearson correlation coefficient of x and y
+func correlation(x, y [12]float64) float64 {
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= 12
+	meanY /= 12
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+
+	return cov / math.Sqrt(varX*varY)
+}
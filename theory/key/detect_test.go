@@ -0,0 +1,87 @@
+package key_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	var notes []note.Note
+	for _, p := range []note.Pitch{note.C4, note.D4, note.E4, note.F4, note.G4, note.A4, note.B4} {
+		notes = append(notes, note.NewNote(p, note.Quarter))
+	}
+	notes = append(notes, note.NewNote(note.C5, note.Half), note.NewRest(note.Whole))
+
+	candidates := key.Detect(notes)
+	require.Len(t, candidates, 24)
+	assert.Equal(t, "C major", candidates[0].Key.String())
+
+	for i := 1; i < len(candidates); i++ {
+		assert.True(t, candidates[i-1].Score >= candidates[i].Score)
+	}
+}
+
+func TestDetectStaff(t *testing.T) {
+	// Bars 1 to 4 of the marble machine, treble and bass staves
+	treble := [][]note.Note{
+		{note.NewNote(note.E6, note.Quarter)},
+		{note.NewNote(note.E5, note.Eighth)},
+		{note.NewNote(note.B5, note.Eighth)},
+		{note.NewRest(note.Quarter)},
+		{note.NewNote(note.E5, note.Eighth)},
+		{note.NewNote(note.A5, note.Eighth)},
+		{note.NewNote(note.G5, note.Eighth)},
+		{note.NewNote(note.A5, note.Eighth)},
+		{note.NewNote(note.E5, note.Eighth)},
+		{note.NewNote(note.B5, note.Eighth)},
+		{note.NewRest(note.Eighth)},
+		{note.NewNote(note.G5, note.Eighth)},
+		{note.NewNote(note.A5, note.Eighth)},
+		{note.NewNote(note.D6, note.Eighth)},
+		{note.NewRest(note.Quarter)},
+		{note.NewNote(note.E5, note.Eighth)},
+		{note.NewNote(note.B5, note.Eighth)},
+		{note.NewRest(note.Quarter)},
+		{note.NewNote(note.E5, note.Eighth)},
+		{note.NewNote(note.A5, note.Eighth)},
+		{note.NewNote(note.G5, note.Eighth)},
+		{note.NewNote(note.A5, note.Eighth)},
+		{note.NewNote(note.D5, note.Eighth)},
+		{note.NewNote(note.Fsharp5, note.Eighth)},
+		{note.NewRest(note.Eighth)},
+		{note.NewNote(note.G5, note.Eighth)},
+		{note.NewNote(note.A5, note.Eighth)},
+		{note.NewNote(note.D6, note.Eighth)},
+	}
+	bass := [][]note.Note{
+		{note.NewNote(note.E3, note.Whole), note.NewNote(note.B3, note.Whole)},
+		{note.NewNote(note.E3, note.Whole), note.NewNote(note.G3, note.Whole)},
+		{note.NewNote(note.E3, note.Whole), note.NewNote(note.B3, note.Whole)},
+		{note.NewNote(note.D3, note.Whole), note.NewNote(note.Fsharp3, note.Whole)},
+	}
+
+	candidates := key.DetectStaff(treble, bass)
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "E minor", candidates[0].Key.String())
+	assert.True(t, candidates[0].Score > 0.8)
+}
+
+func TestDetectSpelling(t *testing.T) {
+	// Bb major triad
+	candidates := key.Detect([]note.Note{
+		note.NewNote(note.Asharp4, note.Whole),
+		note.NewNote(note.D5, note.Half),
+		note.NewNote(note.F5, note.Half),
+	})
+	require.NotEmpty(t, candidates)
+	assert.Equal(t, "Bb major", candidates[0].Key.String())
+}
+
+func TestDetectEmpty(t *testing.T) {
+	assert.Nil(t, key.Detect(nil))
+	assert.Nil(t, key.Detect([]note.Note{note.NewRest(note.Whole)}))
+}
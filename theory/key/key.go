@@ -0,0 +1,188 @@
+package key
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// tonicOctave is the octave used for all key tonics, only the tonic name is
+// relevant for a key
+const tonicOctave = 4
+
+// Key is a tonic and a mode, e.g. E minor
+type Key struct {
+	// Tonic is the first degree of the key. Its octave is not relevant
+	Tonic note.SpelledPitch
+	Mode  Mode
+}
+
+// New returns a Key with the given tonic and mode
+func New(tonic note.SpelledPitch, m Mode) Key {
+	tonic.Octave = tonicOctave
+	return Key{
+		Tonic: tonic,
+		Mode:  m,
+	}
+}
+
+// fifthsOrder are the natural letters in the circle of fifths, starting
+// from F
+var fifthsOrder = [...]note.Letter{note.F, note.C, note.G, note.D, note.A, note.E, note.B}
+
+// FromSignature returns the key of the mode with the given number of sharps
+// (positive) or flats (negative), e.g. E minor for 1 and Minor
+func FromSignature(sig note.KeySignature, m Mode) Key {
+	// position of the tonic in fifthsOrder, extended with sharps and flats
+	pos := int(sig) - m.fifths() + 1
+	accidental := pos / len(fifthsOrder)
+	if pos < 0 && pos%len(fifthsOrder) != 0 {
+		accidental--
+	}
+	letter := fifthsOrder[pos-accidental*len(fifthsOrder)]
+
+	return New(note.NewSpelledPitch(letter, note.Accidental(accidental), tonicOctave), m)
+}
+
+// String returns the key name, e.g. "Bb major"
+func (k Key) String() string {
+	return fmt.Sprintf("%v %v", k.Tonic.Name(), k.Mode)
+}
+
+// Signature returns the number of sharps or flats in the key signature
+func (k Key) Signature() note.KeySignature {
+	return note.KeySignature(k.Tonic.Fifths() + k.Mode.fifths())
+}
+
+// Scale returns the spelled pitches of the key scale, starting on the tonic
+// in the 4th octave. The last element is the tonic one octave higher
+func (k Key) Scale() []note.SpelledPitch {
+	return k.Mode.Scale().Spell(k.Tonic)
+}
+
+// Dominant returns the next key in the circle of fifths, with one more sharp
+// or one less flat, e.g. D major for G major
+func (k Key) Dominant() Key {
+	return New(k.Tonic.Transpose(note.PerfectFifth), k.Mode)
+}
+
+// Subdominant returns the previous key in the circle of fifths, with one less
+// sharp or one more flat, e.g. C major for G major
+func (k Key) Subdominant() Key {
+	return New(k.Tonic.Transpose(note.PerfectFourth), k.Mode)
+}
+
+// Relative returns the key with the same key signature and a different
+// tonic. Major keys return their relative minor, e.g. E minor for G major.
+// The rest of modes return their relative major, e.g. G major for E minor or
+// C major for D dorian
+func (k Key) Relative() Key {
+	if k.Mode == Major {
+		return New(k.Tonic.Transpose(Minor.fromMajor()), Minor)
+	}
+	return New(k.Tonic.TransposeDown(k.Mode.fromMajor()), Major)
+}
+
+// Parallel returns the key with the same tonic, minor for major keys and
+// major for the rest of modes, e.g. E major for E minor
+func (k Key) Parallel() Key {
+	if k.Mode == Major {
+		return New(k.Tonic, Minor)
+	}
+	return New(k.Tonic, Major)
+}
+
+// Spell returns the pitch spelled as it belongs to the key. Minor keys also
+// spell the raised 6th and 7th degrees of the melodic and harmonic minor
+// scales with sharps or naturals, e.g. C# in D minor. The rest of pitches
+// outside of the key are spelled following the key signature. ok is false
+// for rests
+func (k Key) Spell(p note.Pitch) (s note.SpelledPitch, ok bool) {
+	if p.Key() < 0 {
+		return note.SpelledPitch{}, false
+	}
+
+	scale := k.Scale()
+	candidates := append([]note.SpelledPitch{}, scale[:len(scale)-1]...)
+
+	if k.Mode == Minor {
+		for _, degree := range []int{5, 6} {
+			raised := scale[degree]
+			raised.Accidental++
+			candidates = append(candidates, raised)
+		}
+	}
+
+	octave := int(note.Octave)
+	for _, c := range candidates {
+		diff := p.Key() - c.Key()
+		if diff%octave == 0 {
+			c.Octave += diff / octave
+			return c, true
+		}
+	}
+
+	return k.Signature().Spell(p)
+}
+
+// parseModes are the mode names accepted by Parse, including the 3 letter
+// abbreviations used by ABC notation
+var parseModes = map[string]Mode{
+	"":           Major,
+	"m":          Minor,
+	"maj":        Major,
+	"major":      Major,
+	"min":        Minor,
+	"minor":      Minor,
+	"ion":        Ionian,
+	"ionian":     Ionian,
+	"dor":        Dorian,
+	"dorian":     Dorian,
+	"phr":        Phrygian,
+	"phrygian":   Phrygian,
+	"lyd":        Lydian,
+	"lydian":     Lydian,
+	"mix":        Mixolydian,
+	"mixolydian": Mixolydian,
+	"aeo":        Aeolian,
+	"aeolian":    Aeolian,
+	"loc":        Locrian,
+	"locrian":    Locrian,
+}
+
+// Parse reads a key name like "E minor", "Bb", "F#m" or "D dorian". The mode
+// is case insensitive, and it can be abbreviated to 3 letters
+func Parse(s string) (Key, error) {
+	name := strings.TrimSpace(s)
+	if name == "" {
+		return Key{}, fmt.Errorf("invalid key %q: empty name", s)
+	}
+
+	// The tonic is a letter and its accidentals
+	end := 1
+	for end < len(name) && strings.ContainsAny(name[end:end+1], "#b") {
+		end++
+	}
+
+	tonic, err := note.ParseSpelledPitch(fmt.Sprintf("%s%d", name[:end], tonicOctave))
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid key %q: wrong tonic %q", s, name[:end])
+	}
+
+	// "m" and "M" are the only case sensitive modes
+	mode := strings.TrimSpace(name[end:])
+	if mode == "M" {
+		mode = "maj"
+	}
+
+	m, ok := parseModes[mode]
+	if !ok {
+		m, ok = parseModes[strings.ToLower(mode)]
+	}
+	if !ok {
+		return Key{}, fmt.Errorf("invalid key %q: unknown mode %q", s, mode)
+	}
+
+	return New(tonic, m), nil
+}
@@ -0,0 +1,188 @@
+package key_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustParse returns the parsed key, or fails the test
+func mustParse(t *testing.T, s string) key.Key {
+	k, err := key.Parse(s)
+	require.NoError(t, err)
+	return k
+}
+
+// names returns the note names separated by spaces
+func names(notes []note.Note) string {
+	s := make([]string, len(notes))
+	for i, n := range notes {
+		s[i] = n.Pitch.String()
+		if n.Key() < 0 {
+			s[i] = "r"
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+func TestParse(t *testing.T) {
+	for s, expected := range map[string]string{
+		"E minor":  "E minor",
+		"Em":       "E minor",
+		"Emin":     "E minor",
+		"Bb":       "Bb major",
+		"BbM":      "Bb major",
+		"bb major": "Bb major",
+		"F#m":      "F# minor",
+		"D dorian": "D dorian",
+		"Ddor":     "D dorian",
+		"G Mix":    "G mixolydian",
+		"C# Aeo":   "C# minor",
+		"Cb":       "Cb major",
+	} {
+		k, err := key.Parse(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, k.String(), s)
+		}
+	}
+
+	for _, s := range []string{"", "H", "C foo", "Cmm", "E minorr"} {
+		_, err := key.Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	for s, expected := range map[string]note.KeySignature{
+		"C":         0,
+		"G":         1,
+		"E minor":   1,
+		"Bb":        -2,
+		"G minor":   -2,
+		"C# major":  7,
+		"Cb major":  -7,
+		"D dorian":  0,
+		"F lydian":  0,
+		"E phr":     0,
+		"A mix":     2,
+		"B locrian": 0,
+	} {
+		assert.Equal(t, expected, mustParse(t, s).Signature(), s)
+	}
+}
+
+func TestFromSignature(t *testing.T) {
+	for _, test := range []struct {
+		sig      note.KeySignature
+		mode     key.Mode
+		expected string
+	}{
+		{0, key.Major, "C major"},
+		{1, key.Minor, "E minor"},
+		{-2, key.Major, "Bb major"},
+		{-2, key.Minor, "G minor"},
+		{7, key.Major, "C# major"},
+		{-7, key.Major, "Cb major"},
+		{7, key.Minor, "A# minor"},
+		{-7, key.Minor, "Ab minor"},
+		{0, key.Dorian, "D dorian"},
+		{2, key.Mixolydian, "A mixolydian"},
+		{-1, key.Locrian, "E locrian"},
+	} {
+		k := key.FromSignature(test.sig, test.mode)
+		assert.Equal(t, test.expected, k.String())
+		assert.Equal(t, test.sig, k.Signature(), test.expected)
+		assert.Equal(t, mustParse(t, test.expected), k)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	g := mustParse(t, "G")
+	assert.Equal(t, "D major", g.Dominant().String())
+	assert.Equal(t, "C major", g.Subdominant().String())
+	assert.Equal(t, "E minor", g.Relative().String())
+	assert.Equal(t, "G minor", g.Parallel().String())
+
+	em := mustParse(t, "E minor")
+	assert.Equal(t, "B minor", em.Dominant().String())
+	assert.Equal(t, "A minor", em.Subdominant().String())
+	assert.Equal(t, "G major", em.Relative().String())
+	assert.Equal(t, "E major", em.Parallel().String())
+
+	assert.Equal(t, "C major", mustParse(t, "D dorian").Relative().String())
+	assert.Equal(t, "Ab major", mustParse(t, "Eb major").Subdominant().String())
+	assert.Equal(t, "C# major", mustParse(t, "F# major").Dominant().String())
+
+	// The tonic octave is not relevant
+	assert.Equal(t, em, key.New(note.NewSpelledPitch(note.E, note.Natural, 2), key.Minor))
+}
+
+func TestScale(t *testing.T) {
+	var s []string
+	for _, p := range mustParse(t, "Ab minor").Scale() {
+		s = append(s, p.String())
+	}
+	assert.Equal(t, "Ab4 Bb4 Cb5 Db5 Eb5 Fb5 Gb5 Ab5", strings.Join(s, " "))
+}
+
+func TestSpell(t *testing.T) {
+	dm := mustParse(t, "D minor")
+	assert.Equal(t, "Bb3", spelled(dm.Spell(note.Asharp3)))
+	assert.Equal(t, "C#5", spelled(dm.Spell(note.Csharp5)))
+	assert.Equal(t, "B4", spelled(dm.Spell(note.B4)))
+	assert.Equal(t, "Eb4", spelled(dm.Spell(note.Dsharp4)))
+
+	fsharp := mustParse(t, "F# major")
+	assert.Equal(t, "E#4", spelled(fsharp.Spell(note.F4)))
+
+	assert.Equal(t, "rest", spelled(dm.Spell(note.NewRest(note.Quarter).Pitch)))
+}
+
+// spelled returns the name of a spelled pitch, or "rest"
+func spelled(s note.SpelledPitch, ok bool) string {
+	if !ok {
+		return "rest"
+	}
+	return s.String()
+}
+
+func TestTranspose(t *testing.T) {
+	// The beginning of the marble machine treble staff
+	melody := []note.Note{
+		note.NewNote(note.E6, note.Quarter),
+		note.NewNote(note.E5, note.Eighth),
+		note.NewNote(note.B5, note.Eighth),
+		note.NewRest(note.Quarter),
+		note.NewNote(note.Fsharp5, note.Eighth),
+		note.NewNote(note.C6, note.Eighth),
+		note.NewNote(note.Dsharp5, note.Eighth),
+	}
+
+	em := mustParse(t, "E minor")
+
+	transposed := em.Transpose(melody, mustParse(t, "D minor"))
+	assert.Equal(t, "D6 D5 A5 r E5 Bb5 C#5", names(transposed))
+	assert.Equal(t, note.Eighth, transposed[6].Duration)
+
+	transposed = em.Transpose(melody, mustParse(t, "A minor"))
+	assert.Equal(t, "A6 A5 E6 r B5 F6 G#5", names(transposed))
+
+	transposed = em.Transpose(melody, mustParse(t, "Eb minor"))
+	assert.Equal(t, "Eb6 Eb5 Bb5 r F5 Cb6 D5", names(transposed))
+
+	// Mode change, each degree keeps its position in the scale
+	transposed = em.Transpose(melody[:6], mustParse(t, "G major"))
+	assert.Equal(t, "G6 G5 D6 r A5 E6", names(transposed))
+
+	// The original is not modified
+	assert.Equal(t, note.E6, melody[0].Pitch)
+
+	staff := em.TransposeStaff([][]note.Note{melody[:2], melody[2:]}, mustParse(t, "D minor"))
+	require.Len(t, staff, 2)
+	assert.Equal(t, "D6 D5", names(staff[0]))
+	assert.Equal(t, "A5 r E5 Bb5 C#5", names(staff[1]))
+}
@@ -0,0 +1,61 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/scale"
+)
+
+// Mode is the scale a key is based on, one of the modes of the major scale
+type Mode int
+
+// Modes of the major scale, in the order of their starting degree
+const (
+	Ionian Mode = iota
+	Dorian
+	Phrygian
+	Lydian
+	Mixolydian
+	Aeolian
+	Locrian
+)
+
+const (
+	// Major is the Ionian mode
+	Major = Ionian
+	// Minor is the Aeolian mode, or natural minor
+	Minor = Aeolian
+)
+
+// modeNames are the names used by String
+var modeNames = [...]string{
+	"major", "dorian", "phrygian", "lydian", "mixolydian", "minor", "locrian"}
+
+// String returns the mode name, e.g. "minor"
+func (m Mode) String() string {
+	if m < Ionian || m > Locrian {
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// Scale returns the scale pattern for the mode
+func (m Mode) Scale() scale.Scale {
+	return scale.Major.Mode(int(m) + 1)
+}
+
+// fifths returns the difference in the key signature with the major key of
+// the same tonic, e.g. -3 for minor: C major has no flats, C minor has 3
+func (m Mode) fifths() int {
+	return [...]int{0, -2, -4, 1, -1, -3, -5}[m]
+}
+
+// fromMajor returns the interval from the tonic of the relative major key
+// to the tonic of this mode, e.g. a major sixth from C major to A minor
+func (m Mode) fromMajor() note.DiatonicInterval {
+	return [...]note.DiatonicInterval{
+		note.PerfectUnison, note.MajorSecond, note.MajorThird, note.PerfectFourth,
+		note.PerfectFifth, note.MajorSixth, note.MajorSeventh,
+	}[m]
+}
@@ -0,0 +1,66 @@
+package key
+
+import (
+	"github.com/carlosms/music-playground/theory/internal/intmath"
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// diatonicSteps is the number of letter names in an octave
+const diatonicSteps = 7
+
+// Transpose returns a copy of the notes moved from this key to the key to.
+// Each note keeps its scale degree and its chromatic alteration, so the
+// result is spelled correctly in the new key, e.g. the leading tone D# in E
+// minor becomes C# in D minor. The notes move to the closest tonic, up or
+// down. When the modes are different the melody is also adapted to the new
+// mode, degree by degree. Rests are not modified
+func (k Key) Transpose(notes []note.Note, to Key) []note.Note {
+	from := k.Scale()
+	target := to.Scale()
+
+	// letter steps between the tonics, to the closest one
+	steps := (int(to.Tonic.Letter) - int(k.Tonic.Letter) + diatonicSteps) % diatonicSteps
+	if steps > diatonicSteps/2 {
+		steps -= diatonicSteps
+	}
+
+	transposed := make([]note.Note, len(notes))
+	for i, n := range notes {
+		transposed[i] = n
+		s, ok := k.Spell(n.Pitch)
+		if !ok {
+			continue
+		}
+
+		degree := (int(s.Letter) - int(k.Tonic.Letter) + diatonicSteps) % diatonicSteps
+
+		// chromatic alteration relative to the scale degree
+		octave := int(note.Octave)
+		alteration := ((s.Key()-from[degree].Key())%octave + octave) % octave
+		if alteration > octave/2 {
+			alteration -= octave
+		}
+
+		step := (s.Octave+1)*diatonicSteps + int(s.Letter) + steps
+		t := note.SpelledPitch{
+			Letter: note.Letter((step%diatonicSteps + diatonicSteps) % diatonicSteps),
+			Octave: intmath.FloorDiv(step, diatonicSteps) - 1,
+		}
+		t.Accidental = target[degree].Accidental + note.Accidental(alteration)
+
+		transposed[i].Pitch = t
+	}
+
+	return transposed
+}
+
+// TransposeStaff returns a copy of a staff made of groups of simultaneous
+// notes, moved from this key to the key to. See Transpose
+func (k Key) TransposeStaff(staff [][]note.Note, to Key) [][]note.Note {
+	transposed := make([][]note.Note, len(staff))
+	for i, group := range staff {
+		transposed[i] = k.Transpose(group, to)
+	}
+
+	return transposed
+}
@@ -2,6 +2,8 @@ package note
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,65 +27,195 @@ const (
 
 // ToSeconds returns the note value as time.Duration. tempoNote is the note in
 // the tempo marking, or the _beat_ in _beats per minute_.
+// The value is calculated with exact fractions, see Rational.ToTime
 func (d Duration) ToSeconds(tempoNote Duration, bpm int) time.Duration {
-	// duration for each tempoNote
-	timeBeat := float64(time.Minute) / float64(bpm)
-	// convert to the d note value
-	t := float64(d) * (timeBeat / float64(tempoNote))
+	return d.Rational().ToTime(tempoNote, bpm)
+}
+
+// Dotted returns the duration with a dot, one and a half times longer
+func (d Duration) Dotted() Duration {
+	return d.Dots(1)
+}
 
-	return time.Duration(t)
+// DoubleDotted returns the duration with 2 dots, one and three quarters times
+// longer
+func (d Duration) DoubleDotted() Duration {
+	return d.Dots(2)
+}
+
+// Dots returns the duration with n dots. Each dot adds half the value of the
+// previous one
+func (d Duration) Dots(n int) Duration {
+	add := d
+	for i := 0; i < n; i++ {
+		add /= 2
+		d += add
+	}
+	return d
+}
+
+// Triplet returns the duration of one of 3 notes played in the time of 2
+func (d Duration) Triplet() Duration {
+	return d.Tuplet(3, 2)
+}
+
+// Tuplet returns the duration of one of n notes played in the time of m, e.g.
+// Tuplet(5, 4) for a quintuplet
+func (d Duration) Tuplet(n, m int) Duration {
+	return d * Duration(m) / Duration(n)
+}
+
+// Sum returns the total duration. It is exact for dotted notes and tuplets,
+// e.g. 3 triplet quarters sum exactly a half note
+func Sum(durations ...Duration) Duration {
+	var total Rational
+	for _, d := range durations {
+		total = total.Add(d.Rational())
+	}
+	return total.Duration()
 }
 
-// String returns the musical note symbol
+var (
+	// noteGlyphs are the note symbols for the standard durations
+	noteGlyphs = map[Duration]string{
+		Double:    "𝅜",
+		Whole:     "𝅝",
+		Half:      "𝅗𝅥",
+		Quarter:   "♩",
+		Eighth:    "♪",
+		Sixteenth: "𝅘𝅥𝅯",
+	}
+	// restGlyphs are the rest symbols for the standard durations
+	restGlyphs = map[Duration]string{
+		Double:    "𝄺",
+		Whole:     "𝄻",
+		Half:      "𝄼",
+		Quarter:   "𝄽",
+		Eighth:    "𝄾",
+		Sixteenth: "𝄿",
+	}
+)
+
+// maxDots is the number of dots tried by String
+const maxDots = 3
+
+// tupletNumbers are the tuplets tried by String. Other tuplets have the
+// same duration as one of these, e.g. a sextuplet and a triplet
+var tupletNumbers = []int{3, 5, 7, 9, 11, 13}
+
+// superscripts are the digits used to mark tuplets
+var superscripts = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+// String returns the musical note symbol. Dotted durations add a dot for
+// each one, e.g. "♩.", and tuplets add the tuplet number, e.g. "♪³"
 func (d Duration) String() string {
-	switch d {
-	case Double:
-		return "𝅜"
-	case Whole:
-		return "𝅝"
-	case Half:
-		return "𝅗𝅥"
-	case Quarter:
-		return "♩"
-	case Eighth:
-		return "♪"
-	case Sixteenth:
-		return "𝅘𝅥𝅯"
-	default:
-		var s string
-		f := float64(d)
-		if f < 1 {
-			s = fmt.Sprintf("1/%v note", 1/f)
-		} else {
-			s = fmt.Sprintf("%v note", f)
+	return d.symbol(noteGlyphs, "note")
+}
+
+// StringRest returns the rest note symbol, with dots and tuplet numbers as
+// String
+func (d Duration) StringRest() string {
+	return d.symbol(restGlyphs, "rest")
+}
+
+// symbol returns the symbol for the duration, or a description like "1/32
+// note" if it can't be written with the standard symbols
+func (d Duration) symbol(symbols map[Duration]string, name string) string {
+	if s, ok := symbols[d]; ok {
+		return s
+	}
+
+	if base, dots, tuplet, ok := d.decompose(standardDurations); ok {
+		s := symbols[base] + strings.Repeat(".", dots)
+		if tuplet != 0 {
+			s += superscript(tuplet)
 		}
 		return s
 	}
+
+	f := float64(d)
+	if f < 1 {
+		return fmt.Sprintf("1/%v %s", 1/f, name)
+	}
+	return fmt.Sprintf("%v %s", f, name)
 }
 
-// StringRest returns the rest note symbol
-func (d Duration) StringRest() string {
-	switch d {
-	case Double:
-		return "𝄺"
-	case Whole:
-		return "𝄻"
-	case Half:
-		return "𝄼"
-	case Quarter:
-		return "𝄽"
-	case Eighth:
-		return "𝄾"
-	case Sixteenth:
-		return "𝄿"
-	default:
-		var s string
-		f := float64(d)
-		if f < 1 {
-			s = fmt.Sprintf("1/%v rest", 1/f)
-		} else {
-			s = fmt.Sprintf("%v rest", f)
+// Notation is how a duration is written with a single note: a note value
+// with dots, in a tuplet of Tuplet notes played in the time of Normal ones
+type Notation struct {
+	Value Duration
+	Dots  int
+	// Tuplet and Normal are 0 for notes that are not in a tuplet
+	Tuplet int
+	Normal int
+}
+
+// notationValues are the note values used by Notation, from the double
+// whole note to the 128th note
+var notationValues = []Duration{Double, Whole, Half, Quarter, Eighth, Sixteenth,
+	Sixteenth / 2, Sixteenth / 4, Sixteenth / 8}
+
+// Notation returns how the duration is written with a single note, or false
+// if it needs tied notes, e.g. a quarter tied to a sixteenth. Dotted notes
+// are preferred to tuplets. Notes have up to 2 dots, and 1 in tuplets
+func (d Duration) Notation() (Notation, bool) {
+	value, dots, tuplet, ok := d.decompose(notationValues)
+	if !ok || dots > 2 || (tuplet != 0 && dots > 1) {
+		return Notation{}, false
+	}
+
+	n := Notation{Value: value, Dots: dots}
+	if tuplet != 0 {
+		n.Tuplet, n.Normal = tuplet, tupletSpan(tuplet)
+	}
+	return n, true
+}
+
+// decompose returns the note value, number of dots and tuplet number that
+// make this duration, or false if there is none. Dotted notes are preferred
+// to tuplets
+func (d Duration) decompose(values []Duration) (Duration, int, int, bool) {
+	r := d.Rational()
+
+	for _, base := range values {
+		for dots := 0; dots <= maxDots; dots++ {
+			if base.Dots(dots).Rational() == r {
+				return base, dots, 0, true
+			}
 		}
-		return s
 	}
+
+	for _, n := range tupletNumbers {
+		factor := NewRational(int64(tupletSpan(n)), int64(n))
+
+		for _, base := range values {
+			for dots := 0; dots <= maxDots; dots++ {
+				if base.Dots(dots).Rational().Mul(factor) == r {
+					return base, dots, n, true
+				}
+			}
+		}
+	}
+
+	return 0, 0, 0, false
+}
+
+// tupletSpan returns the number of notes in the time of which a tuplet of n
+// notes is played, the previous power of two, e.g. 2 for triplets and 4 for
+// quintuplets
+func tupletSpan(n int) int {
+	m := 1
+	for m*2 < n {
+		m *= 2
+	}
+	return m
+}
+
+// superscript returns the number written with superscript digits
+func superscript(n int) string {
+	var s []rune
+	for _, c := range strconv.Itoa(n) {
+		s = append(s, superscripts[c-'0'])
+	}
+	return string(s)
 }
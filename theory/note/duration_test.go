@@ -48,3 +48,112 @@ func TestDurationStringRest(t *testing.T) {
 	assert.Equal(t, `𝄾`, note.Eighth.StringRest())
 	assert.Equal(t, `𝄿`, note.Sixteenth.StringRest())
 }
+
+func TestDottedDurations(t *testing.T) {
+	assert.Equal(t, note.Quarter+note.Eighth, note.Quarter.Dotted())
+	assert.Equal(t, note.Half+note.Quarter+note.Eighth, note.Half.DoubleDotted())
+	assert.Equal(t, note.Whole, note.Whole.Dots(0))
+
+	assert.Equal(t, note.Quarter.String()+".", note.Quarter.Dotted().String())
+	assert.Equal(t, note.Half.String()+"..", note.Half.DoubleDotted().String())
+	assert.Equal(t, note.Eighth.String()+"...", note.Eighth.Dots(3).String())
+	assert.Equal(t, note.Whole.StringRest()+".", note.Whole.Dotted().StringRest())
+
+	// A dotted half is 3 quarters
+	assert.Equal(t, note.Half.Dotted(), note.Sum(note.Quarter, note.Quarter, note.Quarter))
+}
+
+func TestTuplets(t *testing.T) {
+	triplet := note.Eighth.Triplet()
+	assert.Equal(t, note.Eighth.String()+"³", triplet.String())
+	assert.Equal(t, note.Eighth.StringRest()+"³", triplet.StringRest())
+	assert.Equal(t, note.Sixteenth.String()+"⁵", note.Sixteenth.Tuplet(5, 4).String())
+	assert.Equal(t, note.Quarter.String()+"⁷", note.Quarter.Tuplet(7, 4).String())
+	assert.Equal(t, note.Half.String()+".⁵", note.Half.Dotted().Tuplet(5, 4).String())
+
+	// A dotted quarter triplet is a quarter
+	assert.Equal(t, note.Quarter.String(), note.Quarter.Dotted().Triplet().String())
+
+	// Sextuplets have the same duration as triplets
+	assert.Equal(t, note.Sixteenth.String()+"³", note.Sixteenth.Tuplet(6, 4).String())
+	assert.Equal(t, note.Sixteenth.Triplet(), note.Sixteenth.Tuplet(6, 4))
+}
+
+func TestNotation(t *testing.T) {
+	for _, test := range []struct {
+		d        note.Duration
+		expected note.Notation
+	}{
+		{note.Whole, note.Notation{Value: note.Whole}},
+		{note.Quarter.Dotted(), note.Notation{Value: note.Quarter, Dots: 1}},
+		{note.Sixteenth / 2, note.Notation{Value: note.Sixteenth / 2}},
+		{note.Sixteenth.Dotted() / 4, note.Notation{Value: note.Sixteenth / 4, Dots: 1}},
+		{note.Eighth.Triplet(), note.Notation{Value: note.Eighth, Tuplet: 3, Normal: 2}},
+		{note.Half.Dotted().Tuplet(5, 4), note.Notation{Value: note.Half, Dots: 1, Tuplet: 5, Normal: 4}},
+		{note.Half.Triplet() * 2, note.Notation{Value: note.Whole, Tuplet: 3, Normal: 2}},
+		// Dotted notes are preferred
+		{note.Quarter.Dotted().Triplet(), note.Notation{Value: note.Quarter}},
+	} {
+		n, ok := test.d.Notation()
+		assert.True(t, ok, test.d.String())
+		assert.Equal(t, test.expected, n, test.d.String())
+	}
+
+	for _, d := range []note.Duration{note.Quarter + note.Sixteenth, note.Whole * 5, note.Sixteenth / 16} {
+		_, ok := d.Notation()
+		assert.False(t, ok, d.String())
+	}
+}
+
+func TestSum(t *testing.T) {
+	// A bar of triplet quarters adds up to exactly one whole note
+	var bar []note.Duration
+	for i := 0; i < 6; i++ {
+		bar = append(bar, note.Quarter.Triplet())
+	}
+	assert.Equal(t, note.Whole, note.Sum(bar...))
+
+	var float note.Duration
+	for _, d := range bar {
+		float += d
+	}
+	assert.NotEqual(t, note.Whole, float)
+
+	// Quintuplets and septuplets mixed with regular notes
+	assert.Equal(t, note.Whole, note.Sum(
+		note.Sixteenth.Tuplet(5, 4), note.Sixteenth.Tuplet(5, 4), note.Sixteenth.Tuplet(5, 4),
+		note.Sixteenth.Tuplet(5, 4), note.Sixteenth.Tuplet(5, 4),
+		note.Eighth.Tuplet(7, 4), note.Eighth.Tuplet(7, 4), note.Eighth.Tuplet(7, 4),
+		note.Eighth.Tuplet(7, 4), note.Eighth.Tuplet(7, 4), note.Eighth.Tuplet(7, 4),
+		note.Eighth.Tuplet(7, 4),
+		note.Quarter))
+
+	assert.Equal(t, note.Duration(0), note.Sum())
+}
+
+func TestTies(t *testing.T) {
+	n := note.NewNote(note.C4, note.Half).Tied()
+	assert.True(t, n.Tie)
+	assert.Equal(t, note.Half.String()+" C4~", n.String())
+
+	// Ties are kept by Add
+	assert.True(t, n.Add(note.Tone).Tie)
+
+	merged := note.MergeTies([]note.Note{
+		note.NewNote(note.C4, note.Half).Tied(),
+		note.NewNote(note.C4, note.Eighth.Triplet()).Tied(),
+		note.NewNote(note.C4, note.Quarter.Triplet()),
+		note.NewNote(note.D4, note.Quarter).Tied(),
+		note.NewNote(note.E4, note.Quarter).Tied(),
+		note.NewRest(note.Quarter),
+		note.NewNote(note.F4, note.Quarter).Tied(),
+	})
+
+	assert.Equal(t, []note.Note{
+		note.NewNote(note.C4, note.Half.Dotted()),
+		note.NewNote(note.D4, note.Quarter),
+		note.NewNote(note.E4, note.Quarter),
+		note.NewRest(note.Quarter),
+		note.NewNote(note.F4, note.Quarter),
+	}, merged)
+}
@@ -0,0 +1,177 @@
+package note
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dynamic is the loudness of the music, from pianississimo (ppp) to
+// fortississimo (fff)
+type Dynamic int
+
+const (
+	// NoDynamic is used for notes that keep the previous dynamic
+	NoDynamic Dynamic = iota
+	// Pianississimo is ppp
+	Pianississimo
+	// Pianissimo is pp
+	Pianissimo
+	// Piano is p
+	Piano
+	// MezzoPiano is mp
+	MezzoPiano
+	// MezzoForte is mf
+	MezzoForte
+	// Forte is f
+	Forte
+	// Fortissimo is ff
+	Fortissimo
+	// Fortississimo is fff
+	Fortississimo
+)
+
+// DefaultDynamic is used before the first dynamic marking
+const DefaultDynamic = MezzoForte
+
+var dynamicNames = []string{"", "ppp", "pp", "p", "mp", "mf", "f", "ff", "fff"}
+
+// velocities are the MIDI velocities of each dynamic
+var velocities = []int{0, 16, 32, 48, 64, 80, 96, 112, 127}
+
+// String returns the dynamic marking, e.g. "mf"
+func (d Dynamic) String() string {
+	if d < NoDynamic || d > Fortississimo {
+		return fmt.Sprintf("Dynamic(%d)", int(d))
+	}
+	return dynamicNames[d]
+}
+
+// Velocity returns the MIDI velocity of the dynamic, from 16 for ppp to 127
+// for fff. NoDynamic returns the velocity of DefaultDynamic
+func (d Dynamic) Velocity() int {
+	if d <= NoDynamic || d > Fortississimo {
+		return velocities[DefaultDynamic]
+	}
+	return velocities[d]
+}
+
+// ParseDynamic returns the Dynamic for a marking from "ppp" to "fff"
+func ParseDynamic(s string) (Dynamic, error) {
+	for d := Pianississimo; d <= Fortississimo; d++ {
+		if dynamicNames[d] == s {
+			return d, nil
+		}
+	}
+	return NoDynamic, fmt.Errorf("%q is not a dynamic marking, use one of ppp, pp, p, mp, mf, f, ff or fff", s)
+}
+
+// Hairpin is a gradual change of dynamics
+type Hairpin int
+
+const (
+	// NoHairpin keeps the dynamic
+	NoHairpin Hairpin = iota
+	// Crescendo gets gradually louder, written as <
+	Crescendo
+	// Diminuendo gets gradually softer, written as >
+	Diminuendo
+)
+
+// String returns the hairpin symbol, "<" or ">"
+func (h Hairpin) String() string {
+	switch h {
+	case Crescendo:
+		return "<"
+	case Diminuendo:
+		return ">"
+	}
+	return ""
+}
+
+// Articulation is how a note is played. Articulations are flags that can be
+// combined, e.g. Staccato | Accent
+type Articulation uint8
+
+const (
+	// Staccato notes are played short, detached from the next one
+	Staccato Articulation = 1 << iota
+	// Legato notes are played smoothly connected to the next one
+	Legato
+	// Accent notes are played louder
+	Accent
+	// Tenuto notes are held for their full value, slightly stressed
+	Tenuto
+	// Marcato notes are played louder than accents, and slightly detached
+	Marcato
+)
+
+var articulationNames = []string{"staccato", "legato", "accent", "tenuto", "marcato"}
+
+// Has returns true if all the articulations in o are set
+func (a Articulation) Has(o Articulation) bool {
+	return a&o == o
+}
+
+// String returns the names of the articulations separated by spaces, e.g.
+// "staccato accent"
+func (a Articulation) String() string {
+	var names []string
+	for i, name := range articulationNames {
+		if a.Has(1 << uint(i)) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// Length returns the fraction of the written duration that is played, e.g.
+// 0.5 for Staccato
+func (a Articulation) Length() float64 {
+	switch {
+	case a.Has(Staccato):
+		return 0.5
+	case a.Has(Marcato):
+		return 0.75
+	}
+	return 1
+}
+
+// Emphasis returns how much louder the articulation makes a note, as a
+// factor of its velocity
+func (a Articulation) Emphasis() float64 {
+	e := 1.0
+	if a.Has(Accent) {
+		e *= 1.25
+	}
+	if a.Has(Marcato) {
+		e *= 1.4
+	}
+	if a.Has(Tenuto) {
+		e *= 1.1
+	}
+	return e
+}
+
+// WithDynamic returns a copy of the note with a dynamic marking
+func (n Note) WithDynamic(d Dynamic) Note {
+	n.Dynamic = d
+	return n
+}
+
+// Crescendo returns a copy of the note starting a crescendo
+func (n Note) Crescendo() Note {
+	n.Hairpin = Crescendo
+	return n
+}
+
+// Diminuendo returns a copy of the note starting a diminuendo
+func (n Note) Diminuendo() Note {
+	n.Hairpin = Diminuendo
+	return n
+}
+
+// Articulate returns a copy of the note adding articulations
+func (n Note) Articulate(a Articulation) Note {
+	n.Articulation |= a
+	return n
+}
@@ -0,0 +1,72 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamics(t *testing.T) {
+	for s, d := range map[string]note.Dynamic{
+		"ppp": note.Pianississimo,
+		"p":   note.Piano,
+		"mf":  note.MezzoForte,
+		"fff": note.Fortississimo,
+	} {
+		parsed, err := note.ParseDynamic(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, d, parsed, s)
+			assert.Equal(t, s, d.String())
+		}
+	}
+
+	for _, s := range []string{"", "pppp", "sfz", "MF"} {
+		_, err := note.ParseDynamic(s)
+		assert.Error(t, err, s)
+	}
+
+	assert.Equal(t, 16, note.Pianississimo.Velocity())
+	assert.Equal(t, 80, note.MezzoForte.Velocity())
+	assert.Equal(t, 127, note.Fortississimo.Velocity())
+	assert.Equal(t, note.DefaultDynamic.Velocity(), note.NoDynamic.Velocity())
+
+	for d := note.Pianississimo; d < note.Fortississimo; d++ {
+		assert.True(t, d.Velocity() < (d+1).Velocity(), d.String())
+	}
+}
+
+func TestArticulation(t *testing.T) {
+	a := note.Staccato | note.Accent
+	assert.True(t, a.Has(note.Staccato))
+	assert.True(t, a.Has(note.Staccato|note.Accent))
+	assert.False(t, a.Has(note.Staccato|note.Tenuto))
+	assert.Equal(t, "staccato accent", a.String())
+	assert.Equal(t, "", note.Articulation(0).String())
+
+	assert.Equal(t, 0.5, a.Length())
+	assert.Equal(t, 0.75, note.Marcato.Length())
+	assert.Equal(t, 1.0, note.Legato.Length())
+	assert.Equal(t, 1.0, note.Tenuto.Length())
+
+	assert.Equal(t, 1.0, note.Staccato.Emphasis())
+	assert.True(t, note.Accent.Emphasis() > note.Tenuto.Emphasis())
+	assert.True(t, note.Marcato.Emphasis() > note.Accent.Emphasis())
+}
+
+func TestNoteMarkings(t *testing.T) {
+	n := note.NewNote(note.C4, note.Quarter).
+		WithDynamic(note.Piano).
+		Crescendo().
+		Articulate(note.Staccato).
+		Articulate(note.Accent)
+
+	assert.Equal(t, note.Piano, n.Dynamic)
+	assert.Equal(t, note.Crescendo, n.Hairpin)
+	assert.Equal(t, note.Staccato|note.Accent, n.Articulation)
+	assert.Equal(t, note.Diminuendo, n.Diminuendo().Hairpin)
+
+	// Markings are kept by Add
+	assert.Equal(t, note.Piano, n.Add(note.Tone).Dynamic)
+	assert.Equal(t, note.Quarter.String()+" C4", n.String())
+}
@@ -0,0 +1,325 @@
+package note
+
+import (
+	"fmt"
+)
+
+// IntervalQuality is the quality of a DiatonicInterval. Unisons, fourths,
+// fifths and octaves can be perfect, seconds, thirds, sixths and sevenths can
+// be major or minor. All of them can be augmented or diminished
+type IntervalQuality int8
+
+// Interval qualities
+const (
+	Perfect IntervalQuality = iota
+	Major
+	Minor
+	Augmented
+	Diminished
+	DoublyAugmented
+	DoublyDiminished
+)
+
+// String returns the quality name, e.g. "augmented"
+func (q IntervalQuality) String() string {
+	switch q {
+	case Perfect:
+		return "perfect"
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Augmented:
+		return "augmented"
+	case Diminished:
+		return "diminished"
+	case DoublyAugmented:
+		return "doubly augmented"
+	case DoublyDiminished:
+		return "doubly diminished"
+	default:
+		return fmt.Sprintf("IntervalQuality(%d)", q)
+	}
+}
+
+// symbol returns the quality abbreviation, e.g. "A" for augmented
+func (q IntervalQuality) symbol() string {
+	switch q {
+	case Perfect:
+		return "P"
+	case Major:
+		return "M"
+	case Minor:
+		return "m"
+	case Augmented:
+		return "A"
+	case Diminished:
+		return "d"
+	case DoublyAugmented:
+		return "AA"
+	case DoublyDiminished:
+		return "dd"
+	default:
+		return "?"
+	}
+}
+
+// DiatonicInterval is an interval named by its quality and its diatonic
+// number, e.g. a major third. Unlike Interval, it tells apart enharmonic
+// intervals like the augmented fourth and the diminished fifth
+type DiatonicInterval struct {
+	Quality IntervalQuality
+	// Number is the count of letter names spanned, both ends included: 1 for
+	// unison, 3 for a third, 8 for an octave, 9 for a ninth...
+	Number int
+}
+
+// Common simple intervals
+var (
+	PerfectUnison     = DiatonicInterval{Perfect, 1}
+	AugmentedUnison   = DiatonicInterval{Augmented, 1}
+	MinorSecond       = DiatonicInterval{Minor, 2}
+	MajorSecond       = DiatonicInterval{Major, 2}
+	AugmentedSecond   = DiatonicInterval{Augmented, 2}
+	MinorThird        = DiatonicInterval{Minor, 3}
+	MajorThird        = DiatonicInterval{Major, 3}
+	PerfectFourth     = DiatonicInterval{Perfect, 4}
+	AugmentedFourth   = DiatonicInterval{Augmented, 4}
+	DiminishedFifth   = DiatonicInterval{Diminished, 5}
+	PerfectFifth      = DiatonicInterval{Perfect, 5}
+	AugmentedFifth    = DiatonicInterval{Augmented, 5}
+	MinorSixth        = DiatonicInterval{Minor, 6}
+	MajorSixth        = DiatonicInterval{Major, 6}
+	DiminishedSeventh = DiatonicInterval{Diminished, 7}
+	MinorSeventh      = DiatonicInterval{Minor, 7}
+	MajorSeventh      = DiatonicInterval{Major, 7}
+	PerfectOctave     = DiatonicInterval{Perfect, 8}
+)
+
+// majorSemitones are the semitones of the major or perfect interval for
+// each simple number, from unison (index 0) to seventh
+var majorSemitones = [...]int{0, 2, 4, 5, 7, 9, 11}
+
+// NewDiatonicInterval returns a DiatonicInterval, or an error if the quality
+// does not apply to that number, e.g. a major fifth
+func NewDiatonicInterval(q IntervalQuality, number int) (DiatonicInterval, error) {
+	i := DiatonicInterval{q, number}
+	if number < 1 {
+		return i, fmt.Errorf("wrong interval number %d, it must be 1 or greater", number)
+	}
+
+	if _, ok := i.offset(); !ok {
+		return i, fmt.Errorf("%v is not a valid quality for a number %d interval", q, number)
+	}
+
+	if i.semitones() < 0 {
+		return i, fmt.Errorf("a %v unison is not a valid interval", q)
+	}
+
+	return i, nil
+}
+
+// isPerfect returns true for the numbers that have a perfect quality:
+// unisons, fourths, fifths, and their compounds
+func isPerfect(number int) bool {
+	switch (number - 1) % 7 {
+	case 0, 3, 4:
+		return true
+	default:
+		return false
+	}
+}
+
+// offset returns the difference in semitones with the major or perfect
+// interval of the same number. It returns false if the quality does not
+// apply to the interval number
+func (i DiatonicInterval) offset() (int, bool) {
+	if isPerfect(i.Number) {
+		switch i.Quality {
+		case Perfect:
+			return 0, true
+		case Augmented:
+			return 1, true
+		case Diminished:
+			return -1, true
+		case DoublyAugmented:
+			return 2, true
+		case DoublyDiminished:
+			return -2, true
+		}
+		return 0, false
+	}
+
+	switch i.Quality {
+	case Major:
+		return 0, true
+	case Minor:
+		return -1, true
+	case Augmented:
+		return 1, true
+	case Diminished:
+		return -2, true
+	case DoublyAugmented:
+		return 2, true
+	case DoublyDiminished:
+		return -3, true
+	}
+	return 0, false
+}
+
+// qualityFor returns the quality of an interval of the given number that is
+// offset semitones away from the major or perfect one
+func qualityFor(number, offset int) (IntervalQuality, bool) {
+	if isPerfect(number) {
+		switch offset {
+		case 0:
+			return Perfect, true
+		case 1:
+			return Augmented, true
+		case -1:
+			return Diminished, true
+		case 2:
+			return DoublyAugmented, true
+		case -2:
+			return DoublyDiminished, true
+		}
+		return 0, false
+	}
+
+	switch offset {
+	case 0:
+		return Major, true
+	case -1:
+		return Minor, true
+	case 1:
+		return Augmented, true
+	case -2:
+		return Diminished, true
+	case 2:
+		return DoublyAugmented, true
+	case -3:
+		return DoublyDiminished, true
+	}
+	return 0, false
+}
+
+// referenceSemitones returns the size in semitones of the major or perfect
+// interval with the given number
+func referenceSemitones(number int) int {
+	octaves := (number - 1) / 7
+	return octaves*int(Octave) + majorSemitones[(number-1)%7]
+}
+
+// semitones returns the size of the interval in semitones
+func (i DiatonicInterval) semitones() int {
+	offset, _ := i.offset()
+	return referenceSemitones(i.Number) + offset
+}
+
+// Semitones returns the size of the interval in semitones, to be used with
+// Pitch.Add and Pitch.Subtract
+func (i DiatonicInterval) Semitones() Interval {
+	return Interval(i.semitones())
+}
+
+// IsCompound returns true for intervals larger than an octave
+func (i DiatonicInterval) IsCompound() bool {
+	return i.Number > 8
+}
+
+// Simple returns the interval reduced to one octave or less, e.g. a major
+// third for a major tenth
+func (i DiatonicInterval) Simple() DiatonicInterval {
+	if i.Number > 8 {
+		i.Number = (i.Number-2)%7 + 2
+	}
+	return i
+}
+
+// Compound returns the interval widened by the given number of octaves,
+// e.g. a major ninth for a major second and 1 octave
+func (i DiatonicInterval) Compound(octaves int) DiatonicInterval {
+	i.Number += 7 * octaves
+	return i
+}
+
+// Invert returns the inversion of the simple interval, the interval that
+// added to it makes an octave: major becomes minor, augmented becomes
+// diminished, and perfect stays perfect. Compound intervals are reduced to
+// a simple one before the inversion
+func (i DiatonicInterval) Invert() DiatonicInterval {
+	i = i.Simple()
+	i.Number = 9 - i.Number
+
+	switch i.Quality {
+	case Major:
+		i.Quality = Minor
+	case Minor:
+		i.Quality = Major
+	case Augmented:
+		i.Quality = Diminished
+	case Diminished:
+		i.Quality = Augmented
+	case DoublyAugmented:
+		i.Quality = DoublyDiminished
+	case DoublyDiminished:
+		i.Quality = DoublyAugmented
+	}
+
+	return i
+}
+
+// numberNames are the names of the interval numbers up to two octaves
+var numberNames = [...]string{"",
+	"unison", "second", "third", "fourth", "fifth", "sixth", "seventh", "octave",
+	"ninth", "tenth", "eleventh", "twelfth", "thirteenth", "fourteenth", "fifteenth"}
+
+// String returns the interval name, e.g. "diminished fifth"
+func (i DiatonicInterval) String() string {
+	if i.Number > 0 && i.Number < len(numberNames) {
+		return fmt.Sprintf("%v %v", i.Quality, numberNames[i.Number])
+	}
+	return fmt.Sprintf("%v %dth", i.Quality, i.Number)
+}
+
+// ShortString returns the interval abbreviation, e.g. "d5" for a
+// diminished fifth or "M3" for a major third
+func (i DiatonicInterval) ShortString() string {
+	return fmt.Sprintf("%v%d", i.Quality.symbol(), i.Number)
+}
+
+// IntervalBetween returns the ascending interval between two spelled
+// pitches, from the lower to the higher one. It returns an error for
+// intervals that can't be named, like a triply augmented unison between
+// Cbb4 and C##4
+func IntervalBetween(a, b SpelledPitch) (DiatonicInterval, error) {
+	if b.step() < a.step() || (b.step() == a.step() && b.Key() < a.Key()) {
+		a, b = b, a
+	}
+
+	i := DiatonicInterval{Number: b.step() - a.step() + 1}
+
+	q, ok := qualityFor(i.Number, b.Key()-a.Key()-referenceSemitones(i.Number))
+	if !ok {
+		return i, fmt.Errorf("the interval between %v and %v can't be named", a, b)
+	}
+
+	i.Quality = q
+	return i, nil
+}
+
+// Transpose returns the spelled pitch the interval i above s, e.g. a major
+// third above Bb4 is D5, and a diminished fifth above B3 is F4
+func (s SpelledPitch) Transpose(i DiatonicInterval) SpelledPitch {
+	t := fromStep(s.step() + i.Number - 1)
+	t.Accidental = Accidental(s.Key() + i.semitones() - t.Key())
+	return t
+}
+
+// TransposeDown returns the spelled pitch the interval i below s, e.g. a
+// perfect fifth below D4 is G3
+func (s SpelledPitch) TransposeDown(i DiatonicInterval) SpelledPitch {
+	t := fromStep(s.step() - i.Number + 1)
+	t.Accidental = Accidental(s.Key() - i.semitones() - t.Key())
+	return t
+}
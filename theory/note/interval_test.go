@@ -0,0 +1,129 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiatonicIntervalSemitones(t *testing.T) {
+	assert.Equal(t, note.Interval(0), note.PerfectUnison.Semitones())
+	assert.Equal(t, note.Semitone, note.MinorSecond.Semitones())
+	assert.Equal(t, note.Tone, note.MajorSecond.Semitones())
+	assert.Equal(t, note.Interval(4), note.MajorThird.Semitones())
+	assert.Equal(t, note.Interval(6), note.AugmentedFourth.Semitones())
+	assert.Equal(t, note.Interval(6), note.DiminishedFifth.Semitones())
+	assert.Equal(t, note.Interval(9), note.DiminishedSeventh.Semitones())
+	assert.Equal(t, note.Octave, note.PerfectOctave.Semitones())
+	assert.Equal(t, note.Interval(14), note.MajorSecond.Compound(1).Semitones())
+	assert.Equal(t, note.Interval(17), note.PerfectFourth.Compound(1).Semitones())
+
+	assert.Equal(t, note.E4, note.C4.Add(note.MajorThird.Semitones()))
+}
+
+func TestNewDiatonicInterval(t *testing.T) {
+	i, err := note.NewDiatonicInterval(note.Minor, 6)
+	require.NoError(t, err)
+	assert.Equal(t, note.MinorSixth, i)
+
+	i, err = note.NewDiatonicInterval(note.DoublyDiminished, 7)
+	require.NoError(t, err)
+	assert.Equal(t, note.Interval(8), i.Semitones())
+
+	_, err = note.NewDiatonicInterval(note.Major, 5)
+	assert.Error(t, err)
+
+	_, err = note.NewDiatonicInterval(note.Perfect, 3)
+	assert.Error(t, err)
+
+	_, err = note.NewDiatonicInterval(note.Diminished, 1)
+	assert.Error(t, err)
+
+	_, err = note.NewDiatonicInterval(note.Perfect, 0)
+	assert.Error(t, err)
+}
+
+func TestDiatonicIntervalString(t *testing.T) {
+	assert.Equal(t, "major third", note.MajorThird.String())
+	assert.Equal(t, "diminished fifth", note.DiminishedFifth.String())
+	assert.Equal(t, "augmented fourth", note.AugmentedFourth.String())
+	assert.Equal(t, "perfect octave", note.PerfectOctave.String())
+	assert.Equal(t, "minor ninth", note.MinorSecond.Compound(1).String())
+	assert.Equal(t, "perfect 22th", note.PerfectOctave.Compound(2).String())
+
+	assert.Equal(t, "M3", note.MajorThird.ShortString())
+	assert.Equal(t, "d5", note.DiminishedFifth.ShortString())
+	assert.Equal(t, "A4", note.AugmentedFourth.ShortString())
+	assert.Equal(t, "P8", note.PerfectOctave.ShortString())
+	assert.Equal(t, "m10", note.MinorThird.Compound(1).ShortString())
+}
+
+func TestDiatonicIntervalInvert(t *testing.T) {
+	assert.Equal(t, note.MinorSixth, note.MajorThird.Invert())
+	assert.Equal(t, note.MajorThird, note.MinorSixth.Invert())
+	assert.Equal(t, note.DiminishedFifth, note.AugmentedFourth.Invert())
+	assert.Equal(t, note.PerfectFourth, note.PerfectFifth.Invert())
+	assert.Equal(t, note.PerfectOctave, note.PerfectUnison.Invert())
+	assert.Equal(t, note.PerfectUnison, note.PerfectOctave.Invert())
+	assert.Equal(t, note.MajorSeventh, note.MinorSecond.Compound(1).Invert())
+}
+
+func TestDiatonicIntervalCompound(t *testing.T) {
+	ninth := note.MajorSecond.Compound(1)
+	assert.True(t, ninth.IsCompound())
+	assert.False(t, note.PerfectOctave.IsCompound())
+	assert.Equal(t, 9, ninth.Number)
+	assert.Equal(t, note.MajorSecond, ninth.Simple())
+	assert.Equal(t, note.PerfectOctave, note.PerfectOctave.Compound(1).Simple())
+	assert.Equal(t, note.PerfectFifth, note.PerfectFifth.Simple())
+}
+
+func TestIntervalBetween(t *testing.T) {
+	c4 := note.NewSpelledPitch(note.C, note.Natural, 4)
+	e4 := note.NewSpelledPitch(note.E, note.Natural, 4)
+	b3 := note.NewSpelledPitch(note.B, note.Natural, 3)
+	f4 := note.NewSpelledPitch(note.F, note.Natural, 4)
+	fsharp4 := note.NewSpelledPitch(note.F, note.Sharp, 4)
+	gflat4 := note.NewSpelledPitch(note.G, note.Flat, 4)
+	bsharp3 := note.NewSpelledPitch(note.B, note.Sharp, 3)
+	d5 := note.NewSpelledPitch(note.D, note.Natural, 5)
+
+	for _, test := range []struct {
+		a, b     note.SpelledPitch
+		expected note.DiatonicInterval
+	}{
+		{c4, e4, note.MajorThird},
+		{e4, c4, note.MajorThird},
+		{c4, fsharp4, note.AugmentedFourth},
+		{c4, gflat4, note.DiminishedFifth},
+		{b3, f4, note.DiminishedFifth},
+		{c4, c4, note.PerfectUnison},
+		{bsharp3, c4, note.DiatonicInterval{Quality: note.Diminished, Number: 2}},
+		{c4, d5, note.MajorSecond.Compound(1)},
+		{e4, d5, note.MinorSeventh},
+	} {
+		i, err := note.IntervalBetween(test.a, test.b)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, i)
+	}
+
+	_, err := note.IntervalBetween(
+		note.NewSpelledPitch(note.C, note.DoubleFlat, 4),
+		note.NewSpelledPitch(note.C, note.DoubleSharp, 4))
+	assert.Error(t, err)
+}
+
+func TestSpelledPitchTranspose(t *testing.T) {
+	bflat4 := note.NewSpelledPitch(note.B, note.Flat, 4)
+	assert.Equal(t, note.NewSpelledPitch(note.D, note.Natural, 5), bflat4.Transpose(note.MajorThird))
+	assert.Equal(t, note.NewSpelledPitch(note.F, note.Flat, 5), bflat4.Transpose(note.DiminishedFifth))
+	assert.Equal(t, note.NewSpelledPitch(note.E, note.Natural, 5), bflat4.Transpose(note.AugmentedFourth))
+	assert.Equal(t, note.NewSpelledPitch(note.E, note.Flat, 4), bflat4.TransposeDown(note.PerfectFifth))
+	assert.Equal(t, note.NewSpelledPitch(note.B, note.Flat, 5), bflat4.Transpose(note.PerfectOctave))
+
+	c0 := note.NewSpelledPitch(note.C, note.Natural, 0)
+	assert.Equal(t, note.NewSpelledPitch(note.B, note.Flat, -1), c0.TransposeDown(note.MajorSecond))
+	assert.Equal(t, bflat4.Key()+4, bflat4.Transpose(note.MajorThird).Key())
+}
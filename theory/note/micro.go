@@ -0,0 +1,91 @@
+package note
+
+import (
+	"fmt"
+	"math"
+)
+
+// centsPerKey is the number of cents between consecutive MIDI keys
+const centsPerKey = 100
+
+// MicroPitch is a pitch between the MIDI keys, measured as a fractional key
+// number, e.g. 69.5 for a quarter tone above A4. It can represent pitch
+// bends, quarter tones, or pitches tuned to the cent. Its range is the MIDI
+// range, from 50 cents below C-1 to 50 cents above G9
+type MicroPitch float64
+
+// NewMicroPitch returns the pitch deviated the given cents from p, e.g.
+// NewMicroPitch(A4, 14) is 14 cents above A4. Pitches outside of the range
+// of MicroPitch are clamped to it
+func NewMicroPitch(p Pitch, cents float64) MicroPitch {
+	key := float64(p.Key())
+	if m, ok := p.(MicroPitch); ok {
+		key = float64(m)
+	}
+	return clampMicro(key + cents/centsPerKey)
+}
+
+// MicroPitchFromFrequency returns the pitch with the given hertz value in
+// standard tuning. Frequencies outside of the range of MicroPitch are
+// clamped to it
+func MicroPitchFromFrequency(freq float64) MicroPitch {
+	return clampMicro(float64(A4) + float64(Octave)*math.Log2(freq/A4.Frequency()))
+}
+
+// clampMicro returns the fractional key number clamped to the range of
+// MicroPitch
+func clampMicro(key float64) MicroPitch {
+	return MicroPitch(math.Max(float64(C_1)-0.5, math.Min(key, float64(G9)+0.5)))
+}
+
+// String returns the nearest key name followed by the deviation in cents,
+// e.g. "A4 +14c". The deviation is omitted when it rounds to 0 cents
+func (m MicroPitch) String() string {
+	// micro pitches have a key, they are never rests
+	s, _ := Sharps.Spell(m)
+	name := s.String()
+
+	cents := math.Round(m.Cents())
+	if cents == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s %+dc", name, int(cents))
+}
+
+// Frequency returns the hertz value for this pitch
+func (m MicroPitch) Frequency() float64 {
+	return A4.Frequency() * math.Pow(2, (float64(m)-float64(A4))/float64(Octave))
+}
+
+// Add returns a new Pitch adding an interval to this pitch, making it higher.
+// The cents deviation is kept
+func (m MicroPitch) Add(i Interval) Pitch {
+	return m + MicroPitch(i)
+}
+
+// Subtract returns a new Pitch subtracting an interval to this pitch, making
+// it lower. The cents deviation is kept
+func (m MicroPitch) Subtract(i Interval) Pitch {
+	return m - MicroPitch(i)
+}
+
+// Key returns the nearest MIDI key number. Pitches exactly between 2 keys
+// return the lower one, e.g. 69 for 69.5, except 50 cents below C-1. Pitches
+// outside of the range of MicroPitch return the key of C-1 or G9, they are
+// never rests
+func (m MicroPitch) Key() int {
+	key := int(math.Ceil(float64(m) - 0.5))
+	switch {
+	case key < int(C_1):
+		return int(C_1)
+	case key > int(G9):
+		return int(G9)
+	}
+	return key
+}
+
+// Cents returns the deviation from the nearest MIDI key, from -50 to 50 in
+// the range of MicroPitch
+func (m MicroPitch) Cents() float64 {
+	return (float64(m) - float64(m.Key())) * centsPerKey
+}
@@ -0,0 +1,64 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMicroPitch(t *testing.T) {
+	p := note.NewMicroPitch(note.A4, 14)
+	assert.Equal(t, "A4 +14c", p.String())
+	assert.Equal(t, 69, p.Key())
+	assert.InDelta(t, 14, p.Cents(), 0.0001)
+	assert.InDelta(t, 443.5726, p.Frequency(), 0.0001)
+
+	assert.Equal(t, "A#4 -14c", note.NewMicroPitch(note.B4, -114).String())
+	assert.Equal(t, "C4", note.NewMicroPitch(note.C4, 0.3).String())
+	assert.Equal(t, "C-1 +1c", note.NewMicroPitch(note.C_1, 1).String())
+
+	// Quarter tones belong to the lower key
+	q := note.NewMicroPitch(note.E4, 50)
+	assert.Equal(t, "E4 +50c", q.String())
+	assert.Equal(t, note.E4.Key(), q.Key())
+	assert.Equal(t, "F4 -49c", note.NewMicroPitch(note.E4, 51).String())
+
+	// Deviations are added to existing micro pitches
+	assert.Equal(t, "A4 +20c", note.NewMicroPitch(p, 6).String())
+
+	// Same frequency as the standard pitch without deviation
+	assert.Equal(t, note.Fsharp5.Frequency(), note.NewMicroPitch(note.Fsharp5, 0).Frequency())
+
+	// Pitches outside of the MIDI range are clamped, they are not rests
+	low := note.NewMicroPitch(note.C_1, -60)
+	assert.Equal(t, note.MicroPitch(-0.5), low)
+	assert.Equal(t, note.C_1.Key(), low.Key())
+	assert.Equal(t, "C-1 -50c", low.String())
+	assert.Equal(t, "G9 +50c", note.NewMicroPitch(note.G9, 60).String())
+	assert.Equal(t, note.C_1.Key(), note.MicroPitch(-3).Key())
+	assert.Equal(t, note.G9.Key(), note.MicroPitch(200).Key())
+	assert.Equal(t, note.MicroPitch(-0.5), note.MicroPitchFromFrequency(0))
+}
+
+func TestMicroPitchArithmetic(t *testing.T) {
+	p := note.NewMicroPitch(note.A4, 14)
+
+	up := p.Add(note.Octave)
+	assert.Equal(t, "A5 +14c", up.String())
+	assert.InDelta(t, 2*p.Frequency(), up.Frequency(), 0.0001)
+
+	down := p.Subtract(note.Tone)
+	assert.Equal(t, "G4 +14c", down.String())
+	assert.Equal(t, note.G4.Key(), down.Key())
+
+	n := note.NewNote(p, note.Quarter).Add(note.Semitone)
+	assert.Equal(t, "♩ A#4 +14c", n.String())
+}
+
+func TestMicroPitchFromFrequency(t *testing.T) {
+	assert.Equal(t, "A4", note.MicroPitchFromFrequency(440).String())
+	assert.Equal(t, "A4 +14c", note.MicroPitchFromFrequency(443.5726).String())
+	assert.Equal(t, "D4 -2c", note.MicroPitchFromFrequency(293.33).String())
+	assert.InDelta(t, 261.6256, note.MicroPitchFromFrequency(261.6256).Frequency(), 0.0001)
+}
@@ -8,6 +8,18 @@ import (
 type Note struct {
 	Pitch
 	Duration
+	// Tie is true when the note is tied to the next one, with the same pitch.
+	// Both are played as a single note with their durations combined
+	Tie bool
+	// Dynamic is the loudness from this note on. NoDynamic keeps the
+	// previous one
+	Dynamic Dynamic
+	// Hairpin starts a crescendo or diminuendo on this note, up to the next
+	// note with a Dynamic
+	Hairpin Hairpin
+	// Articulation is how the note is played, e.g. Staccato. Articulations
+	// can be combined with |
+	Articulation Articulation
 }
 
 // NewNote creates a new musical Note
@@ -26,26 +38,75 @@ func NewRest(d Duration) Note {
 	}
 }
 
-// String returns a human readable representation of this note
+// String returns a human readable representation of this note. Tied notes
+// end with "~"
 func (n Note) String() string {
 	if _, ok := n.Pitch.(restPitch); ok {
 		return n.Duration.StringRest()
 	}
+	if n.Tie {
+		return fmt.Sprintf("%v %v~", n.Duration, n.Pitch)
+	}
 	return fmt.Sprintf("%v %v", n.Duration, n.Pitch)
 }
 
 // Add returns a new Note adding an interval to this note's pitch, making it higher
 func (n Note) Add(i Interval) Note {
-	return Note{
-		Pitch:    n.Pitch.Add(i),
-		Duration: n.Duration,
-	}
+	n.Pitch = n.Pitch.Add(i)
+	return n
 }
 
 // Subtract returns a new Note subtracting an interval to this note's pitch, making it lower
 func (n Note) Subtract(i Interval) Note {
-	return Note{
-		Pitch:    n.Pitch.Subtract(i),
-		Duration: n.Duration,
+	n.Pitch = n.Pitch.Subtract(i)
+	return n
+}
+
+// Tied returns a copy of the note tied to the next one
+func (n Note) Tied() Note {
+	n.Tie = true
+	return n
+}
+
+// MergeTies returns a copy of the notes where each group of tied notes is
+// replaced by a single note with their total duration. Ties to a note with a
+// different pitch, and ties on rests, are ignored
+func MergeTies(notes []Note) []Note {
+	var merged []Note
+	for _, n := range notes {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.Tie && last.Key() >= 0 && last.Key() == n.Key() {
+				last.Duration = Sum(last.Duration, n.Duration)
+				last.Tie = n.Tie
+				continue
+			}
+			last.Tie = false
+		}
+
+		merged = append(merged, n)
+	}
+
+	// a tie on the last note has nothing to connect to
+	if len(merged) > 0 {
+		merged[len(merged)-1].Tie = false
+	}
+
+	return merged
+}
+
+// Tuning maps pitches to frequencies, e.g. equal temperament with A4 at 440
+// Hz, or just intonation
+type Tuning interface {
+	// Frequency returns the hertz value for the pitch
+	Frequency(p Pitch) float64
+}
+
+// TunedFrequency returns the hertz value for this note's pitch using the
+// tuning t. Rests return 0
+func (n Note) TunedFrequency(t Tuning) float64 {
+	if n.Key() < 0 {
+		return 0
 	}
+	return t.Frequency(n.Pitch)
 }
@@ -34,6 +34,7 @@ func TestRestString(t *testing.T) {
 	assert.Equal(t, "4 rest", n.String())
 
 	assert.Equal(t, "", n.Pitch.String())
+	assert.Equal(t, -1, n.Pitch.Key())
 }
 
 func TestNoteFrequency(t *testing.T) {
@@ -91,3 +92,22 @@ func TestRestDuration(t *testing.T) {
 	assert.Equal(t, n.Frequency(), longer.Frequency())
 	assert.Equal(t, note.Double, longer.Duration)
 }
+
+func TestPitchKey(t *testing.T) {
+	assert.Equal(t, 0, note.C_1.Key())
+	assert.Equal(t, 60, note.C4.Key())
+	assert.Equal(t, 69, note.A4.Key())
+	assert.Equal(t, 127, note.G9.Key())
+	assert.Equal(t, 72, note.C4.Add(note.Octave).Key())
+}
+
+func TestFromKey(t *testing.T) {
+	assert.Equal(t, note.C_1, note.FromKey(0))
+	assert.Equal(t, note.C4, note.FromKey(60))
+	assert.Equal(t, note.G9, note.FromKey(127))
+	assert.Equal(t, 61, note.FromKey(61).Key())
+
+	// Keys out of range are clamped
+	assert.Equal(t, note.C_1, note.FromKey(-1))
+	assert.Equal(t, note.G9, note.FromKey(128))
+}
@@ -0,0 +1,229 @@
+package note
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParsePitch reads a pitch name like "F#5", "Bb3" or "C-1". The returned
+// Pitch is a SpelledPitch, it keeps the spelling of the given name
+func ParsePitch(s string) (Pitch, error) {
+	p, err := ParseSpelledPitch(s)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseSpelledPitch reads a pitch name made of a letter, optional
+// accidentals and an octave number. Accidentals can be written as "#", "b",
+// "##", "bb", "x" (double sharp), "♯" or "♭". It returns an error for names
+// outside of the MIDI key range, C-1 to G9
+func ParseSpelledPitch(s string) (SpelledPitch, error) {
+	var p SpelledPitch
+
+	name := strings.TrimSpace(s)
+	if name == "" {
+		return p, fmt.Errorf("invalid pitch %q: empty name", s)
+	}
+
+	l := strings.IndexByte("CDEFGAB", strings.ToUpper(name[:1])[0])
+	if l < 0 {
+		return p, fmt.Errorf("invalid pitch %q: %q is not a note letter", s, name[:1])
+	}
+	p.Letter = Letter(l)
+	name = name[1:]
+
+	for name != "" {
+		r, size := utf8.DecodeRuneInString(name)
+		switch r {
+		case '#', '♯':
+			p.Accidental++
+		case 'b', '♭':
+			p.Accidental--
+		case 'x', '𝄪':
+			p.Accidental += DoubleSharp
+		case '𝄫':
+			p.Accidental += DoubleFlat
+		default:
+			size = 0
+		}
+
+		if size == 0 {
+			break
+		}
+		name = name[size:]
+	}
+
+	if name == "" {
+		return p, fmt.Errorf("invalid pitch %q: missing octave", s)
+	}
+
+	octave, err := strconv.Atoi(name)
+	if err != nil {
+		return p, fmt.Errorf("invalid pitch %q: wrong octave %q", s, name)
+	}
+	p.Octave = octave
+
+	if key := p.Key(); key < MinKey || key > MaxKey {
+		return p, fmt.Errorf("invalid pitch %q: outside of the range C-1 to G9", s)
+	}
+
+	return p, nil
+}
+
+// durationNames are the abbreviations accepted by ParseDuration
+var durationNames = map[string]Duration{
+	"w": Whole,
+	"h": Half,
+	"q": Quarter,
+	"e": Eighth,
+	"s": Sixteenth,
+	"t": Sixteenth / 2,
+}
+
+// standardDurations are the durations with their own note and rest symbols
+var standardDurations = []Duration{Double, Whole, Half, Quarter, Eighth, Sixteenth}
+
+// durationSymbols are the note symbols accepted by ParseDuration and
+// ParseNote, e.g. "♩"
+var durationSymbols = func() map[string]Duration {
+	m := map[string]Duration{}
+	for _, d := range standardDurations {
+		m[d.String()] = d
+	}
+	return m
+}()
+
+// restSymbols are the rest symbols accepted by ParseNote, e.g. "𝄽"
+var restSymbols = func() map[string]Duration {
+	m := map[string]Duration{}
+	for _, d := range standardDurations {
+		m[d.StringRest()] = d
+	}
+	return m
+}()
+
+// ParseDuration reads a note duration. It accepts the abbreviations "w"
+// (whole), "h" (half), "q" (quarter), "e" (eighth), "s" (sixteenth) and "t"
+// (thirty-second), the symbols returned by Duration.String, and fractions
+// of the whole note like "1/4" or "2". Each dot at the end makes a dotted
+// duration, e.g. "q." or "h..", and a superscript number makes a tuplet,
+// e.g. "♪³" or "e³"
+func ParseDuration(s string) (Duration, error) {
+	base, dots, tuplet := splitDuration(strings.TrimSpace(s))
+
+	d, ok := durationNames[base]
+	if !ok {
+		d, ok = durationSymbols[base]
+	}
+	if !ok {
+		f, err := parseFraction(base)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d = f
+	}
+
+	return applyDuration(d, dots, tuplet), nil
+}
+
+// splitDuration returns the duration name without the trailing dots and
+// superscript tuplet number
+func splitDuration(name string) (string, int, int) {
+	runes := []rune(name)
+	end := len(runes)
+	for end > 0 && superscriptDigit(runes[end-1]) >= 0 {
+		end--
+	}
+
+	tuplet := 0
+	for _, r := range runes[end:] {
+		tuplet = tuplet*10 + superscriptDigit(r)
+	}
+
+	name = string(runes[:end])
+	base := strings.TrimRight(name, ".")
+	return base, len(name) - len(base), tuplet
+}
+
+// superscriptDigit returns the value of a superscript digit, or -1 for other
+// characters
+func superscriptDigit(r rune) int {
+	for i, s := range superscripts {
+		if s == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyDuration returns the duration with the dots and tuplet number
+func applyDuration(d Duration, dots int, tuplet int) Duration {
+	d = d.Dots(dots)
+	if tuplet < 2 {
+		return d
+	}
+	return d.Tuplet(tuplet, tupletSpan(tuplet))
+}
+
+// parseFraction reads a positive number like "2" or a fraction like "1/8"
+func parseFraction(s string) (Duration, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) > 2 {
+		return 0, fmt.Errorf("invalid fraction %q", s)
+	}
+
+	num, err := strconv.Atoi(parts[0])
+	if err != nil || num <= 0 {
+		return 0, fmt.Errorf("invalid fraction %q", s)
+	}
+
+	den := 1
+	if len(parts) == 2 {
+		den, err = strconv.Atoi(parts[1])
+		if err != nil || den <= 0 {
+			return 0, fmt.Errorf("invalid fraction %q", s)
+		}
+	}
+
+	return Duration(num) / Duration(den), nil
+}
+
+// ParseNote reads a duration followed by a pitch, separated by spaces, e.g.
+// "q. F#5" or "♩ Bb3". Rests are written with "r" or "rest" instead of a
+// pitch, e.g. "e r", or with a rest symbol alone like "𝄽". See ParseDuration
+// and ParsePitch for the accepted formats
+func ParseNote(s string) (Note, error) {
+	fields := strings.Fields(s)
+
+	if len(fields) == 1 {
+		base, dots, tuplet := splitDuration(fields[0])
+		if d, ok := restSymbols[base]; ok {
+			return NewRest(applyDuration(d, dots, tuplet)), nil
+		}
+	}
+
+	if len(fields) != 2 {
+		return Note{}, fmt.Errorf("invalid note %q: it must be a duration and a pitch", s)
+	}
+
+	d, err := ParseDuration(fields[0])
+	if err != nil {
+		return Note{}, fmt.Errorf("invalid note %q: %v", s, err)
+	}
+
+	switch strings.ToLower(fields[1]) {
+	case "r", "rest":
+		return NewRest(d), nil
+	}
+
+	p, err := ParsePitch(fields[1])
+	if err != nil {
+		return Note{}, fmt.Errorf("invalid note %q: %v", s, err)
+	}
+
+	return NewNote(p, d), nil
+}
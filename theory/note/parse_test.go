@@ -0,0 +1,139 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePitch(t *testing.T) {
+	for s, expected := range map[string]note.Pitch{
+		"F#5":  note.Fsharp5,
+		"Bb3":  note.Asharp3,
+		"C-1":  note.C_1,
+		"G9":   note.G9,
+		"A4":   note.A4,
+		"Cb4":  note.B3,
+		"B#3":  note.C4,
+		"Fx4":  note.G4,
+		"F##4": note.G4,
+		"Ebb4": note.D4,
+		"E♭4":  note.Dsharp4,
+		"C♯4":  note.Csharp4,
+		"bb3":  note.Asharp3,
+		" D2 ": note.D2,
+	} {
+		p, err := note.ParsePitch(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected.Key(), p.Key(), s)
+			assert.Equal(t, expected.Frequency(), p.Frequency(), s)
+		}
+	}
+}
+
+func TestParsePitchSpelling(t *testing.T) {
+	p, err := note.ParsePitch("Bb3")
+	require.NoError(t, err)
+	assert.Equal(t, "Bb3", p.String())
+
+	s, err := note.ParseSpelledPitch("F##-1")
+	require.NoError(t, err)
+	assert.Equal(t, note.NewSpelledPitch(note.F, note.DoubleSharp, -1), s)
+}
+
+func TestParsePitchErrors(t *testing.T) {
+	for _, s := range []string{
+		"", "H4", "C", "C#", "C#x", "4", "Cb-1", "G#9", "C10", "C 4", "C4.",
+	} {
+		_, err := note.ParsePitch(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for s, expected := range map[string]note.Duration{
+		"w":    note.Whole,
+		"h":    note.Half,
+		"q":    note.Quarter,
+		"e":    note.Eighth,
+		"s":    note.Sixteenth,
+		"t":    note.Sixteenth / 2,
+		"q.":   note.Quarter + note.Eighth,
+		"h..":  note.Half + note.Quarter + note.Eighth,
+		"♩":    note.Quarter,
+		"𝅜":    note.Double,
+		"1/8":  note.Eighth,
+		"2":    note.Double,
+		"3/8":  3 * note.Eighth,
+		"1/4.": note.Quarter + note.Eighth,
+	} {
+		d, err := note.ParseDuration(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, d, s)
+		}
+	}
+
+	for _, s := range []string{"", "x", "0", "1/0", "-1", "1/2/3", "q q"} {
+		_, err := note.ParseDuration(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseNote(t *testing.T) {
+	n, err := note.ParseNote("q. F#5")
+	require.NoError(t, err)
+	assert.Equal(t, note.Quarter+note.Eighth, n.Duration)
+	assert.Equal(t, note.Fsharp5.Key(), n.Key())
+
+	n, err = note.ParseNote("e r")
+	require.NoError(t, err)
+	assert.Equal(t, note.NewRest(note.Eighth), n)
+
+	n, err = note.ParseNote("h REST")
+	require.NoError(t, err)
+	assert.Equal(t, note.NewRest(note.Half), n)
+
+	// Round trip of the String output
+	for _, n := range []note.Note{
+		note.NewNote(note.Fsharp5, note.Quarter),
+		note.NewNote(note.NewSpelledPitch(note.B, note.Flat, 3), note.Sixteenth),
+		note.NewNote(note.C_1, note.Double),
+		note.NewRest(note.Half),
+		note.NewRest(note.Sixteenth),
+	} {
+		parsed, err := note.ParseNote(n.String())
+		if assert.NoError(t, err, n.String()) {
+			assert.Equal(t, n.String(), parsed.String())
+		}
+	}
+
+	for _, s := range []string{"", "q", "F#5", "q F#5 G5", "x F#5", "q H5", "𝄽 r"} {
+		_, err := note.ParseNote(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseTuplets(t *testing.T) {
+	for s, expected := range map[string]note.Duration{
+		"e³":                           note.Eighth.Triplet(),
+		"q.³":                          note.Quarter.Dotted().Triplet(),
+		"s⁵":                           note.Sixteenth.Tuplet(5, 4),
+		"1/4¹¹":                        note.Quarter.Tuplet(11, 8),
+		note.Eighth.Triplet().String(): note.Eighth.Triplet(),
+	} {
+		d, err := note.ParseDuration(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, d, s)
+		}
+	}
+
+	n, err := note.ParseNote(note.Quarter.Dotted().StringRest())
+	require.NoError(t, err)
+	assert.Equal(t, note.NewRest(note.Quarter.Dotted()), n)
+
+	n, err = note.ParseNote(note.Eighth.Triplet().StringRest())
+	require.NoError(t, err)
+	assert.Equal(t, note.NewRest(note.Eighth.Triplet()), n)
+}
@@ -24,6 +24,8 @@ type Pitch interface {
 	Add(i Interval) Pitch
 	// Subtract returns a new Pitch subtracting an interval to this pitch, making it lower
 	Subtract(i Interval) Pitch
+	// Key returns the MIDI key number for this pitch, e.g. 60 for C4
+	Key() int
 }
 
 // restPitch represents the (absence of) musical frequency for a rest note
@@ -49,6 +51,11 @@ func (r restPitch) String() string {
 	return ""
 }
 
+// Key returns -1, a rest does not have a key number
+func (r restPitch) Key() int {
+	return -1
+}
+
 // pitchValue represents a musical frequency
 type pitchValue uint8
 
@@ -62,12 +69,31 @@ func (p pitchValue) Frequency() float64 {
 	return pitchValues[p].frequency
 }
 
-// Add returns a new Pitch adding an interval to this pitch, making it higher
+// Add returns a new Pitch adding an interval to this pitch, making it higher.
+// Results above G9 are not valid, see CheckedAdd and ClampedAdd
 func (p pitchValue) Add(i Interval) Pitch {
 	return pitchValue(uint8(p) + uint8(i))
 }
 
-// Subtract returns a new Pitch subtracting an interval to this pitch, making it lower
+// Subtract returns a new Pitch subtracting an interval to this pitch, making it lower.
+// Results below C-1 are not valid, see CheckedSubtract and ClampedSubtract
 func (p pitchValue) Subtract(i Interval) Pitch {
 	return pitchValue(uint8(p) - uint8(i))
 }
+
+// Key returns the MIDI key number for this pitch, e.g. 60 for C4
+func (p pitchValue) Key() int {
+	return int(p)
+}
+
+// FromKey returns the pitch for a MIDI key number, e.g. C4 for 60. Keys
+// outside of MinKey to MaxKey are clamped to the range
+func FromKey(key int) Pitch {
+	switch {
+	case key < MinKey:
+		key = MinKey
+	case key > MaxKey:
+		key = MaxKey
+	}
+	return pitchValue(key)
+}
@@ -0,0 +1,164 @@
+package note
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MinKey is the lowest MIDI key number, C-1
+	MinKey = 0
+	// MaxKey is the highest MIDI key number, G9
+	MaxKey = 127
+)
+
+// RangeError is returned when a pitch is moved outside of the MIDI key range
+type RangeError struct {
+	// Pitch is the original pitch
+	Pitch Pitch
+	// Key is the resulting key number, outside of the range
+	Key int
+}
+
+// Error returns a description of the error
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("%v moved to key %d, outside of the range C-1 to G9", e.Pitch, e.Key)
+}
+
+// Transpose returns a new Pitch moved the given number of semitones, up for
+// positive values and down for negative ones. Unlike Pitch.Add and
+// Pitch.Subtract, it returns a RangeError when the result is outside of the
+// MIDI key range. Rests are not modified
+func Transpose(p Pitch, semitones int) (Pitch, error) {
+	if p.Key() < 0 {
+		return p, nil
+	}
+
+	key := p.Key() + semitones
+	if key < MinKey || key > MaxKey {
+		return nil, &RangeError{Pitch: p, Key: key}
+	}
+
+	if semitones < 0 {
+		return p.Subtract(Interval(-semitones)), nil
+	}
+	return p.Add(Interval(semitones)), nil
+}
+
+// CheckedAdd returns a new Pitch adding an interval, or a RangeError if the
+// result is higher than G9
+func CheckedAdd(p Pitch, i Interval) (Pitch, error) {
+	return Transpose(p, int(i))
+}
+
+// CheckedSubtract returns a new Pitch subtracting an interval, or a
+// RangeError if the result is lower than C-1
+func CheckedSubtract(p Pitch, i Interval) (Pitch, error) {
+	return Transpose(p, -int(i))
+}
+
+// ClampedAdd returns a new Pitch adding an interval. Results higher than G9
+// return G9
+func ClampedAdd(p Pitch, i Interval) Pitch {
+	return clamped(p, int(i))
+}
+
+// ClampedSubtract returns a new Pitch subtracting an interval. Results lower
+// than C-1 return C-1
+func ClampedSubtract(p Pitch, i Interval) Pitch {
+	return clamped(p, -int(i))
+}
+
+// clamped returns the pitch moved the given semitones, limited to the MIDI
+// key range
+func clamped(p Pitch, semitones int) Pitch {
+	t, err := Transpose(p, semitones)
+	if err == nil {
+		return t
+	}
+
+	if semitones < 0 {
+		return pitchValue(MinKey)
+	}
+	return pitchValue(MaxKey)
+}
+
+// CheckedAdd returns a new Note adding an interval to this note's pitch, or
+// a RangeError if the result is higher than G9
+func (n Note) CheckedAdd(i Interval) (Note, error) {
+	p, err := CheckedAdd(n.Pitch, i)
+	if err != nil {
+		return Note{}, err
+	}
+	n.Pitch = p
+	return n, nil
+}
+
+// CheckedSubtract returns a new Note subtracting an interval to this note's
+// pitch, or a RangeError if the result is lower than C-1
+func (n Note) CheckedSubtract(i Interval) (Note, error) {
+	p, err := CheckedSubtract(n.Pitch, i)
+	if err != nil {
+		return Note{}, err
+	}
+	n.Pitch = p
+	return n, nil
+}
+
+// OutOfRange is the position of a note in a staff that can't be transposed
+type OutOfRange struct {
+	// Group is the index of the group of simultaneous notes in the staff
+	Group int
+	// Index is the index of the note in its group
+	Index int
+	// Note is the original note
+	Note Note
+	// Key is the key number the note would be moved to
+	Key int
+}
+
+// StaffRangeError is returned when some notes of a staff are moved outside of
+// the MIDI key range
+type StaffRangeError []OutOfRange
+
+// Error returns a description of the error, listing the notes out of range
+func (e StaffRangeError) Error() string {
+	notes := make([]string, len(e))
+	for i, o := range e {
+		notes[i] = fmt.Sprintf("group %d note %d (%v to key %d)", o.Group, o.Index, o.Note.Pitch, o.Key)
+	}
+	return fmt.Sprintf("%d notes outside of the range C-1 to G9: %s",
+		len(e), strings.Join(notes, ", "))
+}
+
+// TransposeStaff returns a copy of a staff made of groups of simultaneous
+// notes, moved the given number of semitones. If any note falls outside of
+// the MIDI key range it returns nil and a StaffRangeError with all of them
+func TransposeStaff(staff [][]Note, semitones int) ([][]Note, error) {
+	var outOfRange StaffRangeError
+
+	transposed := make([][]Note, len(staff))
+	for i, group := range staff {
+		transposed[i] = make([]Note, len(group))
+		for j, n := range group {
+			p, err := Transpose(n.Pitch, semitones)
+			if err != nil {
+				outOfRange = append(outOfRange, OutOfRange{
+					Group: i,
+					Index: j,
+					Note:  n,
+					Key:   n.Key() + semitones,
+				})
+				continue
+			}
+			transposed[i][j] = n
+			transposed[i][j].Pitch = p
+		}
+	}
+
+	if len(outOfRange) > 0 {
+		return nil, outOfRange
+	}
+
+	return transposed, nil
+}
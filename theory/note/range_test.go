@@ -0,0 +1,96 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckedArithmetic(t *testing.T) {
+	p, err := note.CheckedAdd(note.C4, note.Octave)
+	require.NoError(t, err)
+	assert.Equal(t, note.C5, p)
+
+	p, err = note.CheckedSubtract(note.C4, note.Tone)
+	require.NoError(t, err)
+	assert.Equal(t, note.Asharp3, p)
+
+	_, err = note.CheckedSubtract(note.C_1, note.Semitone)
+	require.Error(t, err)
+	rangeErr, ok := err.(*note.RangeError)
+	require.True(t, ok)
+	assert.Equal(t, -1, rangeErr.Key)
+	assert.Equal(t, note.C_1, rangeErr.Pitch)
+
+	_, err = note.CheckedAdd(note.G9, note.Semitone)
+	assert.EqualError(t, err, "G9 moved to key 128, outside of the range C-1 to G9")
+
+	// Spelled and micro pitches keep their type
+	bb := note.NewSpelledPitch(note.B, note.Flat, 4)
+	p, err = note.CheckedAdd(bb, note.Tone)
+	require.NoError(t, err)
+	assert.Equal(t, "C5", p.String())
+
+	p, err = note.CheckedSubtract(note.NewMicroPitch(note.A4, 14), note.Octave)
+	require.NoError(t, err)
+	assert.Equal(t, "A3 +14c", p.String())
+
+	// Rests are not modified
+	n, err := note.NewRest(note.Quarter).CheckedAdd(note.Octave)
+	require.NoError(t, err)
+	assert.Equal(t, -1, n.Key())
+
+	n, err = note.NewNote(note.B8, note.Half).CheckedAdd(note.Octave)
+	assert.Error(t, err)
+	assert.Equal(t, note.Note{}, n)
+
+	n, err = note.NewNote(note.B8, note.Half).CheckedSubtract(note.Octave)
+	require.NoError(t, err)
+	assert.Equal(t, note.NewNote(note.B7, note.Half), n)
+}
+
+func TestClampedArithmetic(t *testing.T) {
+	assert.Equal(t, note.C5, note.ClampedAdd(note.C4, note.Octave))
+	assert.Equal(t, note.G9, note.ClampedAdd(note.C9, note.Octave))
+	assert.Equal(t, note.C_1, note.ClampedSubtract(note.D_1, note.Octave))
+	assert.Equal(t, note.D_1, note.ClampedSubtract(note.D0, note.Octave))
+}
+
+func TestTransposeStaff(t *testing.T) {
+	staff := [][]note.Note{
+		{note.NewNote(note.E4, note.Eighth), note.NewNote(note.A8, note.Eighth)},
+		{note.NewRest(note.Quarter)},
+		{note.NewNote(note.C9, note.Half)},
+	}
+
+	transposed, err := note.TransposeStaff(staff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, [][]note.Note{
+		{note.NewNote(note.Fsharp4, note.Eighth), note.NewNote(note.B8, note.Eighth)},
+		{note.NewRest(note.Quarter)},
+		{note.NewNote(note.D9, note.Half)},
+	}, transposed)
+
+	transposed, err = note.TransposeStaff(staff, -64)
+	require.NoError(t, err)
+	assert.Equal(t, note.C_1, transposed[0][0].Pitch)
+
+	transposed, err = note.TransposeStaff(staff, 12)
+	assert.Nil(t, transposed)
+	require.Error(t, err)
+
+	rangeErr, ok := err.(note.StaffRangeError)
+	require.True(t, ok)
+	require.Len(t, rangeErr, 2)
+	assert.Equal(t, 0, rangeErr[0].Group)
+	assert.Equal(t, 1, rangeErr[0].Index)
+	assert.Equal(t, 129, rangeErr[0].Key)
+	assert.Equal(t, 2, rangeErr[1].Group)
+	assert.Equal(t, 0, rangeErr[1].Index)
+	assert.Equal(t, "2 notes outside of the range C-1 to G9: group 0 note 1 (A8 to key 129), group 2 note 0 (C9 to key 132)", err.Error())
+
+	// The original is not modified
+	assert.Equal(t, note.E4, staff[0][0].Pitch)
+}
@@ -0,0 +1,202 @@
+package note
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// maxDenominator is the largest denominator used to convert a Duration to a
+// Rational. It is big enough for any tuplet of short notes
+const maxDenominator = 1 << 20
+
+// Rational is an exact fraction of the whole note. Unlike Duration, adding
+// triplets or other tuplets does not accumulate rounding errors
+type Rational struct {
+	num, den int64
+}
+
+// NewRational returns the fraction num/den in its lowest terms. It panics if
+// den is 0
+func NewRational(num, den int64) Rational {
+	if den == 0 {
+		panic("rational with a 0 denominator")
+	}
+	if den < 0 {
+		num, den = -num, -den
+	}
+
+	// the zero value is the only representation of 0
+	if num == 0 {
+		return Rational{}
+	}
+
+	g := gcd(num, den)
+	return Rational{num / g, den / g}
+}
+
+// Rational returns the closest fraction to the duration, with a denominator
+// up to 2^20. Durations made of powers of two, dots and tuplets, like a
+// triplet eighth, return their exact value
+func (d Duration) Rational() Rational {
+	f := float64(d)
+	sign := int64(1)
+	if f < 0 {
+		sign, f = -1, -f
+	}
+
+	// continued fraction expansion, h/k are the convergents
+	h0, h1 := int64(0), int64(1)
+	k0, k1 := int64(1), int64(0)
+	x := f
+	for {
+		a := math.Floor(x)
+		h2 := int64(a)*h1 + h0
+		k2 := int64(a)*k1 + k0
+		if k2 > maxDenominator {
+			break
+		}
+		h0, h1, k0, k1 = h1, h2, k1, k2
+
+		frac := x - a
+		if frac < 1e-12 || math.Abs(float64(h1)/float64(k1)-f) < 1e-12 {
+			break
+		}
+		x = 1 / frac
+	}
+
+	return NewRational(sign*h1, k1)
+}
+
+// Num returns the numerator, in lowest terms
+func (r Rational) Num() int64 {
+	return r.num
+}
+
+// Den returns the denominator, in lowest terms. It is always positive
+func (r Rational) Den() int64 {
+	if r.den == 0 {
+		// zero value
+		return 1
+	}
+	return r.den
+}
+
+// Add returns r + o
+func (r Rational) Add(o Rational) Rational {
+	return NewRational(r.num*o.Den()+o.num*r.Den(), r.Den()*o.Den())
+}
+
+// Sub returns r - o
+func (r Rational) Sub(o Rational) Rational {
+	return NewRational(r.num*o.Den()-o.num*r.Den(), r.Den()*o.Den())
+}
+
+// Mul returns r * o
+func (r Rational) Mul(o Rational) Rational {
+	return NewRational(r.num*o.num, r.Den()*o.Den())
+}
+
+// Cmp returns -1, 0 or 1 if r is less than, equal to, or greater than o
+func (r Rational) Cmp(o Rational) int {
+	a, b := r.num*o.Den(), o.num*r.Den()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Duration returns the Duration closest to the fraction
+func (r Rational) Duration() Duration {
+	return Duration(r.num) / Duration(r.Den())
+}
+
+// String returns the fraction, e.g. "3/8"
+func (r Rational) String() string {
+	return fmt.Sprintf("%d/%d", r.num, r.Den())
+}
+
+// ToTime returns the fraction as time.Duration, rounded to the nearest
+// nanosecond. tempoNote is the note in the tempo marking, or the _beat_ in
+// _beats per minute_. A tempoNote or bpm of 0 returns 0. See
+// Duration.ToSeconds
+func (r Rational) ToTime(tempoNote Duration, bpm int) time.Duration {
+	return time.Duration(r.scale(tempoNote, bpm, int64(time.Minute)))
+}
+
+// ToSamples returns the number of samples at the given sample rate for the
+// fraction, rounded down. tempoNote is the note in the tempo marking, or the
+// _beat_ in _beats per minute_. A tempoNote or bpm of 0 returns 0
+func (r Rational) ToSamples(tempoNote Duration, bpm int, sampleRate int) int64 {
+	return r.floor(tempoNote, bpm, int64(sampleRate)*60)
+}
+
+// scale returns r / tempoNote * perMinute / bpm, rounded to the nearest
+// integer. Big integers avoid overflows for long pieces
+func (r Rational) scale(tempoNote Duration, bpm int, perMinute int64) int64 {
+	num, den := r.ratio(tempoNote, bpm, perMinute)
+
+	// round half up: (2*num + den) / (2*den)
+	num.Mul(num, big.NewInt(2)).Add(num, den)
+	den.Mul(den, big.NewInt(2))
+	return new(big.Int).Div(num, den).Int64()
+}
+
+// floor returns r / tempoNote * perMinute / bpm, rounded down
+func (r Rational) floor(tempoNote Duration, bpm int, perMinute int64) int64 {
+	num, den := r.ratio(tempoNote, bpm, perMinute)
+	return new(big.Int).Div(num, den).Int64()
+}
+
+// ratio returns the numerator and denominator of r / tempoNote * perMinute /
+// bpm, with a positive denominator. It is 0/1 if tempoNote or bpm are 0
+func (r Rational) ratio(tempoNote Duration, bpm int, perMinute int64) (*big.Int, *big.Int) {
+	t := tempoNote.Rational()
+
+	num := big.NewInt(r.num)
+	num.Mul(num, big.NewInt(t.Den()))
+	num.Mul(num, big.NewInt(perMinute))
+
+	den := big.NewInt(r.Den())
+	den.Mul(den, big.NewInt(t.num))
+	den.Mul(den, big.NewInt(int64(bpm)))
+
+	switch den.Sign() {
+	case 0:
+		return big.NewInt(0), big.NewInt(1)
+	case -1:
+		num.Neg(num)
+		den.Neg(den)
+	}
+	return num, den
+}
+
+// Positions returns the start of each duration when they are played one
+// after another, and the end of the last one, as exact fractions of the
+// whole note. Scheduling from these positions does not accumulate the
+// rounding errors of adding floats
+func Positions(durations ...Duration) []Rational {
+	positions := make([]Rational, len(durations)+1)
+	for i, d := range durations {
+		positions[i+1] = positions[i].Add(d.Rational())
+	}
+	return positions
+}
+
+// gcd returns the greatest common divisor of a and b, always positive
+func gcd(a, b int64) int64 {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
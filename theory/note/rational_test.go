@@ -0,0 +1,84 @@
+package note_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRational(t *testing.T) {
+	r := note.NewRational(6, -16)
+	assert.Equal(t, int64(-3), r.Num())
+	assert.Equal(t, int64(8), r.Den())
+	assert.Equal(t, "-3/8", r.String())
+
+	assert.Equal(t, note.NewRational(1, 12), note.Eighth.Triplet().Rational())
+	assert.Equal(t, note.NewRational(3, 8), note.Quarter.Dotted().Rational())
+	assert.Equal(t, note.NewRational(1, 20), note.Sixteenth.Tuplet(5, 4).Rational())
+	assert.Equal(t, note.Rational{}, note.Duration(0).Rational())
+	assert.Equal(t, note.Rational{}, note.NewRational(0, 5))
+	assert.Equal(t, "0/1", note.Rational{}.String())
+
+	third := note.NewRational(1, 3)
+	assert.Equal(t, note.NewRational(1, 2), third.Add(note.NewRational(1, 6)))
+	assert.Equal(t, note.NewRational(1, 6), third.Sub(note.NewRational(1, 6)))
+	assert.Equal(t, note.NewRational(1, 9), third.Mul(third))
+	assert.Equal(t, note.Rational{}, third.Sub(third))
+
+	assert.Equal(t, -1, third.Cmp(note.NewRational(1, 2)))
+	assert.Equal(t, 0, third.Cmp(note.NewRational(2, 6)))
+	assert.Equal(t, 1, third.Cmp(note.Rational{}))
+
+	assert.Equal(t, note.Quarter, note.NewRational(1, 4).Duration())
+	assert.Panics(t, func() { note.NewRational(1, 0) })
+}
+
+func TestRationalToTime(t *testing.T) {
+	assert.Equal(t, 500*time.Millisecond, note.NewRational(1, 4).ToTime(note.Quarter, 120))
+	assert.Equal(t, 1500*time.Millisecond, note.Half.Dotted().Rational().ToTime(note.Quarter, 120))
+
+	// A triplet eighth at 180 bpm lasts 1/9 seconds
+	assert.Equal(t, 111111111*time.Nanosecond, note.Eighth.Triplet().Rational().ToTime(note.Quarter, 180))
+	assert.Equal(t, 111111111*time.Nanosecond, note.Eighth.Triplet().ToSeconds(note.Quarter, 180))
+
+	// No tempo takes no time
+	assert.Equal(t, time.Duration(0), note.NewRational(1, 4).ToTime(note.Quarter, 0))
+	assert.Equal(t, time.Duration(0), note.NewRational(1, 4).ToTime(0, 120))
+	assert.Equal(t, time.Duration(0), note.Quarter.ToSeconds(note.Quarter, 0))
+}
+
+func TestRationalToSamples(t *testing.T) {
+	assert.Equal(t, int64(22050), note.NewRational(1, 4).ToSamples(note.Quarter, 120, 44100))
+	assert.Equal(t, int64(4900), note.Eighth.Triplet().Rational().ToSamples(note.Quarter, 180, 44100))
+	assert.Equal(t, int64(14700), note.Quarter.Rational().ToSamples(note.Quarter, 180, 44100))
+
+	// 1/9 seconds of a 1000 Hz sample rate is 111.1 samples, rounded down
+	assert.Equal(t, int64(111), note.Eighth.Triplet().Rational().ToSamples(note.Quarter, 180, 1000))
+
+	assert.Equal(t, int64(0), note.NewRational(1, 4).ToSamples(note.Quarter, 0, 44100))
+	assert.Equal(t, int64(0), note.NewRational(1, 4).ToSamples(0, 120, 44100))
+}
+
+func TestPositions(t *testing.T) {
+	var durations []note.Duration
+	for i := 0; i < 3000; i++ {
+		durations = append(durations, note.Quarter.Triplet())
+	}
+
+	positions := note.Positions(durations...)
+	assert.Len(t, positions, 3001)
+	assert.Equal(t, note.Rational{}, positions[0])
+	assert.Equal(t, note.NewRational(1, 6), positions[1])
+	assert.Equal(t, note.NewRational(500, 1), positions[3000])
+
+	// After 500 bars at 180 bpm the position is still exactly on a sample
+	assert.Equal(t, int64(500*4*14700), positions[3000].ToSamples(note.Quarter, 180, 44100))
+
+	var float note.Duration
+	for _, d := range durations {
+		float += d
+	}
+	assert.NotEqual(t, note.Duration(500), float)
+}
@@ -0,0 +1,149 @@
+package note
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Letter is the name of a natural note, without accidentals
+type Letter uint8
+
+// Natural note names, in ascending order inside an octave
+const (
+	C Letter = iota
+	D
+	E
+	F
+	G
+	A
+	B
+)
+
+// letterKeys are the distances in semitones from C to each natural note
+var letterKeys = [...]int{0, 2, 4, 5, 7, 9, 11}
+
+// String returns the letter name
+func (l Letter) String() string {
+	return "CDEFGAB"[l : l+1]
+}
+
+// Accidental is the number of semitones a natural note is raised (sharps,
+// positive values) or lowered (flats, negative values)
+type Accidental int8
+
+// Common accidentals
+const (
+	DoubleFlat  Accidental = -2
+	Flat        Accidental = -1
+	Natural     Accidental = 0
+	Sharp       Accidental = 1
+	DoubleSharp Accidental = 2
+)
+
+// SpelledPitch is a pitch written with a letter, an accidental and an
+// octave. Enharmonic pitches like A#4 and Bb4 share the same key number, but
+// are spelled differently
+type SpelledPitch struct {
+	Letter     Letter
+	Accidental Accidental
+	Octave     int
+}
+
+// NewSpelledPitch returns a SpelledPitch, e.g. NewSpelledPitch(B, Flat, 4)
+// for Bb4
+func NewSpelledPitch(l Letter, a Accidental, octave int) SpelledPitch {
+	return SpelledPitch{
+		Letter:     l,
+		Accidental: a,
+		Octave:     octave,
+	}
+}
+
+// Key returns the MIDI key number for this pitch, e.g. 60 for C4. Pitches
+// spelled outside of the MIDI range return keys below 0 or above 127
+func (s SpelledPitch) Key() int {
+	return (s.Octave+1)*int(Octave) + letterKeys[s.Letter] + int(s.Accidental)
+}
+
+// String returns a human readable name for this pitch, e.g. "Bb4" or "F##3"
+func (s SpelledPitch) String() string {
+	return s.Name() + strconv.Itoa(s.Octave)
+}
+
+// Name returns the pitch name without the octave, e.g. "Bb"
+func (s SpelledPitch) Name() string {
+	return s.Letter.String() + s.Accidental.String()
+}
+
+// Frequency returns the hertz value for this pitch
+func (s SpelledPitch) Frequency() float64 {
+	key := s.Key()
+	if key >= MinKey && key <= MaxKey {
+		return pitchValue(key).Frequency()
+	}
+
+	// Same formula used to generate frequencies.go
+	return 440 * math.Pow(2, float64(key-69)/12)
+}
+
+// Add returns a new Pitch adding an interval to this pitch, making it higher.
+// The result is a SpelledPitch, spelled as it would be in the major key of
+// this pitch. Use Transpose to add a DiatonicInterval with exact spelling
+func (s SpelledPitch) Add(i Interval) Pitch {
+	return KeySignature(s.Fifths()).spellKey(s.Key() + int(i))
+}
+
+// Subtract returns a new Pitch subtracting an interval to this pitch, making
+// it lower. The result is a SpelledPitch, spelled as it would be in the
+// major key of this pitch. Use TransposeDown to subtract a DiatonicInterval
+// with exact spelling
+func (s SpelledPitch) Subtract(i Interval) Pitch {
+	return KeySignature(s.Fifths()).spellKey(s.Key() - int(i))
+}
+
+// fifthsOrder are the natural letters in the circle of fifths, starting
+// from F. It is also the order in which sharps are added to a key signature
+var fifthsOrder = [...]Letter{F, C, G, D, A, E, B}
+
+// Fifths returns the position of this pitch in the circle of fifths,
+// relative to C: 1 for G, 2 for D, -1 for F, -2 for Bb... It is also the
+// number of sharps (positive) or flats (negative) in its major key signature
+func (s SpelledPitch) Fifths() int {
+	var pos int
+	for i, l := range fifthsOrder {
+		if l == s.Letter {
+			pos = i - 1
+		}
+	}
+
+	return pos + 7*int(s.Accidental)
+}
+
+// String returns the accidental symbols, e.g. "#", "bb", or an empty string
+// for naturals
+func (a Accidental) String() string {
+	if a > 0 {
+		return strings.Repeat("#", int(a))
+	}
+	return strings.Repeat("b", int(-a))
+}
+
+// step returns the number of natural notes from C-1 to this pitch letter,
+// ignoring the accidental
+func (s SpelledPitch) step() int {
+	return (s.Octave+1)*7 + int(s.Letter)
+}
+
+// fromStep returns the natural pitch for a number of natural notes from C-1
+func fromStep(step int) SpelledPitch {
+	octave := step / 7
+	if step < 0 && step%7 != 0 {
+		octave--
+	}
+
+	return SpelledPitch{
+		Letter: Letter(step - octave*7),
+		Octave: octave - 1,
+	}
+}
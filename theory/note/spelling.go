@@ -0,0 +1,169 @@
+package note
+
+// Speller chooses the enharmonic spelling of a pitch, e.g. A#4 or Bb4
+type Speller interface {
+	// Spell returns the pitch with the same key number, spelled with a
+	// letter and accidental. ok is false for rests, that can't be spelled
+	Spell(p Pitch) (s SpelledPitch, ok bool)
+}
+
+// accidentalSpeller spells the natural notes without accidentals, and the
+// rest of pitches with a sharp or a flat
+type accidentalSpeller Accidental
+
+var (
+	// Sharps spells pitches without accidentals or with a sharp, the same
+	// names used by the generated pitch constants, e.g. A#4
+	Sharps Speller = accidentalSpeller(Sharp)
+	// Flats spells pitches without accidentals or with a flat, e.g. Bb4
+	Flats Speller = accidentalSpeller(Flat)
+)
+
+// Spell returns the pitch spelled as a natural note, or with the preferred
+// accidental
+func (a accidentalSpeller) Spell(p Pitch) (SpelledPitch, bool) {
+	key := p.Key()
+	if key < 0 {
+		return SpelledPitch{}, false
+	}
+
+	for l := C; l <= B; l++ {
+		if PitchClass(key-letterKeys[l]) == 0 {
+			return spelledKey(key, l, Natural), true
+		}
+	}
+
+	l := C
+	for PitchClass(key-letterKeys[l]-int(a)) != 0 {
+		l++
+	}
+	return spelledKey(key, l, Accidental(a)), true
+}
+
+// KeySignature is the number of sharps (positive values) or flats (negative
+// values) in a key, e.g. 2 for D major or -3 for C minor. Signatures beyond
+// 7 use double sharps or double flats
+type KeySignature int
+
+// Accidental returns the accidental the key signature applies to a letter,
+// e.g. Sharp for F in G major
+func (k KeySignature) Accidental(l Letter) Accidental {
+	var pos int
+	for i, o := range fifthsOrder {
+		if o == l {
+			pos = i
+		}
+	}
+
+	if k >= 0 {
+		// sharps are added in the order F, C, G, D, A, E, B
+		return Accidental((int(k) - pos + 6) / 7)
+	}
+
+	// flats are added in the reverse order B, E, A, D, G, C, F
+	return -Accidental((-int(k) - (6 - pos) + 6) / 7)
+}
+
+// cChromatic is the preferred spelling for the notes outside of C major,
+// indexed by pitch class
+var cChromatic = map[int]Accidental{
+	1:  Sharp, // C#
+	3:  Flat,  // Eb
+	6:  Sharp, // F#
+	8:  Flat,  // Ab
+	10: Flat,  // Bb
+}
+
+// Spell returns the pitch spelled as it belongs to the key. Pitches outside
+// of the key get the spelling with the fewest accidentals. When a sharp and
+// a flat are equally good, sharp keys choose the sharp and flat keys the flat
+func (k KeySignature) Spell(p Pitch) (SpelledPitch, bool) {
+	if p.Key() < 0 {
+		return SpelledPitch{}, false
+	}
+	return k.spellKey(p.Key()), true
+}
+
+// spellKey returns the key number spelled as it belongs to the key
+func (k KeySignature) spellKey(key int) SpelledPitch {
+	preferred := Sharp
+	switch {
+	case k < 0:
+		preferred = Flat
+	case k == 0:
+		if a, ok := cChromatic[PitchClass(key)]; ok {
+			preferred = a
+		}
+	}
+
+	var best SpelledPitch
+	bestScore := -1
+	for l := C; l <= B; l++ {
+		a := Accidental(PitchClass(key - letterKeys[l]))
+		if a > 6 {
+			a -= 12
+		}
+		if a < DoubleFlat || a > DoubleSharp {
+			continue
+		}
+
+		var score int
+		switch {
+		case a == k.Accidental(l):
+			score = 4
+		case a == Natural:
+			score = 3
+		case a == preferred:
+			score = 2
+		case a == Sharp || a == Flat:
+			score = 1
+		}
+
+		if score > bestScore {
+			best = spelledKey(key, l, a)
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// spelledKey returns the SpelledPitch for a key number, using the given
+// letter and accidental. The octave is calculated from the natural note
+func spelledKey(key int, l Letter, a Accidental) SpelledPitch {
+	natural := key - int(a) - letterKeys[l]
+	octave := natural / int(Octave)
+	if natural < 0 && natural%int(Octave) != 0 {
+		octave--
+	}
+
+	return SpelledPitch{
+		Letter:     l,
+		Accidental: a,
+		Octave:     octave - 1,
+	}
+}
+
+// Spell returns the pitch as a SpelledPitch. Pitches that are already
+// spelled are returned unchanged, the rest are spelled with Sharps. ok is
+// false for rests
+func Spell(p Pitch) (s SpelledPitch, ok bool) {
+	return SpellWith(Sharps, p)
+}
+
+// SpellWith returns the pitch as a SpelledPitch. Pitches that are already
+// spelled are returned unchanged, the rest are spelled with the Speller. ok
+// is false for rests
+func SpellWith(s Speller, p Pitch) (SpelledPitch, bool) {
+	if sp, ok := p.(SpelledPitch); ok {
+		return sp, true
+	}
+	return s.Spell(p)
+}
+
+// PitchClass returns the position of a key number in the octave, 0 for C to
+// 11 for B
+func PitchClass(key int) int {
+	octave := int(Octave)
+	return (key%octave + octave) % octave
+}
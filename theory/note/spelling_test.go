@@ -0,0 +1,148 @@
+package note_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/stretchr/testify/assert"
+)
+
+// spelled returns the name of a spelled pitch, or "rest" if it could not be
+// spelled
+func spelled(s note.SpelledPitch, ok bool) string {
+	if !ok {
+		return "rest"
+	}
+	return s.String()
+}
+
+func TestSpelledPitchString(t *testing.T) {
+	assert.Equal(t, "Bb4", note.NewSpelledPitch(note.B, note.Flat, 4).String())
+	assert.Equal(t, "A#4", note.NewSpelledPitch(note.A, note.Sharp, 4).String())
+	assert.Equal(t, "F##3", note.NewSpelledPitch(note.F, note.DoubleSharp, 3).String())
+	assert.Equal(t, "Ebb5", note.NewSpelledPitch(note.E, note.DoubleFlat, 5).String())
+	assert.Equal(t, "C-1", note.NewSpelledPitch(note.C, note.Natural, -1).String())
+	assert.Equal(t, "Bb", note.NewSpelledPitch(note.B, note.Flat, 4).Name())
+
+	n := note.NewNote(note.NewSpelledPitch(note.B, note.Flat, 4), note.Quarter)
+	assert.Equal(t, "♩ Bb4", fmt.Sprint(n))
+}
+
+func TestSpelledPitchKey(t *testing.T) {
+	assert.Equal(t, note.Asharp4.Key(), note.NewSpelledPitch(note.B, note.Flat, 4).Key())
+	assert.Equal(t, note.C5.Key(), note.NewSpelledPitch(note.B, note.Sharp, 4).Key())
+	assert.Equal(t, note.B3.Key(), note.NewSpelledPitch(note.C, note.Flat, 4).Key())
+	assert.Equal(t, note.G3.Key(), note.NewSpelledPitch(note.F, note.DoubleSharp, 3).Key())
+
+	assert.Equal(t, note.Asharp4.Frequency(), note.NewSpelledPitch(note.B, note.Flat, 4).Frequency())
+	assert.InDelta(t, 7.7169, note.NewSpelledPitch(note.C, note.Flat, -1).Frequency(), 0.0001)
+}
+
+func TestSpelledPitchAdd(t *testing.T) {
+	c4 := note.NewSpelledPitch(note.C, note.Natural, 4)
+	assert.Equal(t, "Eb4", c4.Add(note.Tone+note.Semitone).String())
+	assert.Equal(t, "Bb4", c4.Add(10).String())
+	assert.Equal(t, "F#4", c4.Add(6).String())
+	assert.Equal(t, "C5", c4.Add(note.Octave).String())
+	assert.Equal(t, "A3", c4.Subtract(note.Tone+note.Semitone).String())
+
+	bflat := note.NewSpelledPitch(note.B, note.Flat, 3)
+	assert.Equal(t, "Eb4", bflat.Add(5).String())
+	assert.Equal(t, "Ab4", bflat.Add(10).String())
+
+	csharp := note.NewSpelledPitch(note.C, note.Sharp, 4)
+	assert.Equal(t, "E#4", csharp.Add(4).String())
+	assert.Equal(t, "B#3", csharp.Subtract(1).String())
+}
+
+func TestFifths(t *testing.T) {
+	assert.Equal(t, 0, note.NewSpelledPitch(note.C, note.Natural, 4).Fifths())
+	assert.Equal(t, 1, note.NewSpelledPitch(note.G, note.Natural, 4).Fifths())
+	assert.Equal(t, 5, note.NewSpelledPitch(note.B, note.Natural, 4).Fifths())
+	assert.Equal(t, -1, note.NewSpelledPitch(note.F, note.Natural, 4).Fifths())
+	assert.Equal(t, -2, note.NewSpelledPitch(note.B, note.Flat, 4).Fifths())
+	assert.Equal(t, 7, note.NewSpelledPitch(note.C, note.Sharp, 4).Fifths())
+	assert.Equal(t, -6, note.NewSpelledPitch(note.G, note.Flat, 4).Fifths())
+}
+
+func TestKeySignatureAccidental(t *testing.T) {
+	g := note.KeySignature(1)
+	assert.Equal(t, note.Sharp, g.Accidental(note.F))
+	assert.Equal(t, note.Natural, g.Accidental(note.C))
+
+	eflat := note.KeySignature(-3)
+	assert.Equal(t, note.Flat, eflat.Accidental(note.B))
+	assert.Equal(t, note.Flat, eflat.Accidental(note.E))
+	assert.Equal(t, note.Flat, eflat.Accidental(note.A))
+	assert.Equal(t, note.Natural, eflat.Accidental(note.D))
+
+	gsharp := note.KeySignature(8)
+	assert.Equal(t, note.DoubleSharp, gsharp.Accidental(note.F))
+	assert.Equal(t, note.Sharp, gsharp.Accidental(note.C))
+
+	for l := note.C; l <= note.B; l++ {
+		assert.Equal(t, note.Natural, note.KeySignature(0).Accidental(l))
+		assert.Equal(t, note.Sharp, note.KeySignature(7).Accidental(l))
+		assert.Equal(t, note.Flat, note.KeySignature(-7).Accidental(l))
+	}
+}
+
+func TestKeySignatureSpell(t *testing.T) {
+	spell := func(k note.KeySignature, pitches ...note.Pitch) string {
+		var s string
+		for _, p := range pitches {
+			s += spelled(k.Spell(p)) + " "
+		}
+		return s
+	}
+
+	// F major
+	assert.Equal(t, "F4 G4 A4 Bb4 C5 D5 E5 ",
+		spell(-1, note.F4, note.G4, note.A4, note.Asharp4, note.C5, note.D5, note.E5))
+	// E major
+	assert.Equal(t, "E4 F#4 G#4 A4 B4 C#5 D#5 ",
+		spell(4, note.E4, note.Fsharp4, note.Gsharp4, note.A4, note.B4, note.Csharp5, note.Dsharp5))
+	// C# major
+	assert.Equal(t, "C#4 D#4 E#4 F#4 G#4 A#4 B#4 ",
+		spell(7, note.Csharp4, note.Dsharp4, note.F4, note.Fsharp4, note.Gsharp4, note.Asharp4, note.C5))
+	// Gb major
+	assert.Equal(t, "Gb4 Ab4 Bb4 Cb5 Db5 Eb5 F5 ",
+		spell(-6, note.Fsharp4, note.Gsharp4, note.Asharp4, note.B4, note.Csharp5, note.Dsharp5, note.F5))
+
+	// Notes outside of the key
+	assert.Equal(t, "C#4 Eb4 F#4 Ab4 Bb4 ", spell(0, note.Csharp4, note.Dsharp4, note.Fsharp4, note.Gsharp4, note.Asharp4))
+	assert.Equal(t, "D#4 A#4 F4 ", spell(2, note.Dsharp4, note.Asharp4, note.F4))
+	assert.Equal(t, "Db4 Gb4 B4 ", spell(-3, note.Csharp4, note.Fsharp4, note.B4))
+}
+
+func TestSharpsFlats(t *testing.T) {
+	assert.Equal(t, "A#4", spelled(note.Sharps.Spell(note.Asharp4)))
+	assert.Equal(t, "Bb4", spelled(note.Flats.Spell(note.Asharp4)))
+	assert.Equal(t, "C4", spelled(note.Flats.Spell(note.C4)))
+	assert.Equal(t, "Db-1", spelled(note.Flats.Spell(note.Csharp_1)))
+	assert.Equal(t, "G9", spelled(note.Sharps.Spell(note.G9)))
+
+	for p := note.C_1; p < note.G9; p++ {
+		assert.Equal(t, p.String(), spelled(note.Spell(p)))
+	}
+
+	bflat := note.NewSpelledPitch(note.B, note.Flat, 4)
+	assert.Equal(t, "Bb4", spelled(note.Spell(bflat)))
+	assert.Equal(t, "Bb4", spelled(note.SpellWith(note.Sharps, bflat)))
+	assert.Equal(t, "Bb4", spelled(note.SpellWith(note.Flats, note.Asharp4)))
+
+	// Rests can't be spelled
+	rest := note.NewRest(note.Quarter).Pitch
+	assert.Equal(t, "rest", spelled(note.Spell(rest)))
+	assert.Equal(t, "rest", spelled(note.Flats.Spell(rest)))
+	assert.Equal(t, "rest", spelled(note.KeySignature(-3).Spell(rest)))
+}
+
+func TestPitchClass(t *testing.T) {
+	assert.Equal(t, 0, note.PitchClass(note.C4.Key()))
+	assert.Equal(t, 11, note.PitchClass(note.B4.Key()))
+	assert.Equal(t, 7, note.PitchClass(note.G9.Key()))
+	assert.Equal(t, 11, note.PitchClass(-1))
+	assert.Equal(t, 0, note.PitchClass(-12))
+}
@@ -0,0 +1,182 @@
+package scale
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Scale is a pattern of step intervals between consecutive notes. The steps
+// of a scale add up to one octave
+type Scale []note.Interval
+
+// minorThird is a whole step and a half, used by the pentatonic and blues scales
+const minorThird = note.Tone + note.Semitone
+
+var (
+	// Major scale: whole, whole, half, whole, whole, whole, half
+	Major = Scale{
+		note.Tone, note.Tone, note.Semitone, note.Tone, note.Tone, note.Tone, note.Semitone}
+
+	// Ionian mode, the same pattern as the Major scale
+	Ionian = Major
+	// Dorian mode: whole, half, whole, whole, whole, half, whole
+	Dorian = Major.Mode(2)
+	// Phrygian mode: half, whole, whole, whole, half, whole, whole
+	Phrygian = Major.Mode(3)
+	// Lydian mode: whole, whole, whole, half, whole, whole, half
+	Lydian = Major.Mode(4)
+	// Mixolydian mode: whole, whole, half, whole, whole, half, whole
+	Mixolydian = Major.Mode(5)
+	// Aeolian mode: whole, half, whole, whole, half, whole, whole
+	Aeolian = Major.Mode(6)
+	// Locrian mode: half, whole, whole, half, whole, whole, whole
+	Locrian = Major.Mode(7)
+
+	// NaturalMinor scale, the same pattern as the Aeolian mode
+	NaturalMinor = Aeolian
+	// HarmonicMinor scale: whole, half, whole, whole, half, whole and a half, half
+	HarmonicMinor = Scale{
+		note.Tone, note.Semitone, note.Tone, note.Tone, note.Semitone, minorThird, note.Semitone}
+	// MelodicMinor scale (ascending): whole, half, whole, whole, whole, whole, half
+	MelodicMinor = Scale{
+		note.Tone, note.Semitone, note.Tone, note.Tone, note.Tone, note.Tone, note.Semitone}
+
+	// MajorPentatonic scale: whole, whole, whole and a half, whole, whole and a half
+	MajorPentatonic = Scale{note.Tone, note.Tone, minorThird, note.Tone, minorThird}
+	// MinorPentatonic scale: whole and a half, whole, whole, whole and a half, whole
+	MinorPentatonic = MajorPentatonic.Mode(5)
+
+	// Blues scale: whole and a half, whole, half, half, whole and a half, whole
+	Blues = Scale{minorThird, note.Tone, note.Semitone, note.Semitone, minorThird, note.Tone}
+
+	// WholeTone scale: whole, whole, whole, whole, whole, whole
+	WholeTone = Scale{note.Tone, note.Tone, note.Tone, note.Tone, note.Tone, note.Tone}
+
+	// Diminished scale, starting with a whole step: whole, half, whole, half...
+	Diminished = Scale{
+		note.Tone, note.Semitone, note.Tone, note.Semitone,
+		note.Tone, note.Semitone, note.Tone, note.Semitone}
+	// DominantDiminished scale, starting with a half step: half, whole, half, whole...
+	DominantDiminished = Diminished.Mode(2)
+
+	// Chromatic scale: all the 12 half steps in an octave
+	Chromatic = Scale{
+		note.Semitone, note.Semitone, note.Semitone, note.Semitone,
+		note.Semitone, note.Semitone, note.Semitone, note.Semitone,
+		note.Semitone, note.Semitone, note.Semitone, note.Semitone}
+)
+
+// New returns a user-defined Scale with the given step intervals. It returns
+// an error if any step is 0, or if the steps do not add up to one octave
+func New(steps ...note.Interval) (Scale, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("a scale needs at least one step")
+	}
+
+	var total int
+	for i, step := range steps {
+		if step == 0 {
+			return nil, fmt.Errorf("step %d is an empty interval", i+1)
+		}
+		total += int(step)
+	}
+
+	if total != int(note.Octave) {
+		return nil, fmt.Errorf(
+			"the steps add up to %d semitones, they must add up to one octave (%d)",
+			total, note.Octave)
+	}
+
+	s := make(Scale, len(steps))
+	copy(s, steps)
+	return s, nil
+}
+
+// Mode returns a new Scale starting on the given degree of this scale. The
+// first degree is 1, and returns the same pattern. The modes of an empty
+// scale are empty
+func (s Scale) Mode(degree int) Scale {
+	if len(s) == 0 {
+		return Scale{}
+	}
+
+	n := (degree - 1) % len(s)
+	if n < 0 {
+		n += len(s)
+	}
+
+	mode := make(Scale, 0, len(s))
+	mode = append(mode, s[n:]...)
+	return append(mode, s[:n]...)
+}
+
+// Len returns the number of different notes in the scale
+func (s Scale) Len() int {
+	return len(s)
+}
+
+// From returns the pitches of the scale built on tonic, in ascending order.
+// The last element is the tonic one octave higher
+func (s Scale) From(tonic note.Pitch) []note.Pitch {
+	pitches := []note.Pitch{tonic}
+	for _, step := range s {
+		pitches = append(pitches, pitches[len(pitches)-1].Add(step))
+	}
+
+	return pitches
+}
+
+// Range returns all the pitches of the scale built on tonic that are between
+// low and high, both included, in ascending order. The tonic does not need to
+// be in the given range, it is only used to place the pattern. If low or
+// high is a rest, the result is empty
+func (s Scale) Range(tonic, low, high note.Pitch) []note.Pitch {
+	if low.Key() < 0 || high.Key() < 0 {
+		return nil
+	}
+
+	var pitches []note.Pitch
+	for p := low; p.Key() <= high.Key(); p = p.Add(note.Semitone) {
+		if s.Contains(tonic, p) {
+			pitches = append(pitches, p)
+		}
+
+		// Adding to the highest pitch would overflow
+		if p.Key() >= note.MaxKey {
+			break
+		}
+	}
+
+	return pitches
+}
+
+// Contains returns true if the pitch belongs to the scale built on tonic, in
+// any octave
+func (s Scale) Contains(tonic, p note.Pitch) bool {
+	_, ok := s.Degree(tonic, p)
+	return ok
+}
+
+// Degree returns the position of the pitch in the scale built on tonic, in
+// any octave. The tonic is the degree 1. It returns false if the pitch does
+// not belong to the scale
+func (s Scale) Degree(tonic, p note.Pitch) (int, bool) {
+	if tonic.Key() < 0 || p.Key() < 0 {
+		return 0, false
+	}
+
+	octave := int(note.Octave)
+	distance := ((p.Key()-tonic.Key())%octave + octave) % octave
+
+	var pos int
+	for i, step := range s {
+		if pos == distance {
+			return i + 1, true
+		}
+		pos += int(step)
+	}
+
+	return 0, false
+}
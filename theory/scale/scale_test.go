@@ -0,0 +1,130 @@
+package scale_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/scale"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom(t *testing.T) {
+	assert.Equal(t,
+		[]note.Pitch{note.C4, note.D4, note.E4, note.F4, note.G4, note.A4, note.B4, note.C5},
+		scale.Major.From(note.C4))
+
+	assert.Equal(t,
+		[]note.Pitch{note.E4, note.Fsharp4, note.Gsharp4, note.A4, note.B4, note.Csharp5, note.Dsharp5, note.E5},
+		scale.Major.From(note.E4))
+
+	assert.Equal(t,
+		[]note.Pitch{note.A3, note.B3, note.C4, note.D4, note.E4, note.F4, note.G4, note.A4},
+		scale.NaturalMinor.From(note.A3))
+
+	assert.Equal(t,
+		[]note.Pitch{note.A3, note.B3, note.C4, note.D4, note.E4, note.F4, note.Gsharp4, note.A4},
+		scale.HarmonicMinor.From(note.A3))
+
+	assert.Equal(t,
+		[]note.Pitch{note.A3, note.B3, note.C4, note.D4, note.E4, note.Fsharp4, note.Gsharp4, note.A4},
+		scale.MelodicMinor.From(note.A3))
+
+	assert.Equal(t,
+		[]note.Pitch{note.A3, note.C4, note.D4, note.Dsharp4, note.E4, note.G4, note.A4},
+		scale.Blues.From(note.A3))
+
+	assert.Equal(t,
+		[]note.Pitch{note.C4, note.D4, note.E4, note.G4, note.A4, note.C5},
+		scale.MajorPentatonic.From(note.C4))
+
+	assert.Equal(t,
+		[]note.Pitch{note.A3, note.C4, note.D4, note.E4, note.G4, note.A4},
+		scale.MinorPentatonic.From(note.A3))
+}
+
+func TestModes(t *testing.T) {
+	// All the modes of C major share the same notes
+	cMajor := scale.Major.From(note.C4)
+	modes := []scale.Scale{
+		scale.Ionian, scale.Dorian, scale.Phrygian, scale.Lydian,
+		scale.Mixolydian, scale.Aeolian, scale.Locrian}
+
+	for i, mode := range modes {
+		assert.Equal(t, cMajor[i:], mode.From(cMajor[i])[:8-i], "mode %d", i+1)
+	}
+
+	assert.Equal(t, scale.Aeolian, scale.NaturalMinor)
+	assert.Equal(t, scale.Major, scale.Major.Mode(8))
+	assert.Equal(t, scale.Locrian, scale.Major.Mode(0))
+	assert.Empty(t, scale.Scale{}.Mode(3))
+}
+
+func TestPatternsSpanOneOctave(t *testing.T) {
+	scales := []scale.Scale{
+		scale.Major, scale.Dorian, scale.Phrygian, scale.Lydian, scale.Mixolydian,
+		scale.Aeolian, scale.Locrian, scale.HarmonicMinor, scale.MelodicMinor,
+		scale.MajorPentatonic, scale.MinorPentatonic, scale.Blues, scale.WholeTone,
+		scale.Diminished, scale.DominantDiminished, scale.Chromatic}
+
+	for _, s := range scales {
+		p := s.From(note.C4)
+		assert.Equal(t, note.C5, p[len(p)-1])
+		assert.Equal(t, s.Len()+1, len(p))
+	}
+}
+
+func TestNew(t *testing.T) {
+	// Hirajōshi scale
+	s, err := scale.New(note.Tone, note.Semitone, 2*note.Tone, note.Semitone, 2*note.Tone)
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]note.Pitch{note.A4, note.B4, note.C5, note.E5, note.F5, note.A5},
+		s.From(note.A4))
+
+	_, err = scale.New(note.Tone, note.Tone)
+	assert.Error(t, err)
+
+	_, err = scale.New(note.Octave, 0)
+	assert.Error(t, err)
+
+	_, err = scale.New()
+	assert.Error(t, err)
+}
+
+func TestRange(t *testing.T) {
+	assert.Equal(t,
+		[]note.Pitch{note.Fsharp3, note.G3, note.A3, note.B3, note.C4},
+		scale.Major.Range(note.G4, note.F3, note.C4))
+
+	assert.Equal(t,
+		[]note.Pitch{note.C4, note.D4, note.E4, note.Fsharp4, note.Gsharp4, note.Asharp4, note.C5},
+		scale.WholeTone.Range(note.C1, note.B3, note.Csharp5))
+
+	assert.Empty(t, scale.Major.Range(note.C4, note.C5, note.C4))
+
+	top := scale.Chromatic.Range(note.C4, note.G9, note.G9)
+	assert.Equal(t, []note.Pitch{note.G9}, top)
+
+	rest := note.NewRest(note.Quarter).Pitch
+	assert.Empty(t, scale.Major.Range(note.C4, rest, note.C5))
+	assert.Empty(t, scale.Major.Range(note.C4, note.C4, rest))
+}
+
+func TestContains(t *testing.T) {
+	assert.True(t, scale.Major.Contains(note.D4, note.Fsharp2))
+	assert.True(t, scale.Major.Contains(note.D4, note.D7))
+	assert.False(t, scale.Major.Contains(note.D4, note.F4))
+	assert.False(t, scale.Major.Contains(note.D4, note.NewRest(note.Quarter).Pitch))
+
+	degree, ok := scale.Major.Degree(note.D4, note.Csharp3)
+	assert.True(t, ok)
+	assert.Equal(t, 7, degree)
+
+	degree, ok = scale.Blues.Degree(note.A3, note.Dsharp6)
+	assert.True(t, ok)
+	assert.Equal(t, 4, degree)
+
+	_, ok = scale.Blues.Degree(note.A3, note.B3)
+	assert.False(t, ok)
+}
@@ -0,0 +1,80 @@
+package scale
+
+import (
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// heptatonic is the number of notes in scales that use each letter once,
+// like the major scale and its modes
+const heptatonic = 7
+
+// Spell returns the pitches of the scale built on tonic, in ascending order,
+// like From. Scales with 7 notes use each letter name once, e.g. the F major
+// scale has a Bb instead of an A#. Other scales are spelled as in the major
+// key of the tonic. The last element is the tonic one octave higher
+func (s Scale) Spell(tonic note.SpelledPitch) []note.SpelledPitch {
+	pitches := []note.SpelledPitch{tonic}
+	key := tonic.Key()
+
+	for i, step := range s {
+		key += int(step)
+
+		if s.Len() != heptatonic {
+			// SpelledPitch.Add spells the result in the major key of the tonic
+			spelled, _ := note.Spell(tonic.Add(note.Interval(key - tonic.Key())))
+			pitches = append(pitches, spelled)
+			continue
+		}
+
+		letter := int(tonic.Letter) + i + 1
+		natural := note.SpelledPitch{
+			Letter: note.Letter(letter % heptatonic),
+			Octave: tonic.Octave + letter/heptatonic,
+		}
+		natural.Accidental = note.Accidental(key - natural.Key())
+		pitches = append(pitches, natural)
+	}
+
+	// The octave keeps the tonic spelling
+	octave := tonic
+	octave.Octave++
+	pitches[len(pitches)-1] = octave
+
+	return pitches
+}
+
+// Speller returns a note.Speller for the context of the scale built on
+// tonic. Pitches that belong to the scale are spelled as in Spell, in any
+// octave. The rest are spelled as in the major key of the tonic
+func (s Scale) Speller(tonic note.SpelledPitch) note.Speller {
+	return speller{
+		pitches:  s.Spell(tonic),
+		fallback: note.KeySignature(tonic.Fifths()),
+	}
+}
+
+// speller spells pitches with the spelling of a reference pitch with the same
+// pitch class, or with a key signature if none matches
+type speller struct {
+	pitches  []note.SpelledPitch
+	fallback note.KeySignature
+}
+
+// Spell returns the pitch with the spelling it has in the scale. ok is false
+// for rests
+func (sp speller) Spell(p note.Pitch) (s note.SpelledPitch, ok bool) {
+	if p.Key() < 0 {
+		return note.SpelledPitch{}, false
+	}
+
+	octave := int(note.Octave)
+	for _, ref := range sp.pitches {
+		diff := p.Key() - ref.Key()
+		if diff%octave == 0 {
+			ref.Octave += diff / octave
+			return ref, true
+		}
+	}
+
+	return sp.fallback.Spell(p)
+}
@@ -0,0 +1,58 @@
+package scale_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+// names returns the pitch names separated by spaces
+func names(pitches []note.SpelledPitch) string {
+	s := make([]string, len(pitches))
+	for i, p := range pitches {
+		s[i] = p.String()
+	}
+	return strings.Join(s, " ")
+}
+
+// spelled returns the name of a spelled pitch, or "rest" if it could not be
+// spelled
+func spelled(s note.SpelledPitch, ok bool) string {
+	if !ok {
+		return "rest"
+	}
+	return s.String()
+}
+
+func TestSpell(t *testing.T) {
+	f := note.NewSpelledPitch(note.F, note.Natural, 4)
+	assert.Equal(t, "F4 G4 A4 Bb4 C5 D5 E5 F5", names(scale.Major.Spell(f)))
+
+	dsharp := note.NewSpelledPitch(note.D, note.Sharp, 4)
+	assert.Equal(t, "D#4 E#4 F##4 G#4 A#4 B#4 C##5 D#5", names(scale.Major.Spell(dsharp)))
+
+	a := note.NewSpelledPitch(note.A, note.Natural, 3)
+	assert.Equal(t, "A3 B3 C4 D4 E4 F4 G#4 A4", names(scale.HarmonicMinor.Spell(a)))
+
+	eflat := note.NewSpelledPitch(note.E, note.Flat, 4)
+	assert.Equal(t, "Eb4 F4 Gb4 Ab4 Bb4 Cb5 Db5 Eb5", names(scale.NaturalMinor.Spell(eflat)))
+
+	assert.Equal(t, "Eb4 Gb4 Ab4 A4 Bb4 Db5 Eb5", names(scale.Blues.Spell(eflat)))
+	assert.Equal(t, "F4 G4 A4 C5 D5 F5", names(scale.MajorPentatonic.Spell(f)))
+}
+
+func TestSpeller(t *testing.T) {
+	d := note.NewSpelledPitch(note.D, note.Natural, 4)
+	speller := scale.HarmonicMinor.Speller(d)
+
+	assert.Equal(t, "C#6", spelled(speller.Spell(note.Csharp6)))
+	assert.Equal(t, "Bb2", spelled(speller.Spell(note.Asharp2)))
+	assert.Equal(t, "F4", spelled(speller.Spell(note.F4)))
+	// Not in the scale, spelled as in D major
+	assert.Equal(t, "G#4", spelled(speller.Spell(note.Gsharp4)))
+
+	assert.Equal(t, "rest", spelled(speller.Spell(note.NewRest(note.Quarter).Pitch)))
+}
@@ -0,0 +1,104 @@
+package score
+
+import (
+	"math"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Velocities returns the MIDI velocity of each note of the voice, with the
+// same shape as its groups. A dynamic applies from its note until the next
+// one, starting with note.DefaultDynamic. A hairpin changes the velocity
+// gradually up to the next dynamic, or one level up or down if there is none.
+// Articulations add their emphasis to each note
+func (v Voice) Velocities() [][]int {
+	positions := make([]note.Rational, len(v)+1)
+	for i, group := range v {
+		positions[i+1] = positions[i].Add(groupDuration(group))
+	}
+
+	base := make([]float64, len(v))
+	current := note.DefaultDynamic
+	for i := 0; i < len(v); i++ {
+		if d := groupDynamic(v[i]); d != note.NoDynamic {
+			current = d
+		}
+		base[i] = float64(current.Velocity())
+
+		h := groupHairpin(v[i])
+		if h == note.NoHairpin {
+			continue
+		}
+
+		// the hairpin ends on the next dynamic marking
+		end := i + 1
+		for end < len(v) && groupDynamic(v[end]) == note.NoDynamic {
+			end++
+		}
+
+		var target note.Dynamic
+		if end < len(v) {
+			target = groupDynamic(v[end])
+		} else {
+			target = step(current, h)
+		}
+
+		from, to := base[i], float64(target.Velocity())
+		length := positions[end].Sub(positions[i]).Duration()
+		for j := i + 1; j < end; j++ {
+			x := positions[j].Sub(positions[i]).Duration()
+			base[j] = from + (to-from)*float64(x/length)
+		}
+
+		// the group at end sets its own dynamic in the next iteration
+		i = end - 1
+	}
+
+	velocities := make([][]int, len(v))
+	for i, group := range v {
+		velocities[i] = make([]int, len(group))
+		for j, n := range group {
+			vel := int(math.Round(base[i] * n.Articulation.Emphasis()))
+			if vel < 1 {
+				vel = 1
+			}
+			if vel > 127 {
+				vel = 127
+			}
+			velocities[i][j] = vel
+		}
+	}
+	return velocities
+}
+
+// groupDynamic returns the first dynamic marking in the group
+func groupDynamic(group []note.Note) note.Dynamic {
+	for _, n := range group {
+		if n.Dynamic != note.NoDynamic {
+			return n.Dynamic
+		}
+	}
+	return note.NoDynamic
+}
+
+// groupHairpin returns the first hairpin in the group
+func groupHairpin(group []note.Note) note.Hairpin {
+	for _, n := range group {
+		if n.Hairpin != note.NoHairpin {
+			return n.Hairpin
+		}
+	}
+	return note.NoHairpin
+}
+
+// step returns the next dynamic in the direction of the hairpin, limited to
+// ppp and fff
+func step(d note.Dynamic, h note.Hairpin) note.Dynamic {
+	if h == note.Crescendo && d < note.Fortississimo {
+		return d + 1
+	}
+	if h == note.Diminuendo && d > note.Pianississimo {
+		return d - 1
+	}
+	return d
+}
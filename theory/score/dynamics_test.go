@@ -0,0 +1,47 @@
+package score_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+)
+
+func quarters(notes ...note.Note) score.Voice {
+	v := make(score.Voice, len(notes))
+	for i, n := range notes {
+		v[i] = []note.Note{n}
+	}
+	return v
+}
+
+func TestVelocities(t *testing.T) {
+	c := note.NewNote(note.C4, note.Quarter)
+
+	// No markings are mf
+	assert.Equal(t, [][]int{{80}, {80}}, quarters(c, c).Velocities())
+
+	// Dynamics apply until the next one, articulations only to their note
+	assert.Equal(t, [][]int{{48}, {48}, {60}, {112}}, quarters(
+		c.WithDynamic(note.Piano), c, c.Articulate(note.Accent), c.WithDynamic(note.Fortissimo),
+	).Velocities())
+
+	// Crescendo from p to f, over 4 quarters
+	assert.Equal(t, [][]int{{48}, {60}, {72}, {84}, {96}}, quarters(
+		c.WithDynamic(note.Piano).Crescendo(), c, c, c, c.WithDynamic(note.Forte),
+	).Velocities())
+
+	// The hairpin is placed by duration, not by number of notes
+	e := note.NewNote(note.E4, note.Eighth)
+	assert.Equal(t, [][]int{{96}, {80}, {72}, {64}}, score.Voice{
+		{c.WithDynamic(note.Forte).Diminuendo()}, {e}, {e}, {c.WithDynamic(note.MezzoPiano)},
+	}.Velocities())
+
+	// Without a dynamic at the end a hairpin goes one level, mf to mp
+	assert.Equal(t, [][]int{{80}, {72}}, quarters(c.Diminuendo(), c).Velocities())
+
+	// Velocities are limited to 127
+	chord := []note.Note{c.WithDynamic(note.Fortississimo), c.Articulate(note.Marcato)}
+	assert.Equal(t, [][]int{{127, 127}}, score.Voice{chord}.Velocities())
+}
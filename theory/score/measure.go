@@ -0,0 +1,202 @@
+package score
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Voice is a melodic line, made of groups of simultaneous notes played one
+// after another. Each group lasts as long as its longest note
+type Voice [][]note.Note
+
+// Duration returns the exact total duration of the groups
+func (v Voice) Duration() note.Rational {
+	var total note.Rational
+	for _, group := range v {
+		total = total.Add(groupDuration(group))
+	}
+	return total
+}
+
+// groupDuration returns the duration of the longest note in the group
+func groupDuration(group []note.Note) note.Rational {
+	var longest note.Rational
+	for _, n := range group {
+		if d := n.Duration.Rational(); d.Cmp(longest) > 0 {
+			longest = d
+		}
+	}
+	return longest
+}
+
+// PitchedNotes returns the notes of the group that are not rests, or its
+// first note if they are all rests. It is the group as written by notations
+// without rests in chords
+func PitchedNotes(group []note.Note) []note.Note {
+	var notes []note.Note
+	for _, n := range group {
+		if n.Key() >= 0 {
+			notes = append(notes, n)
+		}
+	}
+	if len(notes) == 0 {
+		return group[:1]
+	}
+	return notes
+}
+
+// Measure is a bar of music, made of one or more independent voices played
+// at the same time
+type Measure struct {
+	Time   TimeSignature
+	Voices []Voice
+}
+
+// NewMeasure returns a Measure with the given time signature and a single
+// voice made of groups of simultaneous notes
+func NewMeasure(t TimeSignature, groups ...[]note.Note) Measure {
+	return Measure{Time: t, Voices: []Voice{groups}}
+}
+
+// NewPolyphonicMeasure returns a Measure with the given time signature and
+// independent voices
+func NewPolyphonicMeasure(t TimeSignature, voices ...Voice) Measure {
+	return Measure{Time: t, Voices: voices}
+}
+
+// Duration returns the exact duration of the longest voice
+func (m Measure) Duration() note.Rational {
+	var longest note.Rational
+	for _, v := range m.Voices {
+		if d := v.Duration(); d.Cmp(longest) > 0 {
+			longest = d
+		}
+	}
+	return longest
+}
+
+// Staff is a sequence of measures
+type Staff []Measure
+
+// NewStaff returns a Staff where all the measures have the same time
+// signature and a single voice. Each measure is given as its groups of
+// simultaneous notes
+func NewStaff(t TimeSignature, measures ...[][]note.Note) Staff {
+	s := make(Staff, len(measures))
+	for i, groups := range measures {
+		s[i] = NewMeasure(t, groups...)
+	}
+	return s
+}
+
+// NumVoices returns the number of voices of the measure with the most voices
+func (s Staff) NumVoices() int {
+	n := 0
+	for _, m := range s {
+		if len(m.Voices) > n {
+			n = len(m.Voices)
+		}
+	}
+	return n
+}
+
+// Voice returns the groups of simultaneous notes of the voice with the given
+// index in all the measures, in order. Measures where the voice is missing
+// are filled with a rest of the full measure, so all the voices stay aligned
+func (s Staff) Voice(i int) Voice {
+	var voice Voice
+	for _, m := range s {
+		if i < len(m.Voices) {
+			voice = append(voice, m.Voices[i]...)
+			continue
+		}
+		voice = append(voice, []note.Note{note.NewRest(m.Time.Duration().Duration())})
+	}
+	return voice
+}
+
+// Voices returns all the voices of the staff, see Voice
+func (s Staff) Voices() []Voice {
+	voices := make([]Voice, s.NumVoices())
+	for i := range voices {
+		voices[i] = s.Voice(i)
+	}
+	return voices
+}
+
+// Duration returns the exact total duration of the measures
+func (s Staff) Duration() note.Rational {
+	var total note.Rational
+	for _, m := range s {
+		total = total.Add(m.Duration())
+	}
+	return total
+}
+
+// MeasureError describes a voice of a measure with a duration different from
+// the one set by its time signature
+type MeasureError struct {
+	// Index is the position of the measure in the staff, starting at 0
+	Index int
+	// Voice is the index of the voice in the measure, starting at 0
+	Voice int
+	// Expected is the duration set by the time signature
+	Expected note.Rational
+	// Actual is the total duration of the notes in the voice
+	Actual note.Rational
+}
+
+// Over returns true if the measure is too long
+func (e MeasureError) Over() bool {
+	return e.Actual.Cmp(e.Expected) > 0
+}
+
+// Error returns a description of the error. Measures and voices are numbered
+// from 1, as written in the score. The voice is omitted for the first one
+func (e MeasureError) Error() string {
+	state := "underfull"
+	if e.Over() {
+		state = "overfull"
+	}
+
+	bar := fmt.Sprintf("bar %d", e.Index+1)
+	if e.Voice > 0 {
+		bar = fmt.Sprintf("%s voice %d", bar, e.Voice+1)
+	}
+	return fmt.Sprintf("%s is %s: %v of a whole note instead of %v",
+		bar, state, e.Actual, e.Expected)
+}
+
+// StaffError lists the measures of a staff with a wrong duration
+type StaffError []MeasureError
+
+// Error returns a description of all the measure errors
+func (e StaffError) Error() string {
+	s := make([]string, len(e))
+	for i, m := range e {
+		s[i] = m.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Validate checks that the notes of each voice fill exactly the time
+// signature of its measure. It returns a StaffError with the overfull and
+// underfull voices, or nil
+func (s Staff) Validate() error {
+	var errs StaffError
+	for i, m := range s {
+		expected := m.Time.Duration()
+		for j, v := range m.Voices {
+			if actual := v.Duration(); actual != expected {
+				errs = append(errs, MeasureError{Index: i, Voice: j, Expected: expected, Actual: actual})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package score_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeSignature(t *testing.T) {
+	for s, expected := range map[string]score.TimeSignature{
+		"4/4":   score.CommonTime,
+		"C":     score.CommonTime,
+		"C|":    score.CutTime,
+		"3/4":   {Beats: 3, Value: 4},
+		" 6/8 ": {Beats: 6, Value: 8},
+		"7/16":  {Beats: 7, Value: 16},
+	} {
+		ts, err := score.ParseTimeSignature(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, ts, s)
+		}
+	}
+
+	for _, s := range []string{"", "4", "4/3", "0/4", "a/4", "3/4/4", "4/0"} {
+		_, err := score.ParseTimeSignature(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestTimeSignature(t *testing.T) {
+	ts := score.TimeSignature{Beats: 6, Value: 8}
+	assert.Equal(t, "6/8", ts.String())
+	assert.Equal(t, note.Eighth, ts.Beat())
+	assert.Equal(t, note.NewRational(3, 4), ts.Duration())
+	assert.Equal(t, note.NewRational(1, 1), score.CutTime.Duration())
+}
+
+func TestMeasureDuration(t *testing.T) {
+	m := score.NewMeasure(score.TimeSignature{Beats: 3, Value: 4},
+		[]note.Note{note.NewNote(note.C4, note.Quarter), note.NewNote(note.E4, note.Half)},
+		[]note.Note{note.NewNote(note.D4, note.Eighth.Triplet())},
+		[]note.Note{note.NewNote(note.D4, note.Eighth.Triplet())},
+		[]note.Note{note.NewNote(note.D4, note.Eighth.Triplet())},
+	)
+	assert.Equal(t, note.NewRational(3, 4), m.Duration())
+	assert.Equal(t, note.Rational{}, score.Measure{}.Duration())
+}
+
+func TestValidate(t *testing.T) {
+	eighth := []note.Note{note.NewNote(note.E5, note.Eighth)}
+
+	staff := score.NewStaff(score.CommonTime,
+		[][]note.Note{{note.NewRest(note.Whole)}},
+		[][]note.Note{eighth, eighth, eighth, eighth, eighth, eighth, eighth, eighth},
+		[][]note.Note{eighth, eighth, eighth, eighth, eighth, eighth, eighth, eighth, eighth},
+		[][]note.Note{{note.NewNote(note.E5, note.Half.Dotted())}},
+	)
+	require.Len(t, staff, 4)
+	assert.Len(t, staff.Voice(0), 19)
+
+	err := staff.Validate()
+	require.Error(t, err)
+
+	errs, ok := err.(score.StaffError)
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+
+	assert.Equal(t, 2, errs[0].Index)
+	assert.True(t, errs[0].Over())
+	assert.Equal(t, note.NewRational(9, 8), errs[0].Actual)
+	assert.Equal(t, note.NewRational(1, 1), errs[0].Expected)
+
+	assert.Equal(t, 3, errs[1].Index)
+	assert.False(t, errs[1].Over())
+
+	assert.Equal(t, "bar 3 is overfull: 9/8 of a whole note instead of 1/1; "+
+		"bar 4 is underfull: 3/4 of a whole note instead of 1/1", err.Error())
+
+	// Time signature changes
+	staff[3].Time = score.TimeSignature{Beats: 3, Value: 4}
+	staff = append(staff[:2], staff[3])
+	assert.NoError(t, staff.Validate())
+}
+
+func TestVoices(t *testing.T) {
+	half := func(p note.Pitch) []note.Note { return []note.Note{note.NewNote(p, note.Half)} }
+	quarter := func(p note.Pitch) []note.Note { return []note.Note{note.NewNote(p, note.Quarter)} }
+
+	staff := score.Staff{
+		score.NewPolyphonicMeasure(score.CommonTime,
+			score.Voice{quarter(note.E5), quarter(note.D5), quarter(note.C5), quarter(note.D5)},
+			score.Voice{half(note.C4), half(note.G3)},
+		),
+		score.NewMeasure(score.CommonTime, half(note.E5), half(note.E5)),
+	}
+	require.NoError(t, staff.Validate())
+	assert.Equal(t, 2, staff.NumVoices())
+	assert.Equal(t, note.NewRational(2, 1), staff.Duration())
+
+	voices := staff.Voices()
+	require.Len(t, voices, 2)
+	assert.Len(t, voices[0], 6)
+
+	// The missing second voice in bar 2 is filled with a whole rest
+	assert.Equal(t, score.Voice{half(note.C4), half(note.G3), {note.NewRest(note.Whole)}}, voices[1])
+	assert.Equal(t, voices[0].Duration(), voices[1].Duration())
+
+	staff[0].Voices[1] = append(staff[0].Voices[1], quarter(note.C3))
+	err := staff.Validate()
+	require.Error(t, err)
+	assert.Equal(t, "bar 1 voice 2 is overfull: 5/4 of a whole note instead of 1/1", err.Error())
+	assert.Equal(t, note.NewRational(5, 4), staff[0].Duration())
+}
+
+func TestPitchedNotes(t *testing.T) {
+	c := note.NewNote(note.C4, note.Half)
+	rest := note.NewRest(note.Whole)
+
+	assert.Equal(t, []note.Note{c}, score.PitchedNotes([]note.Note{rest, c}))
+	assert.Equal(t, []note.Note{c}, score.PitchedNotes([]note.Note{c}))
+	assert.Equal(t, []note.Note{rest}, score.PitchedNotes([]note.Note{rest, note.NewRest(note.Half)}))
+}
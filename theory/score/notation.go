@@ -0,0 +1,191 @@
+package score
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Clef is the clef of a staff
+type Clef int
+
+const (
+	// Treble is the G clef on the second line
+	Treble Clef = iota
+	// Bass is the F clef on the fourth line
+	Bass
+)
+
+var clefNames = []string{"treble", "bass"}
+
+// String returns the clef name, e.g. "treble"
+func (c Clef) String() string {
+	if c < Treble || c > Bass {
+		return fmt.Sprintf("Clef(%d)", int(c))
+	}
+	return clefNames[c]
+}
+
+// middleC is the key of C4, the lowest note written with the treble clef
+var middleC = note.C4.Key()
+
+// Clef returns the clef that fits the notes of the staff best: bass if
+// their average pitch is below middle C, and treble otherwise
+func (s Staff) Clef() Clef {
+	sum, n := 0, 0
+	for _, m := range s {
+		for _, v := range m.Voices {
+			for _, group := range v {
+				for _, nt := range group {
+					if k := nt.Key(); k >= 0 {
+						sum += k
+						n++
+					}
+				}
+			}
+		}
+	}
+
+	if n > 0 && sum < middleC*n {
+		return Bass
+	}
+	return Treble
+}
+
+// Pulse returns the duration of each beat as it is felt: a dotted beat in
+// compound meters like 6/8 or 12/8, and the beat value otherwise
+func (t TimeSignature) Pulse() note.Rational {
+	if t.Value >= 8 && t.Beats > 3 && t.Beats%3 == 0 {
+		return note.NewRational(3, int64(t.Value))
+	}
+	return note.NewRational(1, int64(t.Value))
+}
+
+// Notate returns the voice of a measure with the notes split into tied notes
+// that can be written with a single note value, see note.Duration.Notation.
+// Notes that do not start on a pulse are also split at the next one, so the
+// beats are visible and the notes can be beamed by beat.
+//
+// The notes of a chord take the duration of the group. Dynamics, hairpins
+// and articulations are kept in the first note of the split ones, and rests
+// are split without ties. A voice with a single rest of the whole measure is
+// returned as it is
+func (t TimeSignature) Notate(v Voice) Voice {
+	if len(v) == 1 && IsRestGroup(v[0]) && groupDuration(v[0]) == t.Duration() {
+		return v
+	}
+
+	var notated Voice
+	var pos note.Rational
+	for _, group := range v {
+		d := groupDuration(group)
+		pieces := t.split(pos, d)
+		for i, piece := range pieces {
+			notated = append(notated, splitGroup(group, piece, i == 0, i == len(pieces)-1))
+		}
+		pos = pos.Add(d)
+	}
+	return notated
+}
+
+// IsRestGroup returns true if all the notes of the group are rests
+func IsRestGroup(group []note.Note) bool {
+	for _, n := range group {
+		if n.Key() >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsLegato returns true if all the pitched notes of the group are legato.
+// Groups of rests are not legato
+func IsLegato(group []note.Note) bool {
+	if IsRestGroup(group) {
+		return false
+	}
+	for _, n := range group {
+		if n.Key() >= 0 && !n.Articulation.Has(note.Legato) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitGroup returns a copy of the group with the duration of one of the
+// pieces it is split into
+func splitGroup(group []note.Note, d note.Duration, first, last bool) []note.Note {
+	piece := make([]note.Note, len(group))
+	for i, n := range group {
+		n.Duration = d
+		if !first {
+			n.Dynamic = note.NoDynamic
+			n.Hairpin = note.NoHairpin
+			n.Articulation &= note.Legato
+		}
+		if !last && n.Key() >= 0 {
+			n.Tie = true
+		}
+		piece[i] = n
+	}
+	return piece
+}
+
+// splitValues are the durations with up to 2 dots, the longest first
+var splitValues = func() []note.Rational {
+	var values []note.Rational
+	for value := note.Double; value >= note.Sixteenth/8; value /= 2 {
+		for dots := 0; dots <= 2; dots++ {
+			values = append(values, value.Dots(dots).Rational())
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Cmp(values[j]) > 0
+	})
+	return values
+}()
+
+// split returns the durations of the tied notes used to write a note of
+// duration d that starts at pos from the start of the measure
+func (t TimeSignature) split(pos, d note.Rational) []note.Duration {
+	pulse := t.Pulse()
+
+	var pieces []note.Duration
+	for d.Cmp(note.Rational{}) > 0 {
+		// number of pulses from the start of the measure
+		pulses := pos.Mul(note.NewRational(pulse.Den(), pulse.Num()))
+		onPulse := pulses.Den() == 1
+		next := note.NewRational(pulses.Num()/pulses.Den()+1, 1).Mul(pulse)
+		fits := pos.Add(d).Cmp(next) <= 0
+
+		n, ok := d.Duration().Notation()
+		if ok && (onPulse || fits || n.Tuplet != 0) {
+			return append(pieces, d.Duration())
+		}
+
+		length := d
+		if !onPulse && !fits {
+			length = next.Sub(pos)
+		}
+		piece, ok := longestValue(length)
+		if !ok {
+			return append(pieces, d.Duration())
+		}
+
+		pieces = append(pieces, piece.Duration())
+		pos, d = pos.Add(piece), d.Sub(piece)
+	}
+	return pieces
+}
+
+// longestValue returns the longest note value, with up to 2 dots, that is
+// not longer than d
+func longestValue(d note.Rational) (note.Rational, bool) {
+	for _, v := range splitValues {
+		if v.Cmp(d) <= 0 {
+			return v, true
+		}
+	}
+	return note.Rational{}, false
+}
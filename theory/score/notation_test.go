@@ -0,0 +1,94 @@
+package score_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClef(t *testing.T) {
+	treble := score.NewStaff(score.CommonTime,
+		[][]note.Note{{note.NewNote(note.E4, note.Half)}, {note.NewNote(note.C4, note.Half)}},
+	)
+	bass := score.NewStaff(score.CommonTime,
+		[][]note.Note{{note.NewNote(note.C3, note.Half)}, {note.NewRest(note.Quarter)}, {note.NewNote(note.D4, note.Quarter)}},
+	)
+
+	assert.Equal(t, score.Treble, treble.Clef())
+	assert.Equal(t, score.Bass, bass.Clef())
+	assert.Equal(t, score.Treble, score.Staff{}.Clef())
+	assert.Equal(t, "bass", score.Bass.String())
+}
+
+func TestPulse(t *testing.T) {
+	for ts, expected := range map[score.TimeSignature]note.Rational{
+		score.CommonTime:      note.NewRational(1, 4),
+		score.CutTime:         note.NewRational(1, 2),
+		{Beats: 3, Value: 8}:  note.NewRational(1, 8),
+		{Beats: 6, Value: 8}:  note.NewRational(3, 8),
+		{Beats: 12, Value: 8}: note.NewRational(3, 8),
+		{Beats: 9, Value: 16}: note.NewRational(3, 16),
+		{Beats: 5, Value: 4}:  note.NewRational(1, 4),
+	} {
+		assert.Equal(t, expected, ts.Pulse(), ts.String())
+	}
+}
+
+func TestNotate(t *testing.T) {
+	voice := score.Voice{
+		{note.NewNote(note.C4, note.Quarter.Dotted())},
+		{note.NewNote(note.D4, note.Quarter).WithDynamic(note.Forte).Articulate(note.Accent | note.Legato)},
+		{note.NewRest(note.Eighth)},
+		{note.NewNote(note.E4, note.Quarter), note.NewNote(note.G4, note.Eighth).Tied()},
+	}
+	assert.Equal(t, score.Voice{
+		{note.NewNote(note.C4, note.Quarter.Dotted())},
+		{note.NewNote(note.D4, note.Eighth).WithDynamic(note.Forte).Articulate(note.Accent | note.Legato).Tied()},
+		{note.NewNote(note.D4, note.Eighth).Articulate(note.Legato)},
+		{note.NewRest(note.Eighth)},
+		{note.NewNote(note.E4, note.Quarter), note.NewNote(note.G4, note.Quarter).Tied()},
+	}, score.CommonTime.Notate(voice))
+
+	voice = score.Voice{
+		{note.NewNote(note.F4, note.Half+note.Eighth).Crescendo()},
+		{note.NewRest(note.Quarter.Dotted())},
+	}
+	assert.Equal(t, score.Voice{
+		{note.NewNote(note.F4, note.Half).Crescendo().Tied()},
+		{note.NewNote(note.F4, note.Eighth)},
+		{note.NewRest(note.Eighth)},
+		{note.NewRest(note.Quarter)},
+	}, score.CommonTime.Notate(voice))
+
+	// Notes that already show the beats are not changed
+	for ts, v := range map[score.TimeSignature]score.Voice{
+		score.CommonTime:     {{note.NewRest(note.Whole)}},
+		{Beats: 3, Value: 4}: {{note.NewRest(note.Half.Dotted())}},
+		{Beats: 6, Value: 8}: {
+			{note.NewNote(note.C4, note.Quarter)}, {note.NewNote(note.D4, note.Eighth)},
+			{note.NewNote(note.E4, note.Eighth)}, {note.NewNote(note.F4, note.Quarter)},
+		},
+		{Beats: 2, Value: 4}: {
+			{note.NewNote(note.C4, note.Quarter.Triplet())}, {note.NewNote(note.D4, note.Quarter.Triplet())},
+			{note.NewNote(note.E4, note.Quarter.Triplet())},
+		},
+	} {
+		assert.Equal(t, v, ts.Notate(v), ts.String())
+	}
+}
+
+func TestIsLegato(t *testing.T) {
+	c := note.NewNote(note.C4, note.Quarter).Articulate(note.Legato)
+	e := note.NewNote(note.E4, note.Quarter)
+	rest := note.NewRest(note.Quarter)
+
+	assert.True(t, score.IsLegato([]note.Note{c}))
+	assert.True(t, score.IsLegato([]note.Note{c, rest}))
+	assert.False(t, score.IsLegato([]note.Note{c, e}))
+	assert.False(t, score.IsLegato([]note.Note{rest}))
+
+	assert.True(t, score.IsRestGroup([]note.Note{rest, rest}))
+	assert.False(t, score.IsRestGroup([]note.Note{rest, c}))
+}
@@ -0,0 +1,95 @@
+package score
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/key"
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Score is a piece of music, made of parts played at the same time
+type Score struct {
+	Title    string
+	Composer string
+	// Arranger is who arranged or transcribed the piece
+	Arranger string
+	// Key is the key signature of all the parts. The zero value is C major
+	Key   key.Key
+	Tempo TempoMap
+	Parts []Part
+}
+
+// Part is the music played by one instrument, e.g. a piano part with a
+// treble and a bass staff
+type Part struct {
+	Name       string
+	Instrument Instrument
+	Staves     []Staff
+}
+
+// Instrument plays a part
+type Instrument struct {
+	Name string
+	// Program is the General MIDI program number, from 0 to 127
+	Program int
+}
+
+// Duration returns the exact duration of the longest staff
+func (s Score) Duration() note.Rational {
+	var longest note.Rational
+	for _, p := range s.Parts {
+		for _, staff := range p.Staves {
+			if d := staff.Duration(); d.Cmp(longest) > 0 {
+				longest = d
+			}
+		}
+	}
+	return longest
+}
+
+// PartError describes the wrong measures of one staff in a score
+type PartError struct {
+	// Part is the index of the part in the score, starting at 0
+	Part int
+	// Name is the name of the part
+	Name string
+	// Staff is the index of the staff in the part, starting at 0
+	Staff int
+	Err   StaffError
+}
+
+// Error returns a description of the error. Staves are numbered from 1
+func (e PartError) Error() string {
+	return fmt.Sprintf("part %q staff %d: %v", e.Name, e.Staff+1, e.Err)
+}
+
+// ScoreError lists the staves of a score with a wrong duration
+type ScoreError []PartError
+
+// Error returns a description of all the staff errors
+func (e ScoreError) Error() string {
+	s := make([]string, len(e))
+	for i, p := range e {
+		s[i] = p.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Validate checks the measures of all the staves, see Staff.Validate. It
+// returns a ScoreError with the wrong staves, or nil
+func (s Score) Validate() error {
+	var errs ScoreError
+	for i, p := range s.Parts {
+		for j, staff := range p.Staves {
+			if err := staff.Validate(); err != nil {
+				errs = append(errs, PartError{Part: i, Name: p.Name, Staff: j, Err: err.(StaffError)})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package score_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreValidate(t *testing.T) {
+	whole := [][]note.Note{{note.NewNote(note.C4, note.Whole)}}
+	half := [][]note.Note{{note.NewNote(note.C4, note.Half)}}
+
+	s := score.Score{
+		Title: "Test",
+		Parts: []score.Part{
+			{Name: "Violin", Staves: []score.Staff{score.NewStaff(score.CommonTime, whole, whole)}},
+			{Name: "Piano", Staves: []score.Staff{
+				score.NewStaff(score.CommonTime, whole, whole),
+				score.NewStaff(score.CommonTime, whole, half),
+			}},
+		},
+	}
+	assert.Equal(t, note.NewRational(2, 1), s.Duration())
+
+	err := s.Validate()
+	require.Error(t, err)
+
+	errs, ok := err.(score.ScoreError)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 1, errs[0].Part)
+	assert.Equal(t, 1, errs[0].Staff)
+	assert.Equal(t, `part "Piano" staff 2: bar 2 is underfull: 1/2 of a whole note instead of 1/1`, err.Error())
+
+	s.Parts = s.Parts[:1]
+	assert.NoError(t, s.Validate())
+}
@@ -0,0 +1,279 @@
+package score
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// Tempo is a tempo marking, the number of beats per minute
+type Tempo struct {
+	// Beat is the note value of each beat, e.g. note.Quarter for ♩ = 120,
+	// or a dotted quarter for 6/8 at ♩. = 60
+	Beat note.Duration
+	// BPM must be positive
+	BPM int
+}
+
+// DefaultTempo is ♩ = 120, used when a score does not set a tempo
+var DefaultTempo = Tempo{Beat: note.Quarter, BPM: 120}
+
+// String returns the tempo marking, e.g. "♩ = 120"
+func (t Tempo) String() string {
+	return fmt.Sprintf("%v = %d", t.Beat, t.BPM)
+}
+
+// wholesPerMinute returns the number of whole notes played in a minute
+func (t Tempo) wholesPerMinute() float64 {
+	return float64(t.BPM) * float64(t.Beat)
+}
+
+// Ramp is how the tempo moves from one change to the next one
+type Ramp int
+
+const (
+	// Immediate keeps the tempo until the next change
+	Immediate Ramp = iota
+	// Linear changes the tempo gradually, at a constant rate
+	Linear
+	// Exponential changes the tempo gradually, by a constant ratio. It sounds
+	// more natural than Linear for long ritardandos
+	Exponential
+)
+
+// TempoChange sets the tempo from a position of the score
+type TempoChange struct {
+	// Position is the start of the change, as a fraction of the whole note
+	// from the beginning of the score
+	Position note.Rational
+	Tempo    Tempo
+	// Ramp is how the tempo moves towards the tempo of the next change. A
+	// ritardando or accelerando is a Linear or Exponential ramp to a slower or
+	// faster tempo
+	Ramp Ramp
+}
+
+// FermataFactor is how many times longer a fermata is held, unless set
+const FermataFactor = 2
+
+// Fermata holds the notes in a span of the score longer than their value
+type Fermata struct {
+	// Position is the start of the held notes, as a fraction of the whole
+	// note from the beginning of the score
+	Position note.Rational
+	// Duration is the length of the held notes
+	Duration note.Rational
+	// Factor is how many times longer the notes are held. FermataFactor is
+	// used if it is 0
+	Factor float64
+}
+
+// factor returns the Factor, or FermataFactor if it is not set
+func (f Fermata) factor() float64 {
+	if f.Factor == 0 {
+		return FermataFactor
+	}
+	return f.Factor
+}
+
+// TempoMap is the tempo of a score over time
+type TempoMap struct {
+	// Changes are the tempo changes, in any order. Before the first change,
+	// or if there are none, DefaultTempo is used
+	Changes  []TempoChange
+	Fermatas []Fermata
+}
+
+// ConstantTempo returns a TempoMap with the same tempo for the whole score
+func ConstantTempo(t Tempo) TempoMap {
+	return TempoMap{Changes: []TempoChange{{Tempo: t}}}
+}
+
+// changes returns the tempo changes sorted by position, starting with
+// DefaultTempo if there is no change at the beginning
+func (m TempoMap) changes() []TempoChange {
+	changes := make([]TempoChange, 0, len(m.Changes)+1)
+	changes = append(changes, m.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Position.Cmp(changes[j].Position) < 0
+	})
+
+	if len(changes) == 0 || changes[0].Position.Cmp(note.Rational{}) > 0 {
+		changes = append([]TempoChange{{Tempo: DefaultTempo}}, changes...)
+	}
+	return changes
+}
+
+// BPM returns the tempo at a position of the score, in beats of the last
+// tempo change per minute. It may be fractional in the middle of a ramp
+func (m TempoMap) BPM(pos note.Rational) float64 {
+	changes := m.changes()
+
+	i := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Position.Cmp(pos) > 0
+	}) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	c := changes[i]
+	var next *TempoChange
+	if i+1 < len(changes) {
+		next = &changes[i+1]
+	}
+	x := pos.Sub(c.Position).Duration()
+	return c.wholesPerMinute(float64(x), next) / float64(c.Tempo.Beat)
+}
+
+// Time returns the time from the beginning of the score to a position,
+// rounded to the nearest nanosecond. The position is a fraction of the
+// whole note
+func (m TempoMap) Time(pos note.Rational) time.Duration {
+	return time.Duration(math.Round(m.minutes(pos) * float64(time.Minute)))
+}
+
+// Samples returns the number of samples at the given sample rate from the
+// beginning of the score to a position, rounded down. See Time
+func (m TempoMap) Samples(pos note.Rational, sampleRate int) int64 {
+	changes := m.changes()
+	total := playedSamples(changes, pos, sampleRate)
+
+	for _, f := range m.Fermatas {
+		start, end := f.Position, f.Position.Add(f.Duration)
+		if start.Cmp(pos) >= 0 {
+			continue
+		}
+		if end.Cmp(pos) > 0 {
+			end = pos
+		}
+		held := playedSamples(changes, end, sampleRate) - playedSamples(changes, start, sampleRate)
+		total += int64(math.Floor((f.factor() - 1) * float64(held)))
+	}
+
+	return total
+}
+
+// playedSamples returns the number of samples to a position, for sorted
+// changes. Each tempo segment is rounded down on its own, so that the
+// samples of a position only depend on the segment it falls in
+func playedSamples(changes []TempoChange, pos note.Rational, sampleRate int) int64 {
+	var total int64
+	for i, c := range changes {
+		if c.Position.Cmp(pos) >= 0 {
+			break
+		}
+
+		end := pos
+		var next *TempoChange
+		if i+1 < len(changes) {
+			next = &changes[i+1]
+			if next.Position.Cmp(pos) < 0 {
+				end = next.Position
+			}
+		}
+
+		length := end.Sub(c.Position)
+		if c.Ramp == Immediate || next == nil || next.Tempo.wholesPerMinute() == c.Tempo.wholesPerMinute() {
+			total += length.ToSamples(c.Tempo.Beat, c.Tempo.BPM, sampleRate)
+			continue
+		}
+
+		// The time of a ramp is a logarithm, which can't be computed exactly.
+		// Its float error is far below a millionth of a sample, so the margin
+		// only keeps positions that fall exactly on a sample, like the end of
+		// the ramp, from being rounded down
+		minutes := c.minutes(float64(length.Duration()), next)
+		total += int64(math.Floor(minutes*60*float64(sampleRate) + 1e-6))
+	}
+	return total
+}
+
+// minutes returns the time to a position in minutes, including fermatas
+func (m TempoMap) minutes(pos note.Rational) float64 {
+	changes := m.changes()
+	total := played(changes, pos)
+
+	for _, f := range m.Fermatas {
+		start, end := f.Position, f.Position.Add(f.Duration)
+		if start.Cmp(pos) >= 0 {
+			continue
+		}
+		if end.Cmp(pos) > 0 {
+			end = pos
+		}
+		total += (f.factor() - 1) * (played(changes, end) - played(changes, start))
+	}
+
+	return total
+}
+
+// played returns the time to a position in minutes, for sorted changes
+func played(changes []TempoChange, pos note.Rational) float64 {
+	var total float64
+	for i, c := range changes {
+		if c.Position.Cmp(pos) >= 0 {
+			break
+		}
+
+		end := pos
+		var next *TempoChange
+		if i+1 < len(changes) {
+			next = &changes[i+1]
+			if next.Position.Cmp(pos) < 0 {
+				end = next.Position
+			}
+		}
+		total += c.minutes(float64(end.Sub(c.Position).Duration()), next)
+	}
+	return total
+}
+
+// wholesPerMinute returns the tempo x whole notes after the change, in whole
+// notes per minute. There are no ramps from or to a tempo of 0
+func (c TempoChange) wholesPerMinute(x float64, next *TempoChange) float64 {
+	w0 := c.Tempo.wholesPerMinute()
+	if c.Ramp == Immediate || next == nil {
+		return w0
+	}
+
+	w1 := next.Tempo.wholesPerMinute()
+	if w0 <= 0 || w1 <= 0 {
+		return w0
+	}
+	length := float64(next.Position.Sub(c.Position).Duration())
+	if c.Ramp == Exponential {
+		return w0 * math.Pow(w1/w0, x/length)
+	}
+	return w0 + (w1-w0)*x/length
+}
+
+// minutes returns the time to play x whole notes after the change, in
+// minutes. Ramps are integrated exactly, as the time of each instant is the
+// inverse of the tempo. Like in Rational.ToTime, a tempo of 0 takes no time
+func (c TempoChange) minutes(x float64, next *TempoChange) float64 {
+	w0 := c.Tempo.wholesPerMinute()
+	if w0 <= 0 {
+		return 0
+	}
+	if c.Ramp == Immediate || next == nil {
+		return x / w0
+	}
+
+	w1 := next.Tempo.wholesPerMinute()
+	if w1 == w0 || w1 <= 0 {
+		return x / w0
+	}
+	length := float64(next.Position.Sub(c.Position).Duration())
+
+	if c.Ramp == Exponential {
+		// w(x) = w0 * e^(kx)
+		k := math.Log(w1/w0) / length
+		return (1 - math.Exp(-k*x)) / (k * w0)
+	}
+
+	// w(x) = w0 + (w1-w0)/length * x
+	return length / (w1 - w0) * math.Log((w0+(w1-w0)*x/length)/w0)
+}
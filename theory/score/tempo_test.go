@@ -0,0 +1,132 @@
+package score_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/score"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	quarter120 = score.Tempo{Beat: note.Quarter, BPM: 120}
+	quarter60  = score.Tempo{Beat: note.Quarter, BPM: 60}
+)
+
+func TestConstantTempo(t *testing.T) {
+	m := score.ConstantTempo(quarter120)
+	assert.Equal(t, 2*time.Second, m.Time(note.NewRational(1, 1)))
+	assert.Equal(t, 120.0, m.BPM(note.NewRational(7, 8)))
+
+	// Same samples as the exact rational computation
+	for _, pos := range []note.Rational{
+		note.NewRational(1, 3), note.NewRational(7, 12), note.NewRational(1001, 3),
+	} {
+		assert.Equal(t, pos.ToSamples(note.Quarter, 120, 44100), m.Samples(pos, 44100), pos.String())
+	}
+
+	// No changes is the default tempo
+	assert.Equal(t, 2*time.Second, score.TempoMap{}.Time(note.NewRational(1, 1)))
+}
+
+func TestDottedBeat(t *testing.T) {
+	// 6/8 at ♩. = 60, each bar has 2 beats
+	m := score.ConstantTempo(score.Tempo{Beat: note.Quarter.Dotted(), BPM: 60})
+	assert.Equal(t, 2*time.Second, m.Time(score.TimeSignature{Beats: 6, Value: 8}.Duration()))
+	assert.Equal(t, 500*time.Millisecond, m.Time(note.NewRational(3, 16)))
+}
+
+func TestTempoChange(t *testing.T) {
+	m := score.TempoMap{Changes: []score.TempoChange{
+		{Position: note.NewRational(1, 1), Tempo: quarter60},
+		{Tempo: quarter120},
+	}}
+	assert.Equal(t, 2*time.Second, m.Time(note.NewRational(1, 1)))
+	assert.Equal(t, 4*time.Second, m.Time(note.NewRational(3, 2)))
+	assert.Equal(t, 6*time.Second, m.Time(note.NewRational(2, 1)))
+	assert.Equal(t, 120.0, m.BPM(note.NewRational(1, 2)))
+	assert.Equal(t, 60.0, m.BPM(note.NewRational(1, 1)))
+
+	// The tempo before the first change is the default one
+	m = score.TempoMap{Changes: []score.TempoChange{
+		{Position: note.NewRational(1, 1), Tempo: quarter60},
+	}}
+	assert.Equal(t, 6*time.Second, m.Time(note.NewRational(2, 1)))
+
+	// Samples are exact on both sides of the change
+	third := note.NewRational(1, 3)
+	assert.Equal(t, int64(88200), m.Samples(note.NewRational(1, 1), 44100))
+	assert.Equal(t, 88200+third.ToSamples(note.Quarter, 60, 44100),
+		m.Samples(note.NewRational(4, 3), 44100))
+}
+
+func TestRamps(t *testing.T) {
+	ritardando := func(r score.Ramp) score.TempoMap {
+		return score.TempoMap{Changes: []score.TempoChange{
+			{Tempo: quarter120, Ramp: r},
+			{Position: note.NewRational(1, 1), Tempo: quarter60},
+		}}
+	}
+
+	linear := ritardando(score.Linear)
+	assert.InDelta(t, 90, linear.BPM(note.NewRational(1, 2)), 1e-9)
+	assert.InDelta(t, 2.7726, linear.Time(note.NewRational(1, 1)).Seconds(), 1e-4)
+	assert.InDelta(t, 6.7726, linear.Time(note.NewRational(2, 1)).Seconds(), 1e-4)
+
+	exponential := ritardando(score.Exponential)
+	assert.InDelta(t, 84.8528, exponential.BPM(note.NewRational(1, 2)), 1e-4)
+	assert.InDelta(t, 2.8854, exponential.Time(note.NewRational(1, 1)).Seconds(), 1e-4)
+
+	// Both are between the two constant tempos
+	half := note.NewRational(1, 2)
+	for _, m := range []score.TempoMap{linear, exponential} {
+		assert.True(t, m.Time(half) > score.ConstantTempo(quarter120).Time(half))
+		assert.True(t, m.Time(half) < score.ConstantTempo(quarter60).Time(half))
+	}
+
+	// An accelerando with no tempo to reach keeps the tempo
+	m := score.TempoMap{Changes: []score.TempoChange{{Tempo: quarter60, Ramp: score.Linear}}}
+	assert.Equal(t, 4*time.Second, m.Time(note.NewRational(1, 1)))
+}
+
+func TestFermata(t *testing.T) {
+	// The second quarter of the bar is held twice as long
+	m := score.ConstantTempo(quarter120)
+	m.Fermatas = []score.Fermata{{Position: note.NewRational(1, 4), Duration: note.NewRational(1, 4)}}
+
+	assert.Equal(t, 500*time.Millisecond, m.Time(note.NewRational(1, 4)))
+	assert.Equal(t, time.Second, m.Time(note.NewRational(3, 8)))
+	assert.Equal(t, 1500*time.Millisecond, m.Time(note.NewRational(1, 2)))
+	assert.Equal(t, 2500*time.Millisecond, m.Time(note.NewRational(1, 1)))
+
+	assert.Equal(t, int64(66150), m.Samples(note.NewRational(1, 2), 44100))
+
+	m.Fermatas[0].Factor = 3
+	assert.Equal(t, 3*time.Second, m.Time(note.NewRational(1, 1)))
+}
+
+func TestZeroTempo(t *testing.T) {
+	for _, m := range []score.TempoMap{
+		score.ConstantTempo(score.Tempo{Beat: note.Quarter}),
+		score.ConstantTempo(score.Tempo{BPM: 120}),
+	} {
+		assert.Equal(t, time.Duration(0), m.Time(note.NewRational(1, 1)))
+		assert.Equal(t, int64(0), m.Samples(note.NewRational(1, 1), 44100))
+	}
+
+	// A ritardando to a tempo of 0 keeps the first tempo
+	m := score.TempoMap{Changes: []score.TempoChange{
+		{Tempo: quarter120, Ramp: score.Linear},
+		{Position: note.NewRational(1, 1), Tempo: score.Tempo{Beat: note.Quarter}},
+	}}
+	assert.Equal(t, 2*time.Second, m.Time(note.NewRational(1, 1)))
+	assert.Equal(t, int64(88200), m.Samples(note.NewRational(1, 1), 44100))
+	assert.Equal(t, 120.0, m.BPM(note.NewRational(1, 2)))
+}
+
+func TestTempoString(t *testing.T) {
+	assert.Equal(t, note.Quarter.String()+" = 120", score.DefaultTempo.String())
+	assert.Equal(t, note.Quarter.String()+". = 60",
+		score.Tempo{Beat: note.Quarter.Dotted(), BPM: 60}.String())
+}
@@ -0,0 +1,69 @@
+package score
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// TimeSignature is the number of beats in a measure, and the note value of
+// each beat as a fraction of the whole note, e.g. 3/4 or 6/8
+type TimeSignature struct {
+	Beats int
+	Value int
+}
+
+var (
+	// CommonTime is 4/4, written as C
+	CommonTime = TimeSignature{Beats: 4, Value: 4}
+	// CutTime is 2/2, written as a crossed C
+	CutTime = TimeSignature{Beats: 2, Value: 2}
+)
+
+// String returns the time signature as a fraction, e.g. "3/4"
+func (t TimeSignature) String() string {
+	return fmt.Sprintf("%d/%d", t.Beats, t.Value)
+}
+
+// Beat returns the note value of each beat, e.g. note.Quarter for 3/4
+func (t TimeSignature) Beat() note.Duration {
+	return note.Whole / note.Duration(t.Value)
+}
+
+// Duration returns the exact duration of a full measure, e.g. 3/4 of a whole
+// note for 3/4
+func (t TimeSignature) Duration() note.Rational {
+	return note.NewRational(int64(t.Beats), int64(t.Value))
+}
+
+// ParseTimeSignature reads a time signature like "3/4" or "6/8". It also
+// accepts "C" for common time and "C|" for cut time. The note value must be
+// a power of two
+func ParseTimeSignature(s string) (TimeSignature, error) {
+	name := strings.TrimSpace(s)
+	switch name {
+	case "C":
+		return CommonTime, nil
+	case "C|":
+		return CutTime, nil
+	}
+
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 {
+		return TimeSignature{}, fmt.Errorf("invalid time signature %q", s)
+	}
+
+	beats, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || beats <= 0 {
+		return TimeSignature{}, fmt.Errorf("invalid time signature %q: wrong number of beats", s)
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || value <= 0 || value&(value-1) != 0 {
+		return TimeSignature{}, fmt.Errorf("invalid time signature %q: the note value must be a power of two", s)
+	}
+
+	return TimeSignature{Beats: beats, Value: value}, nil
+}
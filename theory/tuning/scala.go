@@ -0,0 +1,356 @@
+package tuning
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/carlosms/music-playground/theory/internal/intmath"
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// ScalaScale is a scale read from a Scala .scl file. See
+// http://www.huygens-fokker.org/scala/scl_format.html
+type ScalaScale struct {
+	Description string
+	// Cents are the pitches of the scale above the 1/1 unison, which is not
+	// included. The last one is the period, usually the octave. There is at
+	// least one
+	Cents []float64
+}
+
+// Period returns the interval in cents where the scale repeats
+func (s ScalaScale) Period() float64 {
+	return s.Cents[len(s.Cents)-1]
+}
+
+// degree returns the cents above the unison for a scale degree, that can be
+// negative or greater than the number of notes
+func (s ScalaScale) degree(d int) float64 {
+	size := len(s.Cents)
+	periods := intmath.FloorDiv(d, size)
+	d -= periods * size
+
+	var c float64
+	if d > 0 {
+		c = s.Cents[d-1]
+	}
+
+	return c + float64(periods)*s.Period()
+}
+
+// KeyboardMapping is the assignment of MIDI keys to scale degrees read from
+// a Scala .kbm file. See http://www.huygens-fokker.org/scala/help.htm#mappings
+type KeyboardMapping struct {
+	// First and Last are the range of MIDI keys to retune
+	First, Last int
+	// Middle is the MIDI key mapped to the first element of Mapping
+	Middle int
+	// Reference is the MIDI key tuned to Frequency hertz
+	Reference int
+	Frequency float64
+	// OctaveDegree is the scale degree between consecutive repetitions of
+	// the mapping
+	OctaveDegree int
+	// Mapping are the scale degrees for each key starting at Middle, or -1
+	// for keys that are not mapped. An empty mapping assigns consecutive
+	// degrees to consecutive keys
+	Mapping []int
+}
+
+// DefaultMapping returns the linear mapping used by Scala when there is no
+// .kbm file, with the unison on C4 tuned to its standard frequency
+func DefaultMapping() KeyboardMapping {
+	return KeyboardMapping{
+		First:     0,
+		Last:      127,
+		Middle:    note.C4.Key(),
+		Reference: note.C4.Key(),
+		Frequency: note.C4.Frequency(),
+	}
+}
+
+// degree returns the scale degree for a key, and false if it is not mapped.
+// The degree is not limited to the scale size
+func (m KeyboardMapping) degree(key int, scaleSize int) (int, bool) {
+	distance := key - m.Middle
+	if len(m.Mapping) == 0 {
+		return distance, true
+	}
+
+	repetitions := intmath.FloorDiv(distance, len(m.Mapping))
+	d := m.Mapping[distance-repetitions*len(m.Mapping)]
+	if d < 0 {
+		return 0, false
+	}
+
+	octaveDegree := m.OctaveDegree
+	if octaveDegree == 0 {
+		octaveDegree = scaleSize
+	}
+
+	return d + repetitions*octaveDegree, true
+}
+
+// Scala is a tuning made of a scale and a keyboard mapping
+type Scala struct {
+	Scale   ScalaScale
+	Mapping KeyboardMapping
+}
+
+// NewScala returns a Scala tuning, or an error if the mapping refers to
+// degrees outside of the scale, or its reference key is not mapped
+func NewScala(s ScalaScale, m KeyboardMapping) (Scala, error) {
+	if len(s.Cents) == 0 {
+		return Scala{}, fmt.Errorf("invalid scale: it has no pitches")
+	}
+
+	for _, d := range m.Mapping {
+		if d > len(s.Cents) {
+			return Scala{}, fmt.Errorf("invalid keyboard mapping: degree %v is outside of the %v notes scale", d, len(s.Cents))
+		}
+	}
+
+	if _, ok := m.degree(m.Reference, len(s.Cents)); !ok {
+		return Scala{}, fmt.Errorf("invalid keyboard mapping: reference key %v is not mapped", m.Reference)
+	}
+
+	return Scala{Scale: s, Mapping: m}, nil
+}
+
+// OpenScala reads a Scala tuning from a .scl file and a .kbm file. If kbm is
+// empty the DefaultMapping is used
+func OpenScala(scl, kbm string) (Scala, error) {
+	f, err := os.Open(scl)
+	if err != nil {
+		return Scala{}, err
+	}
+	defer f.Close()
+
+	s, err := ParseScalaScale(f)
+	if err != nil {
+		return Scala{}, fmt.Errorf("%v: %v", scl, err)
+	}
+
+	m := DefaultMapping()
+	if kbm != "" {
+		f, err := os.Open(kbm)
+		if err != nil {
+			return Scala{}, err
+		}
+		defer f.Close()
+
+		m, err = ParseKeyboardMapping(f)
+		if err != nil {
+			return Scala{}, fmt.Errorf("%v: %v", kbm, err)
+		}
+	}
+
+	return NewScala(s, m)
+}
+
+// Frequency returns the hertz value for the pitch. Keys outside of the
+// mapping range, or not mapped to a degree, return 0, as do all the keys of
+// a Scala without pitches, which NewScala does not return
+func (s Scala) Frequency(p note.Pitch) float64 {
+	key := p.Key()
+	if len(s.Scale.Cents) == 0 || key < s.Mapping.First || key > s.Mapping.Last {
+		return 0
+	}
+
+	d, ok := s.Mapping.degree(key, len(s.Scale.Cents))
+	if !ok {
+		return 0
+	}
+
+	ref, _ := s.Mapping.degree(s.Mapping.Reference, len(s.Scale.Cents))
+	cents := s.Scale.degree(d) - s.Scale.degree(ref)
+
+	return s.Mapping.Frequency * CentsToRatio(cents) * deviation(p)
+}
+
+// ParseScalaScale reads a .scl file. Lines starting with "!" are comments.
+// The first line is the description, followed by the number of pitches and
+// the pitches, one per line. Pitches with a period are cents, and the rest
+// are ratios like "3/2" or "2". Scales need at least one pitch, the period
+func ParseScalaScale(r io.Reader) (ScalaScale, error) {
+	var s ScalaScale
+
+	lines, err := scalaLines(r)
+	if err != nil {
+		return s, err
+	}
+
+	if len(lines) < 2 {
+		return s, fmt.Errorf("invalid scale: missing description or number of notes")
+	}
+
+	s.Description = strings.TrimSpace(lines[0])
+
+	n, err := strconv.Atoi(firstField(lines[1]))
+	if err != nil || n <= 0 {
+		return s, fmt.Errorf("invalid scale: wrong number of notes %q", strings.TrimSpace(lines[1]))
+	}
+
+	// Trailing empty lines are ignored
+	pitches := lines[2:]
+	for len(pitches) > n && strings.TrimSpace(pitches[len(pitches)-1]) == "" {
+		pitches = pitches[:len(pitches)-1]
+	}
+
+	if len(pitches) != n {
+		return s, fmt.Errorf("invalid scale: expected %v notes, found %v", n, len(pitches))
+	}
+
+	for i, line := range pitches {
+		c, err := parseScalaPitch(firstField(line))
+		if err != nil {
+			return s, fmt.Errorf("invalid scale: note %v: %v", i+1, err)
+		}
+		s.Cents = append(s.Cents, c)
+	}
+
+	return s, nil
+}
+
+// parseScalaPitch returns the cents for a pitch written in cents like
+// "701.955", or as a ratio like "3/2" or "2"
+func parseScalaPitch(s string) (float64, error) {
+	if strings.Contains(s, ".") {
+		c, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("wrong cents value %q", s)
+		}
+		return c, nil
+	}
+
+	parts := strings.Split(s, "/")
+	if len(parts) > 2 {
+		return 0, fmt.Errorf("wrong ratio %q", s)
+	}
+
+	num, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || num == 0 {
+		return 0, fmt.Errorf("wrong ratio %q", s)
+	}
+
+	den := uint64(1)
+	if len(parts) == 2 {
+		den, err = strconv.ParseUint(parts[1], 10, 64)
+		if err != nil || den == 0 {
+			return 0, fmt.Errorf("wrong ratio %q", s)
+		}
+	}
+
+	return RatioToCents(float64(num) / float64(den)), nil
+}
+
+// ParseKeyboardMapping reads a .kbm file. Lines starting with "!" are
+// comments. It contains, one per line: the mapping size, the first and last
+// MIDI keys to retune, the middle key, the reference key, its frequency, the
+// octave degree and the mapping, with "x" for keys that are not mapped
+func ParseKeyboardMapping(r io.Reader) (KeyboardMapping, error) {
+	var m KeyboardMapping
+
+	lines, err := scalaLines(r)
+	if err != nil {
+		return m, err
+	}
+
+	const header = 7
+	if len(lines) < header {
+		return m, fmt.Errorf("invalid keyboard mapping: expected %v header lines, found %v", header, len(lines))
+	}
+
+	names := [header]string{
+		"map size", "first key", "last key", "middle key", "reference key",
+		"reference frequency", "octave degree"}
+	var values [header]int
+	for i := range values {
+		if i == 5 {
+			continue
+		}
+
+		v, err := strconv.Atoi(firstField(lines[i]))
+		if err != nil || v < 0 {
+			return m, fmt.Errorf("invalid keyboard mapping: wrong %v %q", names[i], strings.TrimSpace(lines[i]))
+		}
+		values[i] = v
+	}
+
+	freq, err := strconv.ParseFloat(firstField(lines[5]), 64)
+	if err != nil || freq <= 0 || math.IsInf(freq, 0) {
+		return m, fmt.Errorf("invalid keyboard mapping: wrong %v %q", names[5], strings.TrimSpace(lines[5]))
+	}
+
+	size := values[0]
+	m = KeyboardMapping{
+		First:        values[1],
+		Last:         values[2],
+		Middle:       values[3],
+		Reference:    values[4],
+		Frequency:    freq,
+		OctaveDegree: values[6],
+	}
+
+	if m.First > m.Last {
+		return m, fmt.Errorf("invalid keyboard mapping: first key %v is higher than last key %v", m.First, m.Last)
+	}
+
+	// Trailing keys can be omitted, they are not mapped
+	entries := lines[header:]
+	for len(entries) > 0 && strings.TrimSpace(entries[len(entries)-1]) == "" {
+		entries = entries[:len(entries)-1]
+	}
+	if len(entries) > size {
+		return m, fmt.Errorf("invalid keyboard mapping: expected %v keys, found %v", size, len(entries))
+	}
+
+	for i := 0; i < size; i++ {
+		if i >= len(entries) || strings.EqualFold(firstField(entries[i]), "x") {
+			m.Mapping = append(m.Mapping, -1)
+			continue
+		}
+
+		d, err := strconv.Atoi(firstField(entries[i]))
+		if err != nil || d < 0 {
+			return m, fmt.Errorf("invalid keyboard mapping: key %v: wrong degree %q", i, strings.TrimSpace(entries[i]))
+		}
+		m.Mapping = append(m.Mapping, d)
+	}
+
+	return m, nil
+}
+
+// scalaLines returns the lines that are not comments
+func scalaLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// firstField returns the first word of a line, the rest is ignored
+func firstField(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,294 @@
+package tuning_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/tuning"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// werck3 is werck3.scl from the Scala scale archive
+const werck3 = `! werck3.scl
+!
+Andreas Werckmeister's temperament III (the most famous one, 1681)
+ 12
+!
+ 90.22500
+ 192.18000
+ 294.13500
+ 390.22500
+ 498.04500
+ 588.27000
+ 696.09000
+ 792.18000
+ 888.27000
+ 996.09000
+ 1092.18000
+ 2/1
+`
+
+// bohlenPierce is bohlen-p.scl from the Scala scale archive, a just tuning
+// that repeats at the tritave 3/1
+const bohlenPierce = `! bohlen-p.scl
+!
+Bohlen-Pierce, 3:5:7 based, just version
+ 13
+!
+ 27/25
+ 25/21
+ 9/7
+ 7/5
+ 75/49
+ 5/3
+ 9/5
+ 49/25
+ 15/7
+ 7/3
+ 63/25
+ 25/9
+ 3/1
+`
+
+// pelog is a 7 notes scale with comments after the values
+const pelog = `! pelog.scl
+!
+Pelog, approximate values
+ 7
+!
+ 120.0   ! bem
+ 270.0   ! gulu
+ 540.0   ! dada
+ 670.0   ! pelog
+ 785.0   ! lima
+ 950.0   ! nem
+ 2/1
+`
+
+// a440 is the standard 12 keys mapping with A4 at 440 Hz
+const a440 = `! a440.kbm
+! Size of map
+12
+! First and last MIDI keys
+0
+127
+! Middle key
+60
+! Reference key and frequency
+69
+440.0
+! Octave degree
+12
+! Mapping
+0
+1
+2
+3
+4
+5
+6
+7
+8
+9
+10
+11
+`
+
+// whiteKeys maps a 7 notes scale to the white keys, starting on C4
+const whiteKeys = `! white.kbm
+12
+0
+127
+60
+60
+261.6255653
+7
+0
+x
+1
+x
+2
+3
+x
+4
+x
+5
+x
+6
+`
+
+func parseScale(t *testing.T, scl string) tuning.ScalaScale {
+	s, err := tuning.ParseScalaScale(strings.NewReader(scl))
+	require.NoError(t, err)
+	return s
+}
+
+func parseMapping(t *testing.T, kbm string) tuning.KeyboardMapping {
+	m, err := tuning.ParseKeyboardMapping(strings.NewReader(kbm))
+	require.NoError(t, err)
+	return m
+}
+
+func TestParseScalaScale(t *testing.T) {
+	s := parseScale(t, werck3)
+	assert.Equal(t, "Andreas Werckmeister's temperament III (the most famous one, 1681)", s.Description)
+	require.Len(t, s.Cents, 12)
+	assert.Equal(t, 90.225, s.Cents[0])
+	assert.InDelta(t, 1200, s.Period(), delta)
+
+	s = parseScale(t, bohlenPierce)
+	require.Len(t, s.Cents, 13)
+	assert.InDelta(t, 133.2376, s.Cents[0], delta)
+	assert.InDelta(t, 1901.9550, s.Period(), delta)
+
+	s = parseScale(t, pelog)
+	assert.Equal(t, []float64{120, 270, 540, 670, 785, 950, 1200}, roundCents(s.Cents))
+}
+
+// roundCents rounds the cents to 4 decimals
+func roundCents(c []float64) []float64 {
+	r := make([]float64, len(c))
+	for i, v := range c {
+		r[i] = float64(int(v*10000+0.5)) / 10000
+	}
+	return r
+}
+
+func TestParseScalaScaleErrors(t *testing.T) {
+	for name, scl := range map[string]string{
+		"empty":          "",
+		"no count":       "description\n",
+		"wrong count":    "description\n twelve\n",
+		"no notes":       "description\n 0\n",
+		"missing notes":  "description\n 3\n 100.0\n 2/1\n",
+		"too many notes": "description\n 1\n 100.0\n 2/1\n",
+		"wrong cents":    "description\n 1\n 100.0.0\n",
+		"wrong ratio":    "description\n 1\n 3/2/1\n",
+		"zero ratio":     "description\n 1\n 0/1\n",
+		"zero den":       "description\n 1\n 3/0\n",
+		"negative ratio": "description\n 1\n -3/2\n",
+	} {
+		_, err := tuning.ParseScalaScale(strings.NewReader(scl))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseKeyboardMapping(t *testing.T) {
+	m := parseMapping(t, a440)
+	assert.Equal(t, 0, m.First)
+	assert.Equal(t, 127, m.Last)
+	assert.Equal(t, 60, m.Middle)
+	assert.Equal(t, 69, m.Reference)
+	assert.Equal(t, 440.0, m.Frequency)
+	assert.Equal(t, 12, m.OctaveDegree)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, m.Mapping)
+
+	m = parseMapping(t, whiteKeys)
+	assert.Equal(t, []int{0, -1, 1, -1, 2, 3, -1, 4, -1, 5, -1, 6}, m.Mapping)
+
+	// Trailing keys can be omitted
+	m = parseMapping(t, "3\n0\n127\n60\n60\n261.6\n3\n0\n1\n")
+	assert.Equal(t, []int{0, 1, -1}, m.Mapping)
+
+	for name, kbm := range map[string]string{
+		"empty":           "",
+		"short header":    "12\n0\n127\n60\n69\n",
+		"wrong size":      "a\n0\n127\n60\n69\n440.0\n12\n",
+		"wrong frequency": "0\n0\n127\n60\n69\n0\n12\n",
+		"wrong range":     "0\n127\n0\n60\n69\n440.0\n12\n",
+		"too many keys":   "1\n0\n127\n60\n69\n440.0\n12\n0\n1\n",
+		"wrong degree":    "2\n0\n127\n60\n69\n440.0\n12\n0\ny\n",
+	} {
+		_, err := tuning.ParseKeyboardMapping(strings.NewReader(kbm))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestScala(t *testing.T) {
+	s, err := tuning.NewScala(parseScale(t, werck3), parseMapping(t, a440))
+	require.NoError(t, err)
+
+	assert.InDelta(t, 440, s.Frequency(note.A4), delta)
+	assert.InDelta(t, 880, s.Frequency(note.A5), delta)
+	assert.InDelta(t, 440/tuning.CentsToRatio(888.27), s.Frequency(note.C4), delta)
+	assert.InDelta(t, 440*tuning.CentsToRatio(390.225-888.27), s.Frequency(note.E4), delta)
+
+	// Same result as the built in Werckmeister temperament, with A4 at 440
+	w := tuning.Werckmeister(note.C4)
+	w.Reference = 440 * 440 / w.Frequency(note.A4)
+	for _, p := range []note.Pitch{note.C2, note.Fsharp3, note.Dsharp5, note.B7} {
+		assert.InDelta(t, w.Frequency(p), s.Frequency(p), delta, p.String())
+	}
+
+	// The default mapping with 12 tone equal temperament matches the
+	// standard tuning
+	edo12 := tuning.ScalaScale{Cents: []float64{
+		100, 200, 300, 400, 500, 600, 700, 800, 900, 1000, 1100, 1200}}
+	s, err = tuning.NewScala(edo12, tuning.DefaultMapping())
+	require.NoError(t, err)
+	for _, p := range []note.Pitch{note.C_1, note.A4, note.Gsharp6, note.G9} {
+		assert.InDelta(t, p.Frequency(), s.Frequency(p), delta, p.String())
+	}
+}
+
+func TestScalaNonOctave(t *testing.T) {
+	s, err := tuning.NewScala(parseScale(t, bohlenPierce), tuning.DefaultMapping())
+	require.NoError(t, err)
+
+	c4 := note.C4.Frequency()
+	assert.InDelta(t, c4, s.Frequency(note.C4), delta)
+	assert.InDelta(t, c4*27/25, s.Frequency(note.Csharp4), delta)
+	assert.InDelta(t, c4*3, s.Frequency(note.C4.Add(13)), delta)
+	assert.InDelta(t, c4/3, s.Frequency(note.C4.Subtract(13)), delta)
+	assert.InDelta(t, c4*25/9/3, s.Frequency(note.B3), delta)
+}
+
+func TestScalaUnmappedKeys(t *testing.T) {
+	s, err := tuning.NewScala(parseScale(t, pelog), parseMapping(t, whiteKeys))
+	require.NoError(t, err)
+
+	c4 := note.C4.Frequency()
+	assert.InDelta(t, c4, s.Frequency(note.C4), delta)
+	assert.InDelta(t, c4*tuning.CentsToRatio(120), s.Frequency(note.D4), delta)
+	assert.InDelta(t, c4*tuning.CentsToRatio(950-1200), s.Frequency(note.B3), delta)
+	assert.InDelta(t, c4*2, s.Frequency(note.C5), delta)
+	assert.Equal(t, 0.0, s.Frequency(note.Csharp4))
+
+	m := parseMapping(t, whiteKeys)
+	m.Reference = note.Csharp4.Key()
+	_, err = tuning.NewScala(parseScale(t, pelog), m)
+	assert.Error(t, err)
+
+	m = parseMapping(t, a440)
+	_, err = tuning.NewScala(parseScale(t, pelog), m)
+	assert.Error(t, err)
+
+	// Scales without pitches are rejected, and have no frequencies
+	_, err = tuning.NewScala(tuning.ScalaScale{}, tuning.DefaultMapping())
+	assert.Error(t, err)
+	empty := tuning.Scala{Mapping: tuning.DefaultMapping()}
+	assert.Equal(t, 0.0, empty.Frequency(note.A4))
+}
+
+func TestOpenScala(t *testing.T) {
+	dir := t.TempDir()
+	scl := filepath.Join(dir, "werck3.scl")
+	kbm := filepath.Join(dir, "a440.kbm")
+	require.NoError(t, os.WriteFile(scl, []byte(werck3), 0644))
+	require.NoError(t, os.WriteFile(kbm, []byte(a440), 0644))
+
+	s, err := tuning.OpenScala(scl, kbm)
+	require.NoError(t, err)
+	assert.InDelta(t, 440, s.Frequency(note.A4), delta)
+
+	s, err = tuning.OpenScala(scl, "")
+	require.NoError(t, err)
+	assert.InDelta(t, note.C4.Frequency(), s.Frequency(note.C4), delta)
+
+	_, err = tuning.OpenScala(filepath.Join(dir, "missing.scl"), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package tuning
+
+import (
+	"math"
+
+	"github.com/carlosms/music-playground/theory/internal/intmath"
+	"github.com/carlosms/music-playground/theory/note"
+)
+
+// StandardPitch is the standard frequency for A4, in hertz
+const StandardPitch = 440
+
+// referenceKey is the MIDI key number of A4, the reference pitch
+const referenceKey = 69
+
+// keysPerOctave is the number of MIDI keys in an octave
+const keysPerOctave = int(note.Octave)
+
+var (
+	// Standard is 12 tone equal temperament with A4 at 440 Hz, the tuning
+	// used by Pitch.Frequency
+	Standard = Equal(StandardPitch)
+	// Baroque is 12 tone equal temperament with A4 at 415 Hz
+	Baroque = Equal(415)
+	// Verdi is 12 tone equal temperament with A4 at 432 Hz
+	Verdi = Equal(432)
+)
+
+// EDO is an equal division of the octave in a number of steps. Each MIDI key
+// is one step, and A4 is tuned to the Reference frequency
+type EDO struct {
+	Divisions int
+	Reference float64
+}
+
+// Equal returns 12 tone equal temperament with A4 tuned to reference hertz
+func Equal(reference float64) EDO {
+	return EDO{Divisions: keysPerOctave, Reference: reference}
+}
+
+// Frequency returns the hertz value for the pitch
+func (e EDO) Frequency(p note.Pitch) float64 {
+	steps := float64(p.Key() - referenceKey)
+	return e.Reference * math.Pow(2, steps/float64(e.Divisions)) * deviation(p)
+}
+
+// Ratios is a tuning where each pitch class has a frequency ratio to a tonic.
+// The tonic is tuned as in 12 tone equal temperament with A4 at Reference
+// hertz, and the rest of pitches follow the ratios
+type Ratios struct {
+	Tonic note.Pitch
+	// Ratios are the frequency ratios for 0 to 11 semitones above the tonic.
+	// The first one is 1
+	Ratios    [12]float64
+	Reference float64
+}
+
+// Frequency returns the hertz value for the pitch
+func (r Ratios) Frequency(p note.Pitch) float64 {
+	tonic := Equal(r.Reference).Frequency(r.Tonic)
+
+	distance := p.Key() - r.Tonic.Key()
+	octaves := intmath.FloorDiv(distance, keysPerOctave)
+	class := distance - octaves*keysPerOctave
+
+	return tonic * r.Ratios[class] * math.Pow(2, float64(octaves)) * deviation(p)
+}
+
+// Just returns 5-limit just intonation relative to the tonic
+func Just(tonic note.Pitch) Ratios {
+	return Ratios{
+		Tonic: tonic,
+		Ratios: [12]float64{
+			1, 16.0 / 15, 9.0 / 8, 6.0 / 5, 5.0 / 4, 4.0 / 3,
+			45.0 / 32, 3.0 / 2, 8.0 / 5, 5.0 / 3, 9.0 / 5, 15.0 / 8},
+		Reference: StandardPitch,
+	}
+}
+
+// Pythagorean returns the tuning made of pure 3:2 fifths, from 5 fifths below
+// the tonic to 6 fifths above, e.g. Db to F# for C
+func Pythagorean(tonic note.Pitch) Ratios {
+	return fifths(tonic, 3.0/2, -5)
+}
+
+// Meantone returns quarter-comma meantone, with pure major thirds and fifths
+// narrowed by a quarter of a syntonic comma, from 3 fifths below the tonic to
+// 8 fifths above, e.g. Eb to G# for C
+func Meantone(tonic note.Pitch) Ratios {
+	return fifths(tonic, math.Pow(5, 0.25), -3)
+}
+
+// fifths returns a tuning made of a chain of 12 fifths with the given ratio,
+// starting low fifths above the tonic
+func fifths(tonic note.Pitch, fifth float64, low int) Ratios {
+	r := Ratios{Tonic: tonic, Reference: StandardPitch}
+
+	for i := low; i < low+keysPerOctave; i++ {
+		ratio := math.Pow(fifth, float64(i))
+		for ratio >= 2 {
+			ratio /= 2
+		}
+		for ratio < 1 {
+			ratio *= 2
+		}
+
+		class := (i*7%keysPerOctave + keysPerOctave) % keysPerOctave
+		r.Ratios[class] = ratio
+	}
+
+	return r
+}
+
+// Werckmeister returns the Werckmeister III well temperament relative to the
+// tonic, originally defined for C
+func Werckmeister(tonic note.Pitch) Ratios {
+	return cents(tonic, [12]float64{
+		0, 90.225, 192.18, 294.135, 390.225, 498.045,
+		588.27, 696.09, 792.18, 888.27, 996.09, 1092.18})
+}
+
+// Kirnberger returns the Kirnberger III well temperament relative to the
+// tonic, originally defined for C
+func Kirnberger(tonic note.Pitch) Ratios {
+	return cents(tonic, [12]float64{
+		0, 90.225, 193.157, 294.135, 386.314, 498.045,
+		590.224, 696.578, 792.18, 889.735, 996.09, 1088.269})
+}
+
+// cents returns a tuning with the given cents above the tonic for each
+// pitch class
+func cents(tonic note.Pitch, c [12]float64) Ratios {
+	r := Ratios{Tonic: tonic, Reference: StandardPitch}
+	for i, v := range c {
+		r.Ratios[i] = CentsToRatio(v)
+	}
+	return r
+}
+
+// deviation returns the frequency ratio between a note.MicroPitch and its
+// nearest key, or 1 for the rest of pitches. Tunings retune the nearest key
+// and keep the cents deviation
+func deviation(p note.Pitch) float64 {
+	if m, ok := p.(note.MicroPitch); ok {
+		return CentsToRatio(m.Cents())
+	}
+	return 1
+}
+
+// CentsToRatio returns the frequency ratio for an interval in cents, 1200
+// cents per octave
+func CentsToRatio(cents float64) float64 {
+	return math.Pow(2, cents/1200)
+}
+
+// RatioToCents returns the interval in cents for a frequency ratio
+func RatioToCents(ratio float64) float64 {
+	return 1200 * math.Log2(ratio)
+}
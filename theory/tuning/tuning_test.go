@@ -0,0 +1,110 @@
+package tuning_test
+
+import (
+	"testing"
+
+	"github.com/carlosms/music-playground/theory/note"
+	"github.com/carlosms/music-playground/theory/tuning"
+	"github.com/stretchr/testify/assert"
+)
+
+const delta = 0.0001
+
+func TestEqual(t *testing.T) {
+	for _, p := range []note.Pitch{note.C_1, note.C4, note.A4, note.Fsharp7, note.G9} {
+		assert.InDelta(t, p.Frequency(), tuning.Standard.Frequency(p), delta, p.String())
+	}
+
+	assert.InDelta(t, 415, tuning.Baroque.Frequency(note.A4), delta)
+	assert.InDelta(t, 207.5, tuning.Baroque.Frequency(note.A3), delta)
+	assert.InDelta(t, 432, tuning.Verdi.Frequency(note.A4), delta)
+	assert.InDelta(t, 256.8687, tuning.Verdi.Frequency(note.C4), delta)
+
+	// Spelled pitches are tuned by their key number
+	assert.InDelta(t, 466.1638, tuning.Standard.Frequency(note.NewSpelledPitch(note.B, note.Flat, 4)), delta)
+}
+
+func TestEDO(t *testing.T) {
+	edo24 := tuning.EDO{Divisions: 24, Reference: 440}
+	assert.InDelta(t, 440, edo24.Frequency(note.A4), delta)
+	assert.InDelta(t, 452.8929, edo24.Frequency(note.Asharp4), delta)
+	assert.InDelta(t, 880, edo24.Frequency(note.A4.Add(24)), delta)
+
+	edo19 := tuning.EDO{Divisions: 19, Reference: 440}
+	assert.InDelta(t, 220, edo19.Frequency(note.A4.Subtract(19)), delta)
+}
+
+func TestJust(t *testing.T) {
+	c := tuning.Just(note.C4)
+	c4 := note.C4.Frequency()
+
+	assert.InDelta(t, c4, c.Frequency(note.C4), delta)
+	assert.InDelta(t, c4*5/4, c.Frequency(note.E4), delta)
+	assert.InDelta(t, c4*3/2, c.Frequency(note.G4), delta)
+	assert.InDelta(t, c4*15/8/2, c.Frequency(note.B3), delta)
+	assert.InDelta(t, c4*5/3*4, c.Frequency(note.A6), delta)
+
+	// The octave of the tonic is not relevant
+	assert.InDelta(t, c.Frequency(note.E4), tuning.Just(note.C1).Frequency(note.E4), delta)
+
+	a := tuning.Just(note.A4)
+	a.Reference = 415
+	assert.InDelta(t, 415, a.Frequency(note.A4), delta)
+	assert.InDelta(t, 415*6/5, a.Frequency(note.C5), delta)
+}
+
+func TestPythagorean(t *testing.T) {
+	d := tuning.Pythagorean(note.D4)
+	d4 := note.D4.Frequency()
+
+	assert.InDelta(t, d4*3/2, d.Frequency(note.A4), delta)
+	assert.InDelta(t, d4*9/8, d.Frequency(note.E4), delta)
+	assert.InDelta(t, d4*81/64, d.Frequency(note.Fsharp4), delta)
+	assert.InDelta(t, d4*256/243, d.Frequency(note.Dsharp4), delta)
+	assert.InDelta(t, d4*729/512, d.Frequency(note.Gsharp4), delta)
+}
+
+func TestMeantone(t *testing.T) {
+	c := tuning.Meantone(note.C4)
+	c4 := note.C4.Frequency()
+
+	// Pure major thirds
+	assert.InDelta(t, c4*5/4, c.Frequency(note.E4), delta)
+	assert.InDelta(t, c4*25/16, c.Frequency(note.Gsharp4), delta)
+	assert.InDelta(t, 696.5784, tuning.RatioToCents(c.Frequency(note.G4)/c4), delta)
+	assert.InDelta(t, 310.2647, tuning.RatioToCents(c.Frequency(note.Dsharp4)/c4), delta)
+}
+
+func TestWellTemperaments(t *testing.T) {
+	c4 := note.C4.Frequency()
+
+	w := tuning.Werckmeister(note.C4)
+	assert.InDelta(t, c4, w.Frequency(note.C4), delta)
+	assert.InDelta(t, 390.225, tuning.RatioToCents(w.Frequency(note.E4)/c4), delta)
+
+	k := tuning.Kirnberger(note.C4)
+	assert.InDelta(t, c4*5/4, k.Frequency(note.E4), delta)
+	assert.InDelta(t, c4*15/8, k.Frequency(note.B4), 0.001)
+}
+
+func TestTunedFrequency(t *testing.T) {
+	n := note.NewNote(note.E4, note.Quarter)
+	assert.InDelta(t, n.Frequency(), n.TunedFrequency(tuning.Standard), delta)
+	assert.InDelta(t, note.C4.Frequency()*5/4, n.TunedFrequency(tuning.Just(note.C4)), delta)
+	assert.Equal(t, 0.0, note.NewRest(note.Quarter).TunedFrequency(tuning.Just(note.C4)))
+}
+
+func TestCents(t *testing.T) {
+	assert.InDelta(t, 2, tuning.CentsToRatio(1200), delta)
+	assert.InDelta(t, 701.955, tuning.RatioToCents(1.5), 0.001)
+}
+
+func TestMicroPitchDeviation(t *testing.T) {
+	p := note.NewMicroPitch(note.E4, -14)
+	assert.InDelta(t, p.Frequency(), tuning.Standard.Frequency(p), delta)
+	assert.InDelta(t, 415*tuning.CentsToRatio(-14)*tuning.CentsToRatio(-500), tuning.Baroque.Frequency(p), delta)
+
+	// 14 cents below the equal tempered E4 is close to the just major third
+	just := tuning.Just(note.C4)
+	assert.InDelta(t, note.C4.Frequency()*5/4*tuning.CentsToRatio(-14), just.Frequency(p), delta)
+}